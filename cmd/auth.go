@@ -0,0 +1,55 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/config"
+)
+
+func setupAuth(cfg config.AuthConfig, db *sql.DB) (auth.Authenticator, auth.KeyStore, *auth.UsageTracker, error) {
+	store := auth.NewKeyStore(db)
+
+	if cfg.BootstrapAdminKey != "" {
+		key, err := auth.HashAPIKey(cfg.BootstrapAdminKey)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("bootstrap admin key: %w", err)
+		}
+		key.Name = "bootstrap"
+		key.Scopes = []auth.Role{auth.RoleAdmin}
+		existing, err := store.GetAPIKey(key.ID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		switch {
+		case existing == nil:
+			if err := store.SaveAPIKey(key); err != nil {
+				return nil, nil, nil, err
+			}
+			slog.Info("Bootstrap admin API key registered", "key_id", key.ID)
+		case existing.RevokedAt != nil:
+			// Revoking the key must outlast restarts with it still configured.
+			slog.Warn("Bootstrap admin API key is revoked and stays so", "key_id", key.ID)
+		}
+	}
+
+	usage := auth.NewUsageTracker(store)
+
+	chain := auth.Chain{&auth.APIKeyAuthenticator{Store: store, Usage: usage}}
+
+	if cfg.JWKSFile != "" {
+		keys, err := auth.LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		chain = append(chain, &auth.JWTAuthenticator{
+			Keys:     keys,
+			Issuer:   cfg.JWTIssuer,
+			Audience: cfg.JWTAudience,
+		})
+		slog.Info("Loaded JWT verification keys", "count", len(keys), "path", cfg.JWKSFile)
+	}
+
+	return chain, store, usage, nil
+}
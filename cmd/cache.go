@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"swift-api/pkg/repository"
+	"time"
+)
+
+// keepPreloaded loads the whole dataset into the cache and reloads it every
+// interval, picking up changes made by other instances. Preloaded entries
+// never expire, so interval bounds how stale they can get.
+func keepPreloaded(ctx context.Context, cached *repository.CachedRepository, interval time.Duration, logger *slog.Logger) {
+	if err := cached.Preload(ctx); err != nil {
+		logger.Error("Error preloading cache, serving read-through", "error", err)
+	}
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := cached.Preload(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("Error refreshing preloaded cache", "error", err)
+			}
+		}
+	}
+}
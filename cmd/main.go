@@ -1,52 +1,371 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/gorilla/mux"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"swift-api/internal/database"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/config"
+	"swift-api/pkg/events"
+	"swift-api/pkg/graphqlapi"
+	"swift-api/pkg/grpcapi"
 	"swift-api/pkg/handlers"
+	"swift-api/pkg/health"
+	"swift-api/pkg/importer"
+	"swift-api/pkg/logging"
+	"swift-api/pkg/metrics"
+	"swift-api/pkg/openapi"
 	"swift-api/pkg/parser"
+	"swift-api/pkg/ratelimit"
 	"swift-api/pkg/repository"
+	"swift-api/pkg/server"
+	"swift-api/pkg/service"
+	"swift-api/pkg/tracing"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
-	db, err := database.ConnectDB()
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		config.Usage(os.Stderr)
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.PrintOnly {
+		fmt.Print(cfg)
+		return
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.Logging.Level, cfg.Logging.Format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+	parser.SetLogger(logger.With("component", "parser"))
+	logger.Info("Effective configuration", "config", cfg)
+
+	if err := run(cfg, logger); err != nil {
+		logger.Error("Service stopped with an error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run wires the service and blocks until SIGINT/SIGTERM. Everything it opens
+// is released through defers, so it returns errors instead of exiting.
+func run(cfg *config.Config, logger *slog.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("error configuring tracing: %w", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("Error flushing traces", "error", err)
+		}
+	}()
+
+	driver, err := cfg.Database.DriverName()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer db.Close()
+	db, err := database.ConnectDB(ctx, cfg.Database, logger.With("component", "database"))
+	if err != nil {
+		return fmt.Errorf("error connecting to database: %w", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Error("Error closing database", "error", err)
+		}
+		logger.Info("Database connections closed")
+	}()
+
+	if err := database.Migrate(ctx, db, driver, logger.With("component", "database")); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+
+	m := metrics.New()
+	m.RegisterDB(db, "swift")
+
+	replicaDBs, err := database.OpenReplicas(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("error opening read replicas: %w", err)
+	}
+	defer func() {
+		for _, replica := range replicaDBs {
+			replica.Close()
+		}
+	}()
 
-	filePath := os.Getenv("SWIFT_CODES_FILE_PATH")
-	if filePath == "" {
-		log.Fatal("SWIFT_CODES_FILE_PATH environment variable is required")
+	repoOpts := []repository.Option{repository.WithLogger(logger.With("component", "repository"))}
+	if len(replicaDBs) > 0 {
+		replicas := repository.NewReplicaSet(logger.With("component", "replicas"), replicaDBs...)
+		go replicas.Run(ctx, cfg.Database.ReplicaCheckInterval)
+		for i, replica := range replicaDBs {
+			m.RegisterDB(replica, fmt.Sprintf("swift-replica-%d", i+1))
+		}
+		repoOpts = append(repoOpts, repository.WithReplicas(replicas))
+		logger.Info("Routing reads to replicas", "count", len(replicaDBs))
 	}
 
-	hq, branches, err := parser.ParseCSV(filePath)
+	var repo repository.Repository
+	if driver == database.SQLite {
+		repo = repository.NewTracedRepository(repository.NewSQLiteRepository(db, repoOpts...), "sqlite")
+	} else {
+		repo = repository.NewTracedRepository(repository.NewRepository(db, repoOpts...), "postgresql")
+	}
+	var cached *repository.CachedRepository
+	if cfg.Cache.Enabled {
+		cached = repository.NewCachedRepository(repo, cfg.Cache.Size, cfg.Cache.TTL, logger.With("component", "cache"))
+		m.RegisterCache("lookups", cached.Stats)
+		repo = cached
+	}
+
+	importDone := &health.Gate{}
+	checker := health.NewChecker(2 * time.Second)
+	checker.Add("database", db.PingContext)
+	checker.Add("migrations", func(ctx context.Context) error { return database.CheckMigrations(ctx, db, driver) })
+	checker.Add("import", importDone.Check)
+
+	authn, keyStore, usage, err := setupAuth(cfg.Auth, db)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("error configuring authentication: %w", err)
 	}
-	hq = parser.FillMissingHeadquarters(hq, branches)
+	// The usage tracker flushes once more when ctx is cancelled; wait for that
+	// before the deferred db.Close runs.
+	usageDone := make(chan struct{})
+	go func() {
+		defer close(usageDone)
+		usage.Run(ctx, cfg.Auth.UsageFlushInterval)
+	}()
+	defer func() {
+		stop()
+		<-usageDone
+	}()
 
-	repo := repository.NewRepository(db)
-	if err = repo.InsertSwiftCodes(hq); err != nil {
-		log.Fatal("Error inserting headquarters:", err)
+	readRole := auth.RoleReader
+	if cfg.Auth.AnonymousRead {
+		readRole = ""
 	}
 
-	if err = repo.InsertSwiftCodes(branches); err != nil {
-		log.Fatal("Error inserting branches:", err)
+	// publisher stays nil with the feature off, so writes announce nothing.
+	var publisher events.Publisher
+	var feed *handlers.EventsHandler
+	if cfg.Features.Events {
+		webhooks := events.NewStore(db)
+		dispatcher := events.NewDispatcher(webhooks, cfg.Webhooks, logger.With("component", "webhooks"))
+		bus := events.NewBus(cfg.Events.History, dispatcher)
+		publisher = bus
+		feed = handlers.NewEventsHandler(bus, webhooks, dispatcher, cfg.Events.Heartbeat)
+
+		// Deliveries in flight finish, and queued ones are dead-lettered,
+		// before the deferred db.Close runs. Closing the bus ends the event
+		// streams so that the server can drain.
+		dispatchDone := make(chan struct{})
+		go func() {
+			defer close(dispatchDone)
+			dispatcher.Run(ctx)
+		}()
+		go func() {
+			<-ctx.Done()
+			bus.Close()
+		}()
+		defer func() {
+			stop()
+			<-dispatchDone
+		}()
 	}
 
-	handler := handlers.NewHandler(repo)
+	handler := handlers.NewHandler(repo, logger.With("component", "handlers"))
+	handler.Events = publisher
+	keyHandler := handlers.NewAPIKeyHandler(keyStore)
 
-	r := mux.NewRouter()
+	// The limiters are shared with gRPC, so a client has one budget.
+	proxies, err := ratelimit.ParseProxies(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("error parsing trusted proxies: %w", err)
+	}
+	limits := grpcapi.Limits{
+		IP:      newLimiter("ip", cfg.RateLimit.IP),
+		Read:    newLimiter("read", cfg.RateLimit.Read),
+		Write:   newLimiter("write", cfg.RateLimit.Write),
+		Proxies: proxies,
+	}
 
-	r.HandleFunc("/v1/swift-codes/{swift-code}", handler.GetSwiftCode).Methods("GET")
-	r.HandleFunc("/v1/swift-codes/country/{countryISO2code}", handler.GetSwiftCodesByCountry).Methods("GET")
-	r.HandleFunc("/v1/swift-codes", handler.CreateSwiftCode).Methods("POST")
-	r.HandleFunc("/v1/swift-codes/{swift-code}", handler.DeleteSwiftCode).Methods("DELETE")
+	root := mux.NewRouter()
+	root.Use(handlers.RequestID())
+	root.Use(tracing.Middleware)
+	root.Use(handlers.AccessLog(logger.With("component", "http")))
+	root.Use(m.Middleware)
 
-	log.Println("Server running on :8080")
-	log.Fatal(http.ListenAndServe(":8080", r))
+	// Probes and metrics are served from root so that they are never
+	// throttled or refused; everything else goes through r.
+	if cfg.Features.Metrics {
+		root.Handle("/metrics", m.Handler()).Methods("GET")
+	}
+	root.HandleFunc("/healthz", checker.Liveness).Methods("GET")
+	root.HandleFunc("/readyz", checker.Readiness).Methods("GET")
+
+	r := root.NewRoute().Subrouter()
+	r.Use(handlers.ClientAddress(proxies))
+	// Throttle by address first, or bad credentials would never be limited.
+	r.Use(handlers.LimitByAddress(limits.IP))
+	r.Use(handlers.Authenticate(authn))
+	r.Use(handlers.ReadYourWrites())
+
+	reads := handlers.RateLimit(limits.Read)
+	writes := handlers.RateLimit(limits.Write)
+
+	r.Handle("/v1/swift-codes/{swift-code}", reads(withRole(readRole, handler.GetSwiftCode))).Methods("GET")
+	r.Handle("/v1/swift-codes/country/{countryISO2code}", reads(withRole(readRole, handler.GetSwiftCodesByCountry))).Methods("GET")
+	r.Handle("/v1/swift-codes/country/{countryISO2code}/headquarters", reads(withRole(readRole, handler.GetHeadquartersByCountry))).Methods("GET")
+	r.Handle("/v1/swift-codes/{swift-code}/branches", reads(withRole(readRole, handler.ListBranches))).Methods("GET")
+	r.Handle("/v1/banks/{institutionCode}", reads(withRole(readRole, handler.GetBank))).Methods("GET")
+	r.Handle("/v1/institutions", reads(withRole(readRole, handler.SearchInstitutions))).Methods("GET")
+	r.Handle("/v1/institutions/{code}", reads(withRole(readRole, handler.GetInstitution))).Methods("GET")
+	r.Handle("/v1/swift-codes:lookup", reads(withRole(readRole, handler.LookupSwiftCodes))).Methods("POST")
+	r.Handle("/v2/swift-codes/{swift-code}", reads(withRole(readRole, handler.GetSwiftCodeV2))).Methods("GET")
+	r.Handle("/v2/swift-codes/country/{countryISO2code}", reads(withRole(readRole, handler.GetSwiftCodesByCountryV2))).Methods("GET")
+	r.Handle("/v1/swift-codes", writes(withRole(auth.RoleEditor, handler.CreateSwiftCode))).Methods("POST")
+	r.Handle("/v1/swift-codes/{swift-code}", writes(withRole(auth.RoleEditor, handler.UpdateSwiftCode))).Methods("PUT")
+	r.Handle("/v1/swift-codes/{swift-code}", writes(withRole(auth.RoleEditor, handler.DeleteSwiftCode))).Methods("DELETE")
+	if cfg.Features.DiffEndpoint {
+		r.Handle("/v1/imports:diff", writes(withRole(auth.RoleEditor, handler.DiffImport))).Methods("POST")
+	}
+
+	if cfg.Features.AdminAPI {
+		r.Handle("/v1/admin/api-keys", writes(withRole(auth.RoleAdmin, keyHandler.CreateAPIKey))).Methods("POST")
+		r.Handle("/v1/admin/api-keys", reads(withRole(auth.RoleAdmin, keyHandler.ListAPIKeys))).Methods("GET")
+		r.Handle("/v1/admin/api-keys/{id:[0-9a-f]+}", writes(withRole(auth.RoleAdmin, keyHandler.RevokeAPIKey))).Methods("DELETE")
+		r.Handle("/v1/admin/api-keys/{id:[0-9a-f]+}:rotate", writes(withRole(auth.RoleAdmin, keyHandler.RotateAPIKey))).Methods("POST")
+	}
+
+	if feed != nil {
+		r.Handle("/v1/events", reads(withRole(readRole, feed.StreamEvents))).Methods("GET")
+		r.Handle("/v1/webhooks", writes(withRole(auth.RoleAdmin, feed.CreateWebhook))).Methods("POST")
+		r.Handle("/v1/webhooks", reads(withRole(auth.RoleAdmin, feed.ListWebhooks))).Methods("GET")
+		r.Handle("/v1/webhooks/{id:[0-9a-f]+}", writes(withRole(auth.RoleAdmin, feed.DeleteWebhook))).Methods("DELETE")
+		r.Handle("/v1/webhooks/{id:[0-9a-f]+}/dead-letters", reads(withRole(auth.RoleAdmin, feed.ListDeadLetters))).Methods("GET")
+		r.Handle("/v1/webhooks/{id:[0-9a-f]+}/dead-letters/{deadLetterId:[0-9]+}", writes(withRole(auth.RoleAdmin, feed.DeleteDeadLetter))).Methods("DELETE")
+		r.Handle("/v1/webhooks/{id:[0-9a-f]+}/dead-letters/{deadLetterId:[0-9]+}:redeliver", writes(withRole(auth.RoleAdmin, feed.RedeliverDeadLetter))).Methods("POST")
+	}
+
+	if cfg.Features.GraphQL {
+		gql := graphqlapi.New(service.New(repo, logger.With("component", "service")), cfg.GraphQL)
+		r.Handle("/graphql", reads(withRole(readRole, gql.ServeHTTP))).Methods("GET", "POST")
+	}
+
+	r.Handle("/v1/openapi.json", openapi.Handler()).Methods("GET")
+	r.Handle("/docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently)).Methods("GET")
+	r.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", openapi.DocsHandler("/v1/openapi.json"))).Methods("GET")
+
+	srv, err := server.New(cfg.Server, root, logger.With("component", "server"))
+	if err != nil {
+		return fmt.Errorf("error configuring server: %w", err)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- srv.Run(ctx) }()
+
+	// Without gRPC nothing is ever sent on grpcErr, and only the HTTP server
+	// is waited for.
+	servers := 1
+	grpcErr := make(chan error, 1)
+	if cfg.GRPC.Enabled {
+		grpcSrv, err := newGRPCServer(cfg, repo, publisher, authn, readRole, limits, m, logger)
+		if err != nil {
+			return fmt.Errorf("error configuring gRPC server: %w", err)
+		}
+		go func() { grpcErr <- grpcSrv.Run(ctx) }()
+		servers++
+	}
+
+	if cfg.Import.OnStartup {
+		var importOpts []importer.Option
+		if publisher != nil {
+			importOpts = append(importOpts, importer.WithEvents(publisher))
+		}
+		stats, err := importer.Import(ctx, repo, cfg.Import.FilePath, importOpts...)
+		importDone.Done(err)
+		if err != nil {
+			logger.Error("Error importing SWIFT codes, service will report not ready", "error", err)
+		} else {
+			m.RecordImport(stats)
+			logger.Info("Imported SWIFT codes",
+				"parsed", stats.Parsed, "skipped", stats.Skipped, "placeholders", stats.Placeholders,
+				"headquarters", stats.Headquarters, "branches", stats.Branches, "promoted", stats.Promoted)
+		}
+	} else {
+		importDone.Done(nil)
+	}
+
+	if cached != nil && cfg.Cache.Preload {
+		go keepPreloaded(ctx, cached, cfg.Cache.TTL, logger.With("component", "cache"))
+	}
+
+	// Either server failing stops the other one too.
+	var errs []error
+	for range servers {
+		select {
+		case err := <-serverErr:
+			errs = append(errs, err)
+			serverErr = nil
+		case err := <-grpcErr:
+			errs = append(errs, err)
+			grpcErr = nil
+		}
+		stop()
+	}
+	return errors.Join(errs...)
+}
+
+// newGRPCServer serves the service layer over gRPC with the same
+// credentials, roles, rate limits, metrics, tracing and TLS certificate as
+// the HTTP server.
+func newGRPCServer(cfg *config.Config, repo repository.Repository, publisher events.Publisher, authn auth.Authenticator, readRole auth.Role, limits grpcapi.Limits, m *metrics.Metrics, logger *slog.Logger) (*grpcapi.Server, error) {
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor(), m.UnaryServerInterceptor())}
+	if cfg.Server.TLSEnabled() {
+		certs, err := server.NewCertReloader(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile, logger.With("component", "grpc"))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		})))
+	}
+
+	grpcCfg := cfg.GRPC
+	grpcCfg.ShutdownTimeout = cfg.Server.ShutdownTimeout
+	svc := service.New(repo, logger.With("component", "service"))
+	svc.Events = publisher
+	return grpcapi.New(grpcCfg, svc, authn, readRole, limits, logger.With("component", "grpc"), opts...), nil
+}
+
+// withRole protects h with the given role. An empty role leaves the route
+// open to anonymous callers.
+func withRole(role auth.Role, h http.HandlerFunc) http.Handler {
+	if role == "" {
+		return h
+	}
+	return handlers.RequireRole(role)(h)
 }
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"log/slog"
+	"swift-api/pkg/ratelimit"
+)
+
+// newLimiter returns nil, which disables limiting, for a non-positive rate.
+func newLimiter(class string, policy ratelimit.Policy) ratelimit.Limiter {
+	if policy.Rate <= 0 {
+		slog.Info("Rate limiting disabled", "class", class)
+		return nil
+	}
+	return ratelimit.NewMemoryLimiter(policy)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"swift-api/pkg/diff"
+	"swift-api/pkg/models"
+	"swift-api/pkg/parser"
+)
+
+func main() {
+	format := flag.String("format", "text", "output format: text or json")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: swiftdiff [-format text|json] OLD.csv NEW.csv")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	parser.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	current, err := load(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	incoming, err := load(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	result := diff.Compare(current, incoming)
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	case "text":
+		printText(os.Stdout, result)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(2)
+	}
+
+	if !result.Empty() {
+		os.Exit(1)
+	}
+}
+
+func load(path string) ([]models.SwiftCode, error) {
+	hq, branches, err := parser.ParseCSV(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return append(hq, branches...), nil
+}
+
+func printText(w io.Writer, result diff.Result) {
+	for _, c := range result.Added {
+		fmt.Fprintf(w, "+ %s %s\n", c.SwiftCode, c.BankName)
+	}
+	for _, c := range result.Removed {
+		fmt.Fprintf(w, "- %s %s\n", c.SwiftCode, c.BankName)
+	}
+	for _, c := range result.Changed {
+		fmt.Fprintf(w, "~ %s\n", c.SwiftCode)
+		for _, f := range c.Fields {
+			fmt.Fprintf(w, "    %s: %q -> %q\n", f.Field, f.Old, f.New)
+		}
+	}
+	fmt.Fprintf(w, "%d added, %d removed, %d changed\n", result.Summary.Added, result.Summary.Removed, result.Summary.Changed)
+}
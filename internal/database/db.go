@@ -1,54 +1,183 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
-func ConnectDB() (*sql.DB, error) {
-	connStr := os.Getenv("DB_URL")
-	if connStr == "" {
-		return nil, fmt.Errorf("DB_URL environment variable is required")
+// Supported drivers. They double as database/sql driver names.
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+type Config struct {
+	// Driver is Postgres or SQLite. When empty it is inferred from the URL
+	// scheme: sqlite: and file: URLs select SQLite, anything else PostgreSQL.
+	Driver          string
+	URL             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ReplicaURLs are read replicas. They get the same pool limits as the
+	// primary and are health-checked every ReplicaCheckInterval.
+	ReplicaURLs          []string
+	ReplicaCheckInterval time.Duration
+
+	// ConnectAttempts bounds the number of pings at startup, ConnectTimeout
+	// the total time spent on them (0 = no deadline). Attempts are spaced by
+	// an exponential backoff starting at ConnectBackoff, capped at
+	// ConnectMaxBackoff, with full jitter.
+	ConnectAttempts   int
+	ConnectTimeout    time.Duration
+	ConnectBackoff    time.Duration
+	ConnectMaxBackoff time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxOpenConns:    25,
+		MaxIdleConns:    25,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+
+		ReplicaCheckInterval: 5 * time.Second,
+
+		ConnectAttempts:   10,
+		ConnectTimeout:    time.Minute,
+		ConnectBackoff:    500 * time.Millisecond,
+		ConnectMaxBackoff: 10 * time.Second,
 	}
+}
 
-	var db *sql.DB
+// DriverName resolves the driver to use for cfg.
+func (c Config) DriverName() (string, error) {
+	switch strings.ToLower(c.Driver) {
+	case Postgres, "postgresql":
+		return Postgres, nil
+	case SQLite, "sqlite3":
+		return SQLite, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported database driver %q", c.Driver)
+	}
 
-	err := retry(10, 2*time.Second, func() error {
-		var err error
-		db, err = sql.Open("postgres", connStr)
-		if err != nil {
-			log.Println("Failed to open connection:", err)
-			return err
-		}
-		err = db.Ping()
-		if err != nil {
-			log.Println("Failed to ping database:", err)
-			return err
-		}
-		return nil
-	})
+	scheme, _, _ := strings.Cut(c.URL, ":")
+	switch strings.ToLower(scheme) {
+	case "sqlite", "sqlite3", "file":
+		return SQLite, nil
+	}
+	return Postgres, nil
+}
 
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+// sqliteDSN turns sqlite:path, sqlite://path and file: URLs into a
+// modernc.org/sqlite data source with foreign keys enforced, a busy timeout
+// for concurrent writers and WAL so reads do not block on them.
+func sqliteDSN(url string) string {
+	dsn := url
+	if scheme, rest, ok := strings.Cut(url, ":"); ok && strings.HasPrefix(strings.ToLower(scheme), "sqlite") {
+		dsn = strings.TrimPrefix(rest, "//")
+	}
+
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + sqlitePragmas
+}
+
+// pingTimeout keeps a single unresponsive attempt from using up the whole
+// connect deadline.
+const pingTimeout = 5 * time.Second
+
+// ConnectDB opens a pool and pings it until the database answers. It gives up
+// when the attempts or the deadline run out or ctx is cancelled, and closes
+// the pool in that case.
+func ConnectDB(ctx context.Context, cfg Config, logger *slog.Logger) (*sql.DB, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("database URL is required")
+	}
+
+	db, err := open(cfg, cfg.URL)
 	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
+	}
+
+	b := backoff{initial: cfg.ConnectBackoff, max: cfg.ConnectMaxBackoff}
+	err = retry(ctx, logger, cfg.ConnectAttempts, b, func(ctx context.Context) error {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		defer cancel()
+		return db.PingContext(pingCtx)
+	})
+	if err != nil {
+		db.Close()
 		return nil, fmt.Errorf("could not connect to database: %w", err)
 	}
 
-	log.Println("Connected to database successfully!")
+	logger.Info("Connected to database successfully")
 	return db, nil
 }
 
-func retry(maxRetries int, delay time.Duration, fn func() error) error {
-	for i := 0; i < maxRetries; i++ {
-		err := fn()
-		if err == nil {
-			return nil
+// OpenReplicas opens a pool per replica without waiting for them: a replica
+// that is down at startup is simply reported unhealthy until it answers.
+func OpenReplicas(cfg Config) ([]*sql.DB, error) {
+	var dbs []*sql.DB
+	for _, url := range cfg.ReplicaURLs {
+		db, err := open(cfg, url)
+		if err != nil {
+			for _, opened := range dbs {
+				opened.Close()
+			}
+			return nil, err
 		}
-		log.Printf("Retry %d/%d failed, retrying in %s...\n", i+1, maxRetries, delay)
-		time.Sleep(delay)
+		dbs = append(dbs, db)
 	}
-	return fmt.Errorf("all %d retry attempts failed", maxRetries)
+	return dbs, nil
+}
+
+func open(cfg Config, url string) (*sql.DB, error) {
+	driver, err := cfg.DriverName()
+	if err != nil {
+		return nil, err
+	}
+	dsn := url
+	if driver == SQLite {
+		dsn = sqliteDSN(url)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database: %w", err)
+	}
+	// Every connection to an in-memory SQLite database gets its own empty
+	// database, so keep exactly one.
+	if driver == SQLite && strings.Contains(dsn, ":memory:") {
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+		return db, nil
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return db, nil
 }
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	for _, driver := range []string{Postgres, SQLite} {
+		t.Run(driver, func(t *testing.T) {
+			migrations, err := loadMigrations(driver)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, migrations)
+
+			for i, m := range migrations {
+				assert.Equal(t, i+1, m.version, "migrations must be numbered consecutively from 1")
+				assert.NotEmpty(t, m.sql)
+			}
+			assert.Contains(t, migrations[0].sql, "CREATE TABLE IF NOT EXISTS swift_codes")
+		})
+	}
+
+	t.Run("Drivers are kept in step", func(t *testing.T) {
+		pg, _ := loadMigrations(Postgres)
+		lite, _ := loadMigrations(SQLite)
+		require.Equal(t, len(pg), len(lite))
+		for i := range pg {
+			assert.Equal(t, pg[i].name, lite[i].name)
+		}
+	})
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := DefaultConfig()
+	cfg.URL = "sqlite:" + filepath.Join(t.TempDir(), "swift.db")
+	ctx := context.Background()
+
+	db, err := ConnectDB(ctx, cfg, logger)
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Error(t, CheckMigrations(ctx, db, SQLite))
+	require.NoError(t, Migrate(ctx, db, SQLite, logger))
+	require.NoError(t, Migrate(ctx, db, SQLite, logger), "migrating twice is a no-op")
+	assert.NoError(t, CheckMigrations(ctx, db, SQLite))
+
+	t.Run("Foreign keys are enforced", func(t *testing.T) {
+		_, err := db.Exec(`
+			INSERT INTO swift_codes (swift_code, bank_name, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code)
+			VALUES ('ORPHANPL001', 'B', 'T', 'PL', 'POLAND', 'Europe/Warsaw', FALSE, 'MISSINGPXXX')`)
+		assert.ErrorContains(t, err, "FOREIGN KEY")
+	})
+}
+
+func TestDriverName(t *testing.T) {
+	for _, tc := range []struct {
+		driver, url, want string
+	}{
+		{"", "postgres://user@localhost/swift", Postgres},
+		{"", "host=localhost dbname=swift", Postgres},
+		{"", "sqlite:///var/lib/swift.db", SQLite},
+		{"", "sqlite::memory:", SQLite},
+		{"", "file:swift.db?cache=shared", SQLite},
+		{"sqlite", "swift.db", SQLite},
+		{"POSTGRES", "sqlite:swift.db", Postgres},
+	} {
+		got, err := Config{Driver: tc.driver, URL: tc.url}.DriverName()
+		assert.NoError(t, err, tc.url)
+		assert.Equal(t, tc.want, got, tc.url)
+	}
+
+	_, err := Config{Driver: "mysql"}.DriverName()
+	assert.ErrorContains(t, err, `unsupported database driver "mysql"`)
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	assert.Equal(t, "/var/lib/swift.db?"+sqlitePragmas, sqliteDSN("sqlite:///var/lib/swift.db"))
+	assert.Equal(t, "swift.db?"+sqlitePragmas, sqliteDSN("sqlite:swift.db"))
+	assert.Equal(t, ":memory:?"+sqlitePragmas, sqliteDSN("sqlite::memory:"))
+	assert.Equal(t, "file:swift.db?mode=ro&"+sqlitePragmas, sqliteDSN("file:swift.db?mode=ro"))
+}
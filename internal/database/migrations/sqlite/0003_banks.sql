@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS banks (
+    headquarter_swift_code TEXT PRIMARY KEY CHECK (length(headquarter_swift_code) <= 11),
+    institution_code TEXT NOT NULL CHECK (length(institution_code) <= 4),
+    bank_name TEXT NOT NULL,
+    town_name TEXT NOT NULL,
+    country_iso2 TEXT NOT NULL CHECK (length(country_iso2) <= 2),
+    country_name TEXT NOT NULL,
+    branch_count INTEGER NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_banks_institution_code ON banks(institution_code);
+
+INSERT INTO banks (headquarter_swift_code, institution_code, bank_name, town_name, country_iso2, country_name, branch_count)
+SELECT hq.swift_code, SUBSTR(hq.swift_code, 1, 4), hq.bank_name, hq.town_name, hq.country_iso2, hq.country_name,
+       (SELECT COUNT(*) FROM swift_codes b WHERE b.headquarter_swift_code = hq.swift_code AND b.swift_code <> hq.swift_code)
+FROM swift_codes hq
+WHERE hq.is_headquarter = TRUE AND NOT (hq.bank_name = 'UNKNOWN' AND hq.timezone = 'Etc/UTC');
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// backoff computes exponential delays with full jitter: attempt n waits a
+// random duration in [0, min(max, initial*2^n)).
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	rand    func() float64
+}
+
+func (b backoff) delay(attempt int) time.Duration {
+	ceiling := b.initial
+	for i := 0; i < attempt && ceiling < b.max; i++ {
+		ceiling *= 2
+	}
+	if b.max > 0 && ceiling > b.max {
+		ceiling = b.max
+	}
+
+	random := b.rand
+	if random == nil {
+		random = rand.Float64
+	}
+	return time.Duration(random() * float64(ceiling))
+}
+
+func retry(ctx context.Context, logger *slog.Logger, attempts int, b backoff, fn func(ctx context.Context) error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if i == attempts-1 {
+			break
+		}
+
+		delay := b.delay(i)
+		logger.Warn("Database connection attempt failed",
+			"attempt", i+1, "max_attempts", attempts, "retry_in", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("gave up after %d attempts: %w (last error: %v)", i+1, ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+	return fmt.Errorf("all %d attempts failed: %w", attempts, err)
+}
@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const apiKeyPrefix = "swk"
+
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Salt       string     `json:"-"`
+	KeyHash    string     `json:"-"`
+	Scopes     []Role     `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	UsageCount int64      `json:"usageCount"`
+}
+
+type Usage struct {
+	Count    int64
+	LastUsed time.Time
+}
+
+type KeyStore interface {
+	GetAPIKey(id string) (*APIKey, error)
+	ListAPIKeys() ([]APIKey, error)
+	SaveAPIKey(key APIKey) error
+	RevokeAPIKey(id string) (bool, error)
+	RotateAPIKey(id, salt, keyHash string) (bool, error)
+	RecordUsage(usage map[string]Usage) error
+}
+
+type DBKeyStore struct {
+	db *sql.DB
+}
+
+func NewKeyStore(db *sql.DB) KeyStore {
+	return &DBKeyStore{db: db}
+}
+
+const apiKeyColumns = `id, name, salt, key_hash, scopes, created_at, expires_at, revoked_at, last_used_at, usage_count`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (APIKey, error) {
+	var key APIKey
+	var scopes pq.StringArray
+	err := row.Scan(&key.ID, &key.Name, &key.Salt, &key.KeyHash, &scopes, &key.CreatedAt, &key.ExpiresAt, &key.RevokedAt, &key.LastUsedAt, &key.UsageCount)
+	for _, s := range scopes {
+		key.Scopes = append(key.Scopes, Role(s))
+	}
+	return key, err
+}
+
+func (s *DBKeyStore) GetAPIKey(id string) (*APIKey, error) {
+	key, err := scanAPIKey(s.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		slog.Error("Error fetching API key", "error", err)
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *DBKeyStore) ListAPIKeys() ([]APIKey, error) {
+	rows, err := s.db.Query(`SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at`)
+	if err != nil {
+		slog.Error("Error listing API keys", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			slog.Error("Error scanning API key", "error", err)
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// SaveAPIKey adds key unless a key with its ID is already stored. An
+// existing key, revoked or not, is left as it is.
+func (s *DBKeyStore) SaveAPIKey(key APIKey) error {
+	scopes := make(pq.StringArray, len(key.Scopes))
+	for i, r := range key.Scopes {
+		scopes[i] = string(r)
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO api_keys (id, name, salt, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO NOTHING`,
+		key.ID, key.Name, key.Salt, key.KeyHash, scopes, key.ExpiresAt)
+	if err != nil {
+		slog.Error("Error saving API key", "error", err)
+	}
+	return err
+}
+
+func (s *DBKeyStore) RevokeAPIKey(id string) (bool, error) {
+	result, err := s.db.Exec(`UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		slog.Error("Error revoking API key", "error", err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (s *DBKeyStore) RotateAPIKey(id, salt, keyHash string) (bool, error) {
+	result, err := s.db.Exec(`
+		UPDATE api_keys SET salt = $2, key_hash = $3
+		WHERE id = $1 AND revoked_at IS NULL`, id, salt, keyHash)
+	if err != nil {
+		slog.Error("Error rotating API key", "error", err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (s *DBKeyStore) RecordUsage(usage map[string]Usage) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Plain CASE rather than GREATEST keeps this valid in SQLite, which
+	// compares the stored timestamps as text; UTC keeps that order right.
+	for id, u := range usage {
+		_, err := tx.Exec(`
+			UPDATE api_keys SET
+				usage_count = usage_count + $2,
+				last_used_at = CASE WHEN last_used_at IS NULL OR last_used_at < $3 THEN $3 ELSE last_used_at END
+			WHERE id = $1`, id, u.Count, u.LastUsed.UTC())
+		if err != nil {
+			slog.Error("Error recording API key usage", "error", err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GenerateAPIKey returns a new plaintext key of the form swk_<id>_<secret>
+// together with the record to store. The plaintext is never persisted.
+func GenerateAPIKey(name string, scopes []Role, expiresAt *time.Time) (string, APIKey, error) {
+	id, err := randomBytes(8)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	plaintext, err := newPlaintext(hex.EncodeToString(id))
+	if err != nil {
+		return "", APIKey{}, err
+	}
+
+	key, err := HashAPIKey(plaintext)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	key.Name = name
+	key.Scopes = scopes
+	key.ExpiresAt = expiresAt
+	return plaintext, key, nil
+}
+
+// RegenerateSecret issues a new plaintext for an existing key ID and returns
+// it along with the new salt and hash.
+func RegenerateSecret(id string) (string, APIKey, error) {
+	plaintext, err := newPlaintext(id)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	key, err := HashAPIKey(plaintext)
+	return plaintext, key, err
+}
+
+// HashAPIKey builds the stored record for an existing plaintext key with a
+// fresh salt.
+func HashAPIKey(plaintext string) (APIKey, error) {
+	id, secret, ok := splitAPIKey(plaintext)
+	if !ok {
+		return APIKey{}, fmt.Errorf("malformed API key")
+	}
+	salt, err := randomBytes(16)
+	if err != nil {
+		return APIKey{}, err
+	}
+	saltHex := hex.EncodeToString(salt)
+	return APIKey{
+		ID:      id,
+		Salt:    saltHex,
+		KeyHash: hashSecret(saltHex, secret),
+	}, nil
+}
+
+func (k *APIKey) Matches(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashSecret(k.Salt, secret)), []byte(k.KeyHash)) == 1
+}
+
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+type APIKeyAuthenticator struct {
+	Store KeyStore
+	Usage *UsageTracker
+}
+
+func (a *APIKeyAuthenticator) Authenticate(c Credentials) (*Principal, error) {
+	plaintext := c.APIKey
+	if plaintext == "" {
+		return nil, ErrNoCredentials
+	}
+
+	id, secret, ok := splitAPIKey(plaintext)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	key, err := a.Store.GetAPIKey(id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || !key.Matches(secret) || !key.Active(time.Now()) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if a.Usage != nil {
+		a.Usage.Record(key.ID)
+	}
+
+	return &Principal{
+		Subject: "api-key:" + key.ID,
+		Method:  "api_key",
+		KeyID:   key.ID,
+		Roles:   key.Scopes,
+	}, nil
+}
+
+func newPlaintext(id string) (string, error) {
+	secret, err := randomBytes(24)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s_%s_%s", apiKeyPrefix, id, base64.RawURLEncoding.EncodeToString(secret)), nil
+}
+
+func splitAPIKey(plaintext string) (string, string, bool) {
+	parts := strings.SplitN(plaintext, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func hashSecret(salt, secret string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
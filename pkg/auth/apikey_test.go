@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"swift-api/internal/database"
+)
+
+func TestDBKeyStoreSQLite(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := database.DefaultConfig()
+	cfg.URL = "sqlite:" + filepath.Join(t.TempDir(), "swift.db")
+	db, err := database.ConnectDB(context.Background(), cfg, logger)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, database.Migrate(context.Background(), db, database.SQLite, logger))
+
+	store := NewKeyStore(db)
+	_, key, err := GenerateAPIKey("ci", []Role{RoleReader, RoleEditor}, nil)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveAPIKey(key))
+
+	t.Run("Round trip", func(t *testing.T) {
+		got, err := store.GetAPIKey(key.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, []Role{RoleReader, RoleEditor}, got.Scopes)
+		assert.False(t, got.CreatedAt.IsZero())
+	})
+
+	t.Run("Usage keeps the latest timestamp", func(t *testing.T) {
+		later := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		require.NoError(t, store.RecordUsage(map[string]Usage{key.ID: {Count: 2, LastUsed: later}}))
+		require.NoError(t, store.RecordUsage(map[string]Usage{key.ID: {Count: 1, LastUsed: later.Add(-time.Hour)}}))
+
+		got, err := store.GetAPIKey(key.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), got.UsageCount)
+		require.NotNil(t, got.LastUsedAt)
+		assert.True(t, later.Equal(*got.LastUsedAt))
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		revoked, err := store.RevokeAPIKey(key.ID)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		got, err := store.GetAPIKey(key.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, got.RevokedAt)
+
+		revoked, err = store.RevokeAPIKey(key.ID)
+		require.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("Saving again keeps an existing key", func(t *testing.T) {
+		again := key
+		again.Name = "replaced"
+		again.KeyHash = "other"
+		require.NoError(t, store.SaveAPIKey(again))
+
+		got, err := store.GetAPIKey(key.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "ci", got.Name)
+		assert.Equal(t, key.KeyHash, got.KeyHash)
+		assert.NotNil(t, got.RevokedAt)
+	})
+}
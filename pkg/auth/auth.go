@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrNoCredentials      = errors.New("no credentials supplied")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleReader: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+func ParseRole(s string) (Role, bool) {
+	role := Role(s)
+	_, ok := roleRank[role]
+	return role, ok
+}
+
+// Satisfies reports whether r grants at least the access of required.
+// Roles are ordered reader < editor < admin.
+func (r Role) Satisfies(required Role) bool {
+	return roleRank[r] > 0 && roleRank[r] >= roleRank[required]
+}
+
+type Principal struct {
+	Subject string
+	Method  string
+	KeyID   string
+	Roles   []Role
+}
+
+func (p *Principal) HasRole(required Role) bool {
+	for _, r := range p.Roles {
+		if r.Satisfies(required) {
+			return true
+		}
+	}
+	return false
+}
+
+// Credentials are what a caller presented, whichever transport carried them.
+type Credentials struct {
+	// APIKey is the X-API-Key value.
+	APIKey string
+	// Authorization is the Authorization value, such as "Bearer <token>".
+	Authorization string
+}
+
+// RequestCredentials reads the credentials from the request headers.
+func RequestCredentials(r *http.Request) Credentials {
+	return Credentials{APIKey: r.Header.Get("X-API-Key"), Authorization: r.Header.Get("Authorization")}
+}
+
+// Authenticator resolves the caller from its credentials. It returns
+// ErrNoCredentials when they hold nothing it understands, so that several
+// authenticators can be tried in turn.
+type Authenticator interface {
+	Authenticate(c Credentials) (*Principal, error)
+}
+
+type Chain []Authenticator
+
+func (c Chain) Authenticate(creds Credentials) (*Principal, error) {
+	for _, a := range c {
+		p, err := a.Authenticate(creds)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoCredentials
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok
+}
@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryKeyStore map[string]APIKey
+
+func (s memoryKeyStore) GetAPIKey(id string) (*APIKey, error) {
+	key, ok := s[id]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+func (s memoryKeyStore) ListAPIKeys() ([]APIKey, error) {
+	var keys []APIKey
+	for _, k := range s {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (s memoryKeyStore) SaveAPIKey(key APIKey) error {
+	s[key.ID] = key
+	return nil
+}
+
+func (s memoryKeyStore) RevokeAPIKey(id string) (bool, error) {
+	key, ok := s[id]
+	if !ok || key.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	s[id] = key
+	return true, nil
+}
+
+func (s memoryKeyStore) RotateAPIKey(id, salt, keyHash string) (bool, error) {
+	key, ok := s[id]
+	if !ok || key.RevokedAt != nil {
+		return false, nil
+	}
+	key.Salt, key.KeyHash = salt, keyHash
+	s[id] = key
+	return true, nil
+}
+
+func (s memoryKeyStore) RecordUsage(usage map[string]Usage) error {
+	for id, u := range usage {
+		key := s[id]
+		key.UsageCount += u.Count
+		lastUsed := u.LastUsed
+		key.LastUsedAt = &lastUsed
+		s[id] = key
+	}
+	return nil
+}
+
+func TestRoleSatisfies(t *testing.T) {
+	assert.True(t, RoleAdmin.Satisfies(RoleEditor))
+	assert.True(t, RoleEditor.Satisfies(RoleEditor))
+	assert.True(t, RoleEditor.Satisfies(RoleReader))
+	assert.False(t, RoleReader.Satisfies(RoleEditor))
+	assert.False(t, Role("root").Satisfies(RoleReader))
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	store := memoryKeyStore{}
+	plaintext, key, err := GenerateAPIKey("ci", []Role{RoleEditor}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, key.KeyHash, plaintext)
+	assert.NoError(t, store.SaveAPIKey(key))
+
+	usage := NewUsageTracker(store)
+	authn := &APIKeyAuthenticator{Store: store, Usage: usage}
+
+	t.Run("Valid key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", plaintext)
+		p, err := authn.Authenticate(RequestCredentials(req))
+		assert.NoError(t, err)
+		assert.Equal(t, []Role{RoleEditor}, p.Roles)
+		assert.Equal(t, "api-key:"+key.ID, p.Subject)
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "swk_"+key.ID+"_wrong")
+		_, err := authn.Authenticate(RequestCredentials(req))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Malformed key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "not-a-key")
+		_, err := authn.Authenticate(RequestCredentials(req))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("No key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		_, err := authn.Authenticate(RequestCredentials(req))
+		assert.ErrorIs(t, err, ErrNoCredentials)
+	})
+
+	t.Run("Usage is flushed in batches", func(t *testing.T) {
+		assert.NoError(t, usage.Flush())
+		assert.Equal(t, int64(1), store[key.ID].UsageCount)
+		assert.NotNil(t, store[key.ID].LastUsedAt)
+	})
+
+	t.Run("Expired key", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		expiredText, expired, err := GenerateAPIKey("old", []Role{RoleAdmin}, &past)
+		assert.NoError(t, err)
+		assert.NoError(t, store.SaveAPIKey(expired))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", expiredText)
+		_, err = authn.Authenticate(RequestCredentials(req))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Rotated key invalidates old secret", func(t *testing.T) {
+		rotatedText, secret, err := RegenerateSecret(key.ID)
+		assert.NoError(t, err)
+		ok, err := store.RotateAPIKey(key.ID, secret.Salt, secret.KeyHash)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", plaintext)
+		_, err = authn.Authenticate(RequestCredentials(req))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		req.Header.Set("X-API-Key", rotatedText)
+		p, err := authn.Authenticate(RequestCredentials(req))
+		assert.NoError(t, err)
+		assert.Equal(t, key.ID, p.KeyID)
+	})
+
+	t.Run("Revoked key", func(t *testing.T) {
+		revokedText, revoked, err := GenerateAPIKey("revoked", []Role{RoleReader}, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, store.SaveAPIKey(revoked))
+		ok, err := store.RevokeAPIKey(revoked.ID)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", revokedText)
+		_, err = authn.Authenticate(RequestCredentials(req))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+}
+
+func TestHashAPIKeyUsesFreshSalt(t *testing.T) {
+	plaintext, _, err := GenerateAPIKey("ci", []Role{RoleReader}, nil)
+	assert.NoError(t, err)
+
+	a, err := HashAPIKey(plaintext)
+	assert.NoError(t, err)
+	b, err := HashAPIKey(plaintext)
+	assert.NoError(t, err)
+
+	assert.Equal(t, a.ID, b.ID)
+	assert.NotEqual(t, a.KeyHash, b.KeyHash)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	jwks := map[string]any{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa-1", "use": "sig",
+				"n": b64(rsaKey.N.Bytes()),
+				"e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "kid": "ec-1", "crv": "P-256",
+				"x": b64(ecKey.X.FillBytes(make([]byte, 32))),
+				"y": b64(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	data, _ := json.Marshal(jwks)
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+
+	keys, err := LoadJWKS(path)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	authn := &JWTAuthenticator{Keys: keys, Issuer: "https://issuer.test", Audience: "swift-api"}
+
+	sign := func(method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		assert.NoError(t, err)
+		return s
+	}
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "user-1",
+			"iss":   "https://issuer.test",
+			"aud":   "swift-api",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"editor", "unknown"},
+		}
+	}
+	request := func(token string) Credentials {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return RequestCredentials(req)
+	}
+
+	t.Run("Valid RSA token", func(t *testing.T) {
+		p, err := authn.Authenticate(request(sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims())))
+		assert.NoError(t, err)
+		assert.Equal(t, "user-1", p.Subject)
+		assert.Equal(t, []Role{RoleEditor}, p.Roles)
+	})
+
+	t.Run("Valid EC token", func(t *testing.T) {
+		_, err := authn.Authenticate(request(sign(jwt.SigningMethodES256, "ec-1", ecKey, validClaims())))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		claims := validClaims()
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		_, err := authn.Authenticate(request(sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Wrong audience", func(t *testing.T) {
+		claims := validClaims()
+		claims["aud"] = "other"
+		_, err := authn.Authenticate(request(sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Key from another kid", func(t *testing.T) {
+		_, err := authn.Authenticate(request(sign(jwt.SigningMethodRS256, "ec-1", rsaKey, validClaims())))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("HMAC token rejected", func(t *testing.T) {
+		_, err := authn.Authenticate(request(sign(jwt.SigningMethodHS256, "rsa-1", []byte("secret"), validClaims())))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Non-bearer header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+		_, err := authn.Authenticate(RequestCredentials(req))
+		assert.ErrorIs(t, err, ErrNoCredentials)
+	})
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
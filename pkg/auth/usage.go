@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// UsageTracker aggregates API key usage in memory and writes it to the store
+// in batches, so authenticating a request never waits on an UPDATE.
+type UsageTracker struct {
+	store   KeyStore
+	mu      sync.Mutex
+	pending map[string]Usage
+}
+
+func NewUsageTracker(store KeyStore) *UsageTracker {
+	return &UsageTracker{store: store, pending: make(map[string]Usage)}
+}
+
+func (t *UsageTracker) Record(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := t.pending[id]
+	u.Count++
+	u.LastUsed = time.Now()
+	t.pending[id] = u
+}
+
+func (t *UsageTracker) Flush() error {
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[string]Usage)
+	t.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := t.store.RecordUsage(batch); err != nil {
+		t.mu.Lock()
+		for id, u := range batch {
+			merged := t.pending[id]
+			merged.Count += u.Count
+			if u.LastUsed.After(merged.LastUsed) {
+				merged.LastUsed = u.LastUsed
+			}
+			t.pending[id] = merged
+		}
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes every interval until ctx is cancelled, then flushes once more.
+func (t *UsageTracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.Flush(); err != nil {
+				slog.Error("Error flushing API key usage", "error", err)
+			}
+		case <-ctx.Done():
+			if err := t.Flush(); err != nil {
+				slog.Error("Error flushing API key usage", "error", err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("Evicts least recently used", func(t *testing.T) {
+		c := NewLRU[string, int](2, 0)
+		c.Add("a", 1)
+		c.Add("b", 2)
+		c.Get("a")
+		assert.True(t, c.Add("c", 3))
+
+		_, ok := c.Get("b")
+		assert.False(t, ok)
+		v, ok := c.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("Updating a key does not evict", func(t *testing.T) {
+		c := NewLRU[string, int](2, 0)
+		c.Add("a", 1)
+		c.Add("b", 2)
+		assert.False(t, c.Add("a", 10))
+
+		v, _ := c.Get("a")
+		assert.Equal(t, 10, v)
+	})
+
+	t.Run("Entries expire after TTL", func(t *testing.T) {
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		c := NewLRU[string, int](0, time.Minute)
+		c.now = func() time.Time { return now }
+
+		c.Add("a", 1)
+		c.AddWithTTL("forever", 2, 0)
+		now = now.Add(2 * time.Minute)
+
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		_, ok = c.Get("forever")
+		assert.True(t, ok)
+		assert.Equal(t, 1, c.Len())
+	})
+
+	t.Run("Remove and purge", func(t *testing.T) {
+		c := NewLRU[string, int](0, 0)
+		c.Add("a", 1)
+		c.Add("b", 2)
+		c.Remove("a")
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+
+		c.Purge()
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		c := NewLRU[string, int](1, 0)
+		c.Add("a", 1)
+		c.Get("a")
+		c.Get("missing")
+		c.Add("b", 2)
+
+		assert.Equal(t, Stats{Hits: 1, Misses: 1, Evictions: 1, Entries: 1}, c.Stats())
+	})
+}
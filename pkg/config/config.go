@@ -0,0 +1,284 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"swift-api/internal/database"
+	"swift-api/pkg/events"
+	"swift-api/pkg/graphqlapi"
+	"swift-api/pkg/grpcapi"
+	"swift-api/pkg/ratelimit"
+	"swift-api/pkg/server"
+	"swift-api/pkg/tracing"
+	"time"
+)
+
+// Config is the complete service configuration. Values are resolved in this
+// order, later sources winning: defaults, config file, environment, flags.
+type Config struct {
+	Server    server.Config
+	GRPC      grpcapi.Config
+	GraphQL   graphqlapi.Config
+	Events    events.Config
+	Webhooks  events.WebhookConfig
+	Database  database.Config
+	Import    ImportConfig
+	Auth      AuthConfig
+	RateLimit RateLimitConfig
+	Cache     CacheConfig
+	Logging   LoggingConfig
+	Tracing   tracing.Config
+	Features  FeaturesConfig
+
+	// File is the config file that was loaded, if any.
+	File string
+	// PrintOnly is set by -print-config: print the effective configuration
+	// and exit.
+	PrintOnly bool
+
+	sources map[string]string
+}
+
+type ImportConfig struct {
+	FilePath  string
+	OnStartup bool
+}
+
+type AuthConfig struct {
+	AnonymousRead      bool
+	BootstrapAdminKey  string
+	JWKSFile           string
+	JWTIssuer          string
+	JWTAudience        string
+	UsageFlushInterval time.Duration
+}
+
+// RateLimitConfig holds one policy per route class. A non-positive rate
+// disables limiting for that class.
+type RateLimitConfig struct {
+	// IP applies to every API request by client address before credentials are
+	// checked, so guessing keys is throttled too.
+	IP    ratelimit.Policy
+	Read  ratelimit.Policy
+	Write ratelimit.Policy
+	// TrustedProxies are the CIDR ranges or addresses of reverse proxies
+	// whose X-Forwarded-For header names the client. Empty trusts none.
+	TrustedProxies []string
+}
+
+// CacheConfig controls the lookup cache in front of the repository.
+type CacheConfig struct {
+	Enabled bool
+	Size    int
+	TTL     time.Duration
+	Preload bool
+}
+
+type LoggingConfig struct {
+	Level  string
+	Format string
+}
+
+type FeaturesConfig struct {
+	Metrics      bool
+	DiffEndpoint bool
+	AdminAPI     bool
+	GraphQL      bool
+	Events       bool
+}
+
+func Default() *Config {
+	return &Config{
+		Server:   server.DefaultConfig(),
+		GRPC:     grpcapi.DefaultConfig(),
+		GraphQL:  graphqlapi.DefaultConfig(),
+		Events:   events.DefaultConfig(),
+		Webhooks: events.DefaultWebhookConfig(),
+		Database: database.DefaultConfig(),
+		Import:   ImportConfig{OnStartup: true},
+		Auth: AuthConfig{
+			AnonymousRead:      true,
+			UsageFlushInterval: 10 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			IP:    ratelimit.Policy{Rate: 100, Burst: 200},
+			Read:  ratelimit.Policy{Rate: 50, Burst: 100},
+			Write: ratelimit.Policy{Rate: 5, Burst: 10},
+		},
+		Cache:    CacheConfig{Enabled: true, Size: 10000, TTL: time.Minute},
+		Logging:  LoggingConfig{Level: "info", Format: "json"},
+		Tracing:  tracing.DefaultConfig(),
+		Features: FeaturesConfig{Metrics: true, DiffEndpoint: true, AdminAPI: true, GraphQL: true, Events: true},
+	}
+}
+
+// Validate reports every invalid value at once rather than stopping at the
+// first.
+func (c *Config) Validate() error {
+	envs := make(map[string]string)
+	for _, f := range c.fields() {
+		envs[f.key] = f.env
+	}
+	n := func(key string) string { return key + " (" + envs[key] + ")" }
+
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Addr != "", "%s is required", n("server.addr"))
+	for _, d := range []struct {
+		key string
+		val time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"database.conn_max_lifetime", c.Database.ConnMaxLifetime},
+		{"database.conn_max_idle_time", c.Database.ConnMaxIdleTime},
+		{"database.connect_timeout", c.Database.ConnectTimeout},
+	} {
+		check(d.val >= 0, "%s must not be negative", n(d.key))
+	}
+	check(c.Server.ShutdownTimeout > 0, "%s must be positive", n("server.shutdown_timeout"))
+	if err := c.Server.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	check(!c.GRPC.Enabled || c.GRPC.Addr != "", "%s is required when %s is set", n("grpc.addr"), n("grpc.enabled"))
+	check(!c.GRPC.Enabled || c.GRPC.Addr != c.Server.Addr, "%s must differ from %s", n("grpc.addr"), n("server.addr"))
+	check(c.GRPC.Timeout >= 0, "%s must not be negative", n("grpc.timeout"))
+	check(c.GraphQL.MaxDepth >= 0, "%s must not be negative", n("graphql.max_depth"))
+	check(c.GraphQL.MaxComplexity >= 0, "%s must not be negative", n("graphql.max_complexity"))
+	check(c.Events.History >= 1, "%s must be at least 1", n("events.history"))
+	check(c.Events.Heartbeat > 0, "%s must be positive", n("events.heartbeat"))
+	check(c.Webhooks.Timeout > 0, "%s must be positive", n("webhooks.timeout"))
+	check(c.Webhooks.MaxAttempts >= 1, "%s must be at least 1", n("webhooks.max_attempts"))
+	check(c.Webhooks.InitialBackoff > 0, "%s must be positive", n("webhooks.initial_backoff"))
+	check(c.Webhooks.MaxBackoff >= c.Webhooks.InitialBackoff,
+		"%s must not be below %s", n("webhooks.max_backoff"), n("webhooks.initial_backoff"))
+	check(c.Webhooks.MaxQueue >= 0, "%s must not be negative", n("webhooks.max_queue"))
+
+	check(c.Database.URL != "", "%s is required", n("database.url"))
+	driver, err := c.Database.DriverName()
+	check(err == nil, "%s: %v", n("database.driver"), err)
+	check(driver != database.SQLite || len(c.Database.ReplicaURLs) == 0,
+		"%s are only supported with PostgreSQL", n("database.replica_urls"))
+	check(c.Database.MaxOpenConns >= 0, "%s must not be negative", n("database.max_open_conns"))
+	check(c.Database.MaxIdleConns >= 0, "%s must not be negative", n("database.max_idle_conns"))
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"%s must not exceed %s", n("database.max_idle_conns"), n("database.max_open_conns"))
+	check(len(c.Database.ReplicaURLs) == 0 || c.Database.ReplicaCheckInterval > 0,
+		"%s must be positive", n("database.replica_check_interval"))
+	check(c.Database.ConnectAttempts >= 1, "%s must be at least 1", n("database.connect_attempts"))
+	check(c.Database.ConnectBackoff > 0, "%s must be positive", n("database.connect_backoff"))
+	check(c.Database.ConnectMaxBackoff >= c.Database.ConnectBackoff,
+		"%s must not be below %s", n("database.connect_max_backoff"), n("database.connect_backoff"))
+
+	check(!c.Import.OnStartup || c.Import.FilePath != "", "%s is required when %s is set", n("import.file_path"), n("import.on_startup"))
+
+	check(c.Auth.UsageFlushInterval > 0, "%s must be positive", n("auth.usage_flush_interval"))
+	check(c.Auth.JWKSFile != "" || (c.Auth.JWTIssuer == "" && c.Auth.JWTAudience == ""),
+		"%s and %s require %s", n("auth.jwt_issuer"), n("auth.jwt_audience"), n("auth.jwks_file"))
+
+	for _, p := range []struct {
+		class  string
+		policy ratelimit.Policy
+	}{{"ip", c.RateLimit.IP}, {"read", c.RateLimit.Read}, {"write", c.RateLimit.Write}} {
+		check(p.policy.Rate <= 0 || p.policy.Burst >= 1, "%s must be at least 1", n("rate_limit."+p.class+"_burst"))
+	}
+	_, err = ratelimit.ParseProxies(c.RateLimit.TrustedProxies)
+	check(err == nil, "%s: %v", n("rate_limit.trusted_proxies"), err)
+
+	check(c.Cache.Size >= 0, "%s must not be negative", n("cache.size"))
+	check(c.Cache.TTL >= 0, "%s must not be negative", n("cache.ttl"))
+
+	var lvl slog.Level
+	check(lvl.UnmarshalText([]byte(c.Logging.Level)) == nil, "%s must be debug, info, warn or error", n("logging.level"))
+	format := strings.ToLower(c.Logging.Format)
+	check(format == "json" || format == "text", "%s must be json or text", n("logging.format"))
+
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "%s must be between 0 and 1", n("tracing.sample_ratio"))
+	check(!c.Tracing.Enabled || c.Tracing.ServiceName != "", "%s is required when tracing is enabled", n("tracing.service_name"))
+
+	return errors.Join(errs...)
+}
+
+// Setting is one resolved configuration value, already redacted.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Settings lists every value with the source it came from. Secrets are
+// replaced and passwords are stripped from URLs.
+func (c *Config) Settings() []Setting {
+	fields := c.fields()
+	settings := make([]Setting, 0, len(fields))
+	for _, f := range fields {
+		value := f.get()
+		if f.redact != nil && value != "" {
+			value = f.redact(value)
+		}
+		source := c.sources[f.key]
+		if source == "" {
+			source = "default"
+		}
+		settings = append(settings, Setting{Key: f.key, Value: value, Source: source})
+	}
+	return settings
+}
+
+// String renders the effective configuration, one "key = value (source)"
+// line per setting.
+func (c *Config) String() string {
+	var b strings.Builder
+	for _, s := range c.Settings() {
+		fmt.Fprintf(&b, "%s = %s (%s)\n", s.Key, s.Value, s.Source)
+	}
+	return b.String()
+}
+
+func (c *Config) LogValue() slog.Value {
+	settings := c.Settings()
+	attrs := make([]slog.Attr, 0, len(settings))
+	for _, s := range settings {
+		attrs = append(attrs, slog.String(s.Key, s.Value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+const redacted = "[REDACTED]"
+
+func redactSecret(string) string {
+	return redacted
+}
+
+// redactURL keeps a DSN readable but drops its password. Anything that does
+// not parse as a URL with a scheme is hidden entirely.
+func redactURL(v string) string {
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" {
+		return redacted
+	}
+	if q := u.Query(); q.Has("password") {
+		q.Set("password", "xxxxx")
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
+
+func redactURLs(v string) string {
+	urls := strings.Split(v, ",")
+	for i, u := range urls {
+		urls[i] = redactURL(u)
+	}
+	return strings.Join(urls, ",")
+}
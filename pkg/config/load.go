@@ -0,0 +1,341 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// field binds one setting to its config file key, environment variable and
+// command-line flag. The flag name is the key with dots and underscores
+// replaced by dashes.
+type field struct {
+	key    string
+	env    string
+	usage  string
+	ptr    any
+	redact func(string) string
+}
+
+func (c *Config) fields() []field {
+	return []field{
+		{"server.addr", "HTTP_ADDR", "listen address", &c.Server.Addr, nil},
+		{"server.read_timeout", "HTTP_READ_TIMEOUT", "time allowed to read a request", &c.Server.ReadTimeout, nil},
+		{"server.read_header_timeout", "HTTP_READ_HEADER_TIMEOUT", "time allowed to read request headers", &c.Server.ReadHeaderTimeout, nil},
+		{"server.write_timeout", "HTTP_WRITE_TIMEOUT", "time allowed to write a response", &c.Server.WriteTimeout, nil},
+		{"server.idle_timeout", "HTTP_IDLE_TIMEOUT", "keep-alive idle time", &c.Server.IdleTimeout, nil},
+		{"server.shutdown_timeout", "SHUTDOWN_TIMEOUT", "drain deadline for in-flight requests", &c.Server.ShutdownTimeout, nil},
+		{"server.tls_cert_file", "TLS_CERT_FILE", "TLS certificate file", &c.Server.TLSCertFile, nil},
+		{"server.tls_key_file", "TLS_KEY_FILE", "TLS private key file", &c.Server.TLSKeyFile, nil},
+
+		{"grpc.enabled", "GRPC_ENABLED", "serve the gRPC API", &c.GRPC.Enabled, nil},
+		{"grpc.addr", "GRPC_ADDR", "gRPC listen address", &c.GRPC.Addr, nil},
+		{"grpc.timeout", "GRPC_TIMEOUT", "maximum time a gRPC call may run (0 = client deadline only)", &c.GRPC.Timeout, nil},
+
+		{"graphql.max_depth", "GRAPHQL_MAX_DEPTH", "deepest field nesting a GraphQL query may have (0 = unlimited)", &c.GraphQL.MaxDepth, nil},
+		{"graphql.max_complexity", "GRAPHQL_MAX_COMPLEXITY", "highest estimated field count a GraphQL query may have (0 = unlimited)", &c.GraphQL.MaxComplexity, nil},
+
+		{"events.history", "EVENTS_HISTORY", "events kept for streams resuming with Last-Event-ID", &c.Events.History, nil},
+		{"events.heartbeat", "EVENTS_HEARTBEAT", "how often an idle event stream sends a keepalive", &c.Events.Heartbeat, nil},
+
+		{"webhooks.timeout", "WEBHOOK_TIMEOUT", "time allowed for one webhook delivery", &c.Webhooks.Timeout, nil},
+		{"webhooks.max_attempts", "WEBHOOK_MAX_ATTEMPTS", "deliveries tried before an event is dead-lettered", &c.Webhooks.MaxAttempts, nil},
+		{"webhooks.initial_backoff", "WEBHOOK_INITIAL_BACKOFF", "initial delay between webhook deliveries", &c.Webhooks.InitialBackoff, nil},
+		{"webhooks.max_backoff", "WEBHOOK_MAX_BACKOFF", "maximum delay between webhook deliveries", &c.Webhooks.MaxBackoff, nil},
+		{"webhooks.max_queue", "WEBHOOK_MAX_QUEUE", "events awaiting delivery or retry before new ones are dropped (0 = unbounded)", &c.Webhooks.MaxQueue, nil},
+
+		{"database.driver", "DB_DRIVER", "postgres or sqlite (default: inferred from the URL)", &c.Database.Driver, nil},
+		{"database.url", "DB_URL", "PostgreSQL URL, or sqlite:<path> for SQLite", &c.Database.URL, redactURL},
+		{"database.max_open_conns", "DB_MAX_OPEN_CONNS", "maximum open connections (0 = unlimited)", &c.Database.MaxOpenConns, nil},
+		{"database.max_idle_conns", "DB_MAX_IDLE_CONNS", "maximum idle connections", &c.Database.MaxIdleConns, nil},
+		{"database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", "maximum connection age (0 = unlimited)", &c.Database.ConnMaxLifetime, nil},
+		{"database.conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME", "maximum connection idle time (0 = unlimited)", &c.Database.ConnMaxIdleTime, nil},
+		{"database.replica_urls", "DB_REPLICA_URLS", "comma-separated read replica URLs", &c.Database.ReplicaURLs, redactURLs},
+		{"database.replica_check_interval", "DB_REPLICA_CHECK_INTERVAL", "how often read replicas are health-checked", &c.Database.ReplicaCheckInterval, nil},
+		{"database.connect_attempts", "DB_CONNECT_ATTEMPTS", "connection attempts at startup", &c.Database.ConnectAttempts, nil},
+		{"database.connect_timeout", "DB_CONNECT_TIMEOUT", "total time allowed for connecting at startup (0 = no deadline)", &c.Database.ConnectTimeout, nil},
+		{"database.connect_backoff", "DB_CONNECT_BACKOFF", "initial delay between connection attempts", &c.Database.ConnectBackoff, nil},
+		{"database.connect_max_backoff", "DB_CONNECT_MAX_BACKOFF", "maximum delay between connection attempts", &c.Database.ConnectMaxBackoff, nil},
+
+		{"import.file_path", "SWIFT_CODES_FILE_PATH", "CSV file imported at startup", &c.Import.FilePath, nil},
+		{"import.on_startup", "IMPORT_ON_STARTUP", "import the CSV file at startup", &c.Import.OnStartup, nil},
+
+		{"auth.anonymous_read", "AUTH_ANONYMOUS_READ", "allow unauthenticated reads", &c.Auth.AnonymousRead, nil},
+		{"auth.bootstrap_admin_key", "AUTH_BOOTSTRAP_ADMIN_KEY", "admin API key registered at startup", &c.Auth.BootstrapAdminKey, redactSecret},
+		{"auth.jwks_file", "AUTH_JWKS_FILE", "JWKS file with JWT verification keys", &c.Auth.JWKSFile, nil},
+		{"auth.jwt_issuer", "AUTH_JWT_ISSUER", "required JWT issuer", &c.Auth.JWTIssuer, nil},
+		{"auth.jwt_audience", "AUTH_JWT_AUDIENCE", "required JWT audience", &c.Auth.JWTAudience, nil},
+		{"auth.usage_flush_interval", "AUTH_USAGE_FLUSH_INTERVAL", "how often API key usage is written", &c.Auth.UsageFlushInterval, nil},
+
+		{"rate_limit.ip_rps", "RATE_LIMIT_IP_RPS", "requests per second per client IP before authentication (0 = off)", &c.RateLimit.IP.Rate, nil},
+		{"rate_limit.ip_burst", "RATE_LIMIT_IP_BURST", "per-IP bucket size", &c.RateLimit.IP.Burst, nil},
+		{"rate_limit.read_rps", "RATE_LIMIT_READ_RPS", "read requests per second per client (0 = off)", &c.RateLimit.Read.Rate, nil},
+		{"rate_limit.read_burst", "RATE_LIMIT_READ_BURST", "read bucket size", &c.RateLimit.Read.Burst, nil},
+		{"rate_limit.write_rps", "RATE_LIMIT_WRITE_RPS", "write requests per second per client (0 = off)", &c.RateLimit.Write.Rate, nil},
+		{"rate_limit.write_burst", "RATE_LIMIT_WRITE_BURST", "write bucket size", &c.RateLimit.Write.Burst, nil},
+		{"rate_limit.trusted_proxies", "RATE_LIMIT_TRUSTED_PROXIES", "comma-separated proxy addresses or CIDR ranges whose X-Forwarded-For is trusted", &c.RateLimit.TrustedProxies, nil},
+
+		{"cache.enabled", "CACHE_ENABLED", "cache lookups in memory", &c.Cache.Enabled, nil},
+		{"cache.size", "CACHE_SIZE", "maximum cached lookups (0 = unbounded)", &c.Cache.Size, nil},
+		{"cache.ttl", "CACHE_TTL", "how long a cached lookup is served (0 = until invalidated)", &c.Cache.TTL, nil},
+		{"cache.preload", "CACHE_PRELOAD", "load every SWIFT code into the cache at startup", &c.Cache.Preload, nil},
+
+		{"logging.level", "LOG_LEVEL", "debug, info, warn or error", &c.Logging.Level, nil},
+		{"logging.format", "LOG_FORMAT", "json or text", &c.Logging.Format, nil},
+
+		{"tracing.enabled", "TRACING_ENABLED", "export spans over OTLP/HTTP", &c.Tracing.Enabled, nil},
+		{"tracing.service_name", "OTEL_SERVICE_NAME", "service name resource attribute", &c.Tracing.ServiceName, nil},
+		{"tracing.sample_ratio", "TRACING_SAMPLE_RATIO", "fraction of new traces to sample", &c.Tracing.SampleRatio, nil},
+
+		{"features.metrics", "FEATURE_METRICS", "serve /metrics", &c.Features.Metrics, nil},
+		{"features.diff_endpoint", "FEATURE_DIFF_ENDPOINT", "serve POST /v1/imports:diff", &c.Features.DiffEndpoint, nil},
+		{"features.admin_api", "FEATURE_ADMIN_API", "serve /v1/admin endpoints", &c.Features.AdminAPI, nil},
+		{"features.graphql", "FEATURE_GRAPHQL", "serve /graphql", &c.Features.GraphQL, nil},
+		{"features.events", "FEATURE_EVENTS", "publish change events on /v1/events and to webhooks", &c.Features.Events, nil},
+	}
+}
+
+func (f field) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(f.key)
+}
+
+func (f field) get() string {
+	switch p := f.ptr.(type) {
+	case *string:
+		return *p
+	case *int:
+		return strconv.Itoa(*p)
+	case *bool:
+		return strconv.FormatBool(*p)
+	case *float64:
+		return strconv.FormatFloat(*p, 'g', -1, 64)
+	case *time.Duration:
+		return p.String()
+	case *[]string:
+		return strings.Join(*p, ",")
+	}
+	panic(fmt.Sprintf("config: unsupported type %T for %s", f.ptr, f.key))
+}
+
+func (f field) set(v string) error {
+	switch p := f.ptr.(type) {
+	case *string:
+		*p = v
+	case *int:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", v)
+		}
+		*p = n
+	case *bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", v)
+		}
+		*p = b
+	case *float64:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", v)
+		}
+		*p = n
+	case *time.Duration:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s", v)
+		}
+		*p = d
+	case *[]string:
+		*p = nil
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*p = append(*p, item)
+			}
+		}
+	default:
+		panic(fmt.Sprintf("config: unsupported type %T for %s", f.ptr, f.key))
+	}
+	return nil
+}
+
+// fieldValue adapts a field to flag.Value.
+type fieldValue struct {
+	field
+}
+
+func (v fieldValue) String() string {
+	if v.ptr == nil {
+		return ""
+	}
+	return v.get()
+}
+
+func (v fieldValue) Set(s string) error {
+	return v.set(s)
+}
+
+func (v fieldValue) IsBoolFlag() bool {
+	_, ok := v.ptr.(*bool)
+	return ok
+}
+
+// Load resolves the configuration from defaults, the file named by -config
+// or CONFIG_FILE, the environment and args, then validates it.
+func Load(args []string) (*Config, error) {
+	// A first pass over args finds -config and rejects malformed flags
+	// before anything else is read.
+	probe := Default()
+	fs := probe.flagSet(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if probe.File == "" {
+		probe.File = os.Getenv("CONFIG_FILE")
+	}
+
+	cfg := Default()
+	cfg.File = probe.File
+	cfg.sources = make(map[string]string)
+	fields := cfg.fields()
+	byKey := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byKey[f.key] = f
+	}
+
+	if cfg.File != "" {
+		values, err := readFile(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range sortedKeys(values) {
+			f, ok := byKey[key]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown setting %q", cfg.File, key)
+			}
+			if err := f.set(values[key]); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", cfg.File, key, err)
+			}
+			cfg.sources[key] = "file"
+		}
+	}
+
+	for _, f := range fields {
+		v, ok := os.LookupEnv(f.env)
+		if !ok || v == "" {
+			continue
+		}
+		if err := f.set(v); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.env, err)
+		}
+		cfg.sources[f.key] = "env"
+	}
+
+	fs = cfg.flagSet(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	flagKeys := make(map[string]string, len(fields))
+	for _, f := range fields {
+		flagKeys[f.flagName()] = f.key
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		if key, ok := flagKeys[fl.Name]; ok {
+			cfg.sources[key] = "flag"
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) flagSet(output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("swift-api", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&c.File, "config", c.File, "YAML or TOML config file (env CONFIG_FILE)")
+	fs.BoolVar(&c.PrintOnly, "print-config", false, "print the effective configuration and exit")
+	for _, f := range c.fields() {
+		fs.Var(fieldValue{f}, f.flagName(), fmt.Sprintf("%s (env %s)", f.usage, f.env))
+	}
+	return fs
+}
+
+// Usage writes the flag reference to w.
+func Usage(w io.Writer) {
+	fs := Default().flagSet(w)
+	fmt.Fprintln(w, "Usage of swift-api:")
+	fs.PrintDefaults()
+}
+
+// readFile flattens a YAML or TOML document into dotted keys, e.g.
+// server.addr.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("%s: config file must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten("", doc, values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flatten(prefix string, doc map[string]any, out map[string]string) error {
+	for k, v := range doc {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
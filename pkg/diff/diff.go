@@ -0,0 +1,105 @@
+package diff
+
+import (
+	"sort"
+	"swift-api/pkg/models"
+)
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type Change struct {
+	SwiftCode string        `json:"swiftCode"`
+	Fields    []FieldChange `json:"fields"`
+}
+
+type Summary struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+type Result struct {
+	Summary Summary            `json:"summary"`
+	Added   []models.SwiftCode `json:"added"`
+	Removed []models.SwiftCode `json:"removed"`
+	Changed []Change           `json:"changed"`
+}
+
+func (r Result) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// Compare reports which codes of incoming are new, which codes of current are
+// gone, and which codes present in both differ on bank name, address, town or
+// timezone.
+func Compare(current, incoming []models.SwiftCode) Result {
+	currentByCode := make(map[string]models.SwiftCode, len(current))
+	for _, c := range current {
+		currentByCode[c.SwiftCode] = c
+	}
+	incomingByCode := make(map[string]models.SwiftCode, len(incoming))
+	for _, c := range incoming {
+		incomingByCode[c.SwiftCode] = c
+	}
+
+	result := Result{
+		Added:   []models.SwiftCode{},
+		Removed: []models.SwiftCode{},
+		Changed: []Change{},
+	}
+
+	for code, in := range incomingByCode {
+		cur, exists := currentByCode[code]
+		if !exists {
+			result.Added = append(result.Added, in)
+			continue
+		}
+		if fields := compareFields(cur, in); len(fields) > 0 {
+			result.Changed = append(result.Changed, Change{SwiftCode: code, Fields: fields})
+		}
+	}
+
+	for code, cur := range currentByCode {
+		if _, exists := incomingByCode[code]; !exists {
+			result.Removed = append(result.Removed, cur)
+		}
+	}
+
+	sort.Slice(result.Added, func(i, j int) bool { return result.Added[i].SwiftCode < result.Added[j].SwiftCode })
+	sort.Slice(result.Removed, func(i, j int) bool { return result.Removed[i].SwiftCode < result.Removed[j].SwiftCode })
+	sort.Slice(result.Changed, func(i, j int) bool { return result.Changed[i].SwiftCode < result.Changed[j].SwiftCode })
+
+	result.Summary = Summary{
+		Added:   len(result.Added),
+		Removed: len(result.Removed),
+		Changed: len(result.Changed),
+	}
+	return result
+}
+
+func compareFields(cur, in models.SwiftCode) []FieldChange {
+	var fields []FieldChange
+	add := func(name, old, new string) {
+		if old != new {
+			fields = append(fields, FieldChange{Field: name, Old: old, New: new})
+		}
+	}
+
+	add("bankName", cur.BankName, in.BankName)
+	add("address", strOrEmpty(cur.Address), strOrEmpty(in.Address))
+	add("townName", cur.TownName, in.TownName)
+	add("timezone", cur.Timezone, in.Timezone)
+
+	return fields
+}
+
+func strOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,54 @@
+package diff
+
+import (
+	"swift-api/pkg/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	current := []models.SwiftCode{
+		{SwiftCode: "KEEPPLPWXXX", BankName: "Keep", Address: ptr("Main St"), TownName: "WARSAW", Timezone: "Europe/Warsaw"},
+		{SwiftCode: "GONEPLPWXXX", BankName: "Gone", TownName: "WARSAW", Timezone: "Europe/Warsaw"},
+		{SwiftCode: "EDITPLPWXXX", BankName: "Old Name", Address: ptr("Old St"), TownName: "WARSAW", Timezone: "Europe/Warsaw"},
+	}
+	incoming := []models.SwiftCode{
+		{SwiftCode: "KEEPPLPWXXX", BankName: "Keep", Address: ptr("Main St"), TownName: "WARSAW", Timezone: "Europe/Warsaw"},
+		{SwiftCode: "EDITPLPWXXX", BankName: "New Name", Address: nil, TownName: "WARSAW", Timezone: "Europe/Warsaw"},
+		{SwiftCode: "NEWWPLPWXXX", BankName: "New", TownName: "KRAKOW", Timezone: "Europe/Warsaw"},
+	}
+
+	t.Run("Added, removed and changed codes", func(t *testing.T) {
+		result := Compare(current, incoming)
+
+		assert.Equal(t, Summary{Added: 1, Removed: 1, Changed: 1}, result.Summary)
+		assert.Equal(t, "NEWWPLPWXXX", result.Added[0].SwiftCode)
+		assert.Equal(t, "GONEPLPWXXX", result.Removed[0].SwiftCode)
+		assert.Equal(t, "EDITPLPWXXX", result.Changed[0].SwiftCode)
+		assert.Equal(t, []FieldChange{
+			{Field: "bankName", Old: "Old Name", New: "New Name"},
+			{Field: "address", Old: "Old St", New: ""},
+		}, result.Changed[0].Fields)
+	})
+
+	t.Run("Identical snapshots", func(t *testing.T) {
+		result := Compare(current, current)
+
+		assert.True(t, result.Empty())
+		assert.Equal(t, Summary{}, result.Summary)
+	})
+
+	t.Run("Country fields are not compared", func(t *testing.T) {
+		result := Compare(
+			[]models.SwiftCode{{SwiftCode: "KEEPPLPWXXX", CountryName: "POLAND"}},
+			[]models.SwiftCode{{SwiftCode: "KEEPPLPWXXX", CountryName: "POLSKA"}},
+		)
+
+		assert.True(t, result.Empty())
+	})
+}
+
+func ptr(s string) *string {
+	return &s
+}
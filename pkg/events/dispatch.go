@@ -0,0 +1,366 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	// History is how many events are kept for streams resuming with
+	// Last-Event-ID.
+	History int
+	// Heartbeat is how often an idle stream sends a comment to keep proxies
+	// from closing it.
+	Heartbeat time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{History: 1000, Heartbeat: 15 * time.Second}
+}
+
+type WebhookConfig struct {
+	Timeout        time.Duration
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxQueue bounds the events waiting to be delivered together with the
+	// deliveries waiting to be retried. Events beyond it are dropped and
+	// logged.
+	MaxQueue int
+}
+
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{Timeout: 10 * time.Second, MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Minute, MaxQueue: 10000}
+}
+
+// maxDeliveries bounds the deliveries in flight across all webhooks.
+const maxDeliveries = 8
+
+// job is an event to deliver to every webhook wanting it, or only to hook.
+type job struct {
+	event Event
+	hook  *Webhook
+}
+
+// Dispatcher delivers events to the registered webhooks. Each delivery is
+// retried with exponential backoff; one that still fails is kept as a dead
+// letter, as are the events queued when the dispatcher stops.
+type Dispatcher struct {
+	store  Store
+	cfg    WebhookConfig
+	logger *slog.Logger
+	client *http.Client
+
+	mu    sync.Mutex
+	queue []job
+	// retrying counts the deliveries waiting out a backoff.
+	retrying int
+	wake     chan struct{}
+}
+
+func NewDispatcher(store Store, cfg WebhookConfig, logger *slog.Logger) *Dispatcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Dispatcher{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+		client: &http.Client{Timeout: cfg.Timeout},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Enqueue queues events for the webhooks wanting them. Events that do not
+// fit in MaxQueue are dropped.
+func (d *Dispatcher) Enqueue(events []Event) {
+	var dropped []uint64
+	d.mu.Lock()
+	for _, e := range events {
+		if d.full() {
+			dropped = append(dropped, e.ID)
+			continue
+		}
+		d.queue = append(d.queue, job{event: e})
+	}
+	d.mu.Unlock()
+	if len(dropped) > 0 {
+		d.logger.Error("Webhook queue full, events dropped", "event_ids", dropped, "max_queue", d.cfg.MaxQueue)
+	}
+	d.signal()
+}
+
+// Redeliver queues ev for hook alone, whatever types it wants. It reports
+// false when the queue is full.
+func (d *Dispatcher) Redeliver(hook Webhook, ev Event) bool {
+	d.mu.Lock()
+	full := d.full()
+	if !full {
+		d.queue = append(d.queue, job{event: ev, hook: &hook})
+	}
+	d.mu.Unlock()
+	d.signal()
+	return !full
+}
+
+// full must be called with d.mu held.
+func (d *Dispatcher) full() bool {
+	return d.cfg.MaxQueue > 0 && len(d.queue)+d.retrying >= d.cfg.MaxQueue
+}
+
+func (d *Dispatcher) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) take() []job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	jobs := d.queue
+	d.queue = nil
+	return jobs
+}
+
+// Run delivers queued events until ctx is done, then waits for the
+// deliveries in flight.
+func (d *Dispatcher) Run(ctx context.Context) {
+	sem := make(chan struct{}, maxDeliveries)
+	var wg sync.WaitGroup
+	defer func() {
+		wg.Wait()
+		d.shutdown()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.wake:
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		jobs := d.take()
+		if len(jobs) == 0 {
+			continue
+		}
+		hooks, err := d.store.ListWebhooks(ctx)
+		if err != nil {
+			d.logger.Error("Error listing webhooks, delivery postponed", "events", len(jobs), "error", err)
+			d.mu.Lock()
+			d.queue = append(jobs, d.queue...)
+			d.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(d.cfg.InitialBackoff):
+			}
+			d.signal()
+			continue
+		}
+
+		for _, j := range jobs {
+			for _, hook := range targets(j, hooks) {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					d.deadLetter(ctx, hook, j.event, 0, ctx.Err())
+					continue
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					d.deliver(ctx, sem, hook, j.event)
+				}()
+			}
+		}
+	}
+}
+
+func targets(j job, hooks []Webhook) []Webhook {
+	if j.hook != nil {
+		return []Webhook{*j.hook}
+	}
+	var wanting []Webhook
+	for _, hook := range hooks {
+		if hook.Wants(j.event.Type) {
+			wanting = append(wanting, hook)
+		}
+	}
+	return wanting
+}
+
+// shutdown keeps the events nobody got to as dead letters, so they can be
+// redelivered once the service is back.
+func (d *Dispatcher) shutdown() {
+	jobs := d.take()
+	if len(jobs) == 0 {
+		return
+	}
+	ctx := context.Background()
+	hooks, err := d.store.ListWebhooks(ctx)
+	if err != nil {
+		d.logger.Error("Events dropped on shutdown", "events", len(jobs), "error", err)
+		return
+	}
+	for _, j := range jobs {
+		for _, hook := range targets(j, hooks) {
+			d.deadLetter(ctx, hook, j.event, 0, context.Canceled)
+		}
+	}
+}
+
+// deliver is called holding a slot of sem, which it frees when done. The
+// slot is given up while waiting to retry, so a webhook that is down does
+// not hold back deliveries to the others.
+func (d *Dispatcher) deliver(ctx context.Context, sem chan struct{}, hook Webhook, ev Event) {
+	held := true
+	defer func() {
+		if held {
+			<-sem
+		}
+	}()
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		d.logger.Error("Error encoding event", "event_id", ev.ID, "error", err)
+		return
+	}
+
+	err = ctx.Err()
+	attempt := 0
+	for attempt < d.cfg.MaxAttempts && ctx.Err() == nil {
+		if !held {
+			select {
+			case sem <- struct{}{}:
+				held = true
+			case <-ctx.Done():
+				continue
+			}
+		}
+		attempt++
+		if err = d.post(ctx, hook, ev, body); err == nil {
+			return
+		}
+		if attempt == d.cfg.MaxAttempts {
+			break
+		}
+
+		delay := d.backoff(attempt - 1)
+		d.logger.Warn("Webhook delivery failed",
+			"webhook_id", hook.ID, "event_id", ev.ID, "attempt", attempt, "max_attempts", d.cfg.MaxAttempts,
+			"retry_in", delay, "error", err)
+		<-sem
+		held = false
+		d.mu.Lock()
+		d.retrying++
+		d.mu.Unlock()
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		d.mu.Lock()
+		d.retrying--
+		d.mu.Unlock()
+	}
+	d.deadLetter(ctx, hook, ev, attempt, err)
+}
+
+func (d *Dispatcher) deadLetter(ctx context.Context, hook Webhook, ev Event, attempts int, err error) {
+	d.logger.Error("Webhook delivery abandoned",
+		"webhook_id", hook.ID, "event_id", ev.ID, "attempts", attempts, "error", err)
+	letter := DeadLetter{WebhookID: hook.ID, Event: ev, Attempts: attempts, LastError: err.Error()}
+	if err := d.store.AddDeadLetter(context.WithoutCancel(ctx), letter); err != nil {
+		d.logger.Error("Error saving dead letter", "webhook_id", hook.ID, "event_id", ev.ID, "error", err)
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, hook Webhook, ev Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Swift-Event", string(ev.Type))
+	req.Header.Set("X-Swift-Event-Id", strconv.FormatUint(ev.ID, 10))
+	req.Header.Set("X-Swift-Signature", Sign(hook.Secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// backoff waits a random duration in [0, min(max, initial*2^attempt)).
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	ceiling := d.cfg.InitialBackoff
+	for i := 0; i < attempt && ceiling < d.cfg.MaxBackoff; i++ {
+		ceiling *= 2
+	}
+	if ceiling > d.cfg.MaxBackoff {
+		ceiling = d.cfg.MaxBackoff
+	}
+	return time.Duration(rand.Float64() * float64(ceiling))
+}
+
+// Sign returns the X-Swift-Signature header for body sent at t: the Unix
+// time and the hex HMAC-SHA256 of "<time>.<body>" keyed with the secret.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + signature(secret, ts, body)
+}
+
+// Verify checks a signature header as a receiver would, rejecting it when
+// it is older than tolerance.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) bool {
+	var ts, sig string
+	for part := range strings.SplitSeq(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig = value
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return false
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > tolerance || age < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(signature(secret, ts, body)))
+}
+
+func signature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
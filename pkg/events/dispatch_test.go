@@ -0,0 +1,196 @@
+package events
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records the verified deliveries it accepts and fails the first
+// failures requests.
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	failures int
+	calls    int
+	accepted []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.calls++
+	if !Verify(rc.secret, r.Header.Get("X-Swift-Signature"), body, time.Now(), time.Minute) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if rc.calls <= rc.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	rc.accepted = append(rc.accepted, r.Header.Get("X-Swift-Event")+" "+r.Header.Get("X-Swift-Event-Id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rc *receiver) count() (calls, accepted int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.calls, len(rc.accepted)
+}
+
+func register(t *testing.T, store Store, rc *receiver, types ...Type) Webhook {
+	t.Helper()
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+	hook, err := NewWebhook(srv.URL, types)
+	require.NoError(t, err)
+	rc.secret = hook.Secret
+	require.NoError(t, store.SaveWebhook(context.Background(), hook))
+	return hook
+}
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func testWebhookConfig() WebhookConfig {
+	return WebhookConfig{Timeout: time.Second, MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := NewMemoryStore()
+	d := NewDispatcher(store, testWebhookConfig(), discard)
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	flaky := &receiver{failures: 1}
+	register(t, store, flaky, SwiftCodeCreated)
+	down := &receiver{failures: 1 << 30}
+	downHook := register(t, store, down)
+
+	bus := NewBus(10, d)
+	bus.Publish(ctx, created("HOOKPLPWXXX"), Event{Type: SwiftCodeDeleted, Source: SourceAPI, SwiftCode: "HOOKPLPWXXX"})
+	first := bus.LastID() - 1
+
+	t.Run("Retried until accepted", func(t *testing.T) {
+		require.Eventually(t, func() bool { _, n := flaky.count(); return n == 1 }, 5*time.Second, time.Millisecond)
+		calls, _ := flaky.count()
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, []string{"swift_code.created " + uintString(first)}, flaky.accepted, "only the types it wants")
+	})
+
+	t.Run("Dead letters after the last attempt", func(t *testing.T) {
+		var letters []DeadLetter
+		require.Eventually(t, func() bool {
+			letters, _ = store.ListDeadLetters(ctx, downHook.ID)
+			return len(letters) == 2
+		}, 5*time.Second, time.Millisecond)
+		calls, _ := down.count()
+		assert.Equal(t, 6, calls)
+		for _, l := range letters {
+			assert.Equal(t, 3, l.Attempts)
+			assert.Equal(t, "webhook responded 503 Service Unavailable", l.LastError)
+		}
+	})
+
+	t.Run("Redelivery targets one webhook", func(t *testing.T) {
+		down.mu.Lock()
+		down.failures = 0
+		down.mu.Unlock()
+
+		d.Redeliver(downHook, Event{ID: 42, Type: SwiftCodeDeleted, SwiftCode: "HOOKPLPWXXX"})
+		require.Eventually(t, func() bool { _, n := down.count(); return n == 1 }, 5*time.Second, time.Millisecond)
+		_, n := flaky.count()
+		assert.Equal(t, 1, n)
+	})
+}
+
+func TestDispatcherShutdown(t *testing.T) {
+	store := NewMemoryStore()
+	hook := register(t, store, &receiver{})
+	d := NewDispatcher(store, testWebhookConfig(), discard)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.Enqueue([]Event{created("LATEPLPWXXX")})
+	d.Run(ctx)
+
+	letters, err := store.ListDeadLetters(context.Background(), hook.ID)
+	require.NoError(t, err)
+	require.Len(t, letters, 1, "queued events are kept for redelivery")
+	assert.Equal(t, 0, letters[0].Attempts)
+	assert.Equal(t, "LATEPLPWXXX", letters[0].Event.SwiftCode)
+}
+
+func TestSignature(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":1}`)
+	header := Sign("whsec_test", now, body)
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+
+	assert.True(t, Verify("whsec_test", header, body, now.Add(time.Minute), 5*time.Minute))
+	assert.False(t, Verify("whsec_other", header, body, now, 5*time.Minute))
+	assert.False(t, Verify("whsec_test", header, []byte(`{"id":2}`), now, 5*time.Minute))
+	assert.False(t, Verify("whsec_test", header, body, now.Add(10*time.Minute), 5*time.Minute), "replayed too late")
+	assert.False(t, Verify("whsec_test", "v1=abc", body, now, 5*time.Minute))
+}
+
+func uintString(n uint64) string {
+	return strconv.FormatUint(n, 10)
+}
+
+func TestDispatcherBackoffFreesSlot(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := NewMemoryStore()
+	cfg := testWebhookConfig()
+	cfg.InitialBackoff, cfg.MaxBackoff = time.Hour, time.Hour
+	d := NewDispatcher(store, cfg, discard)
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	register(t, store, &receiver{failures: 1 << 30})
+	healthy := &receiver{}
+	register(t, store, healthy)
+
+	batch := make([]Event, 2*maxDeliveries)
+	for i := range batch {
+		batch[i] = Event{ID: uint64(i + 1), Type: SwiftCodeCreated, SwiftCode: "SLOTPLPWXXX"}
+	}
+	d.Enqueue(batch)
+
+	require.Eventually(t, func() bool { _, n := healthy.count(); return n == len(batch) }, 5*time.Second, time.Millisecond,
+		"deliveries waiting to retry do not hold a slot")
+}
+
+func TestDispatcherQueueBound(t *testing.T) {
+	cfg := testWebhookConfig()
+	cfg.MaxQueue = 2
+	d := NewDispatcher(NewMemoryStore(), cfg, discard)
+
+	d.Enqueue([]Event{created("ONE0PLPWXXX"), created("TWO0PLPWXXX"), created("THREPLPWXXX")})
+	assert.Len(t, d.queue, 2, "events beyond the bound are dropped")
+	assert.False(t, d.Redeliver(Webhook{ID: "hook"}, created("FOURPLPWXXX")))
+}
@@ -0,0 +1,206 @@
+// Package events announces writes to SWIFT codes. The service and the
+// importer publish to a Bus, which numbers the events, keeps the latest for
+// clients resuming a stream, fans them out to live subscribers and hands
+// them to sinks such as the webhook Dispatcher.
+package events
+
+import (
+	"context"
+	"swift-api/pkg/models"
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	SwiftCodeCreated             Type = "swift_code.created"
+	SwiftCodeUpdated             Type = "swift_code.updated"
+	SwiftCodeDeleted             Type = "swift_code.deleted"
+	SwiftCodePlaceholderPromoted Type = "swift_code.placeholder_promoted"
+)
+
+// Types lists every event type, in the order the documentation gives them.
+var Types = []Type{SwiftCodeCreated, SwiftCodeUpdated, SwiftCodeDeleted, SwiftCodePlaceholderPromoted}
+
+func ParseType(s string) (Type, bool) {
+	for _, t := range Types {
+		if string(t) == s {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// Sources say which kind of write produced an event.
+const (
+	SourceAPI    = "api"
+	SourceImport = "import"
+)
+
+// Event describes one write. Data is the code as written; deletions only
+// carry SwiftCode.
+type Event struct {
+	ID        uint64            `json:"id"`
+	Type      Type              `json:"type"`
+	Time      time.Time         `json:"time"`
+	Source    string            `json:"source"`
+	SwiftCode string            `json:"swiftCode"`
+	Data      *models.SwiftCode `json:"data,omitempty"`
+}
+
+// Publisher receives the events of a write once it has succeeded.
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event)
+}
+
+// Sink receives every published event after the bus has numbered it. It is
+// called with the bus locked, so it must not block.
+type Sink interface {
+	Enqueue(events []Event)
+}
+
+// subscriberBuffer is how many events a subscriber may fall behind before it
+// is dropped. A dropped client reconnects and resumes from its last event.
+const subscriberBuffer = 256
+
+// Bus numbers events and keeps the last history of them. Numbering starts
+// at the process start time in microseconds, so IDs keep increasing across
+// restarts and an ID issued by an earlier process is reported as missed.
+type Bus struct {
+	mu      sync.Mutex
+	size    int
+	history []Event
+	lastID  uint64
+	subs    map[*Subscription]struct{}
+	sinks   []Sink
+	closed  bool
+}
+
+func NewBus(history int, sinks ...Sink) *Bus {
+	return &Bus{
+		size:   history,
+		lastID: uint64(time.Now().UnixMicro()),
+		subs:   map[*Subscription]struct{}{},
+		sinks:  sinks,
+	}
+}
+
+// Subscription delivers events published after it was created. C is closed
+// when the subscription is closed, when the bus closes, or when the
+// subscriber falls too far behind. From is the ID of the last event before
+// the first one C delivers.
+type Subscription struct {
+	C    <-chan Event
+	From uint64
+	ch   chan Event
+	bus  *Bus
+}
+
+func (b *Bus) Publish(_ context.Context, events ...Event) {
+	if len(events) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now().UTC()
+	for i := range events {
+		b.lastID++
+		events[i].ID = b.lastID
+		if events[i].Time.IsZero() {
+			events[i].Time = now
+		}
+	}
+
+	b.history = append(b.history, events...)
+	if over := len(b.history) - b.size; over > 0 {
+		b.history = append([]Event(nil), b.history[over:]...)
+	}
+
+	for sub := range b.subs {
+		if !sub.offer(events) {
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+	for _, sink := range b.sinks {
+		sink.Enqueue(events)
+	}
+}
+
+// Subscribe follows the events published from now on.
+func (b *Bus) Subscribe() *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.subscribe()
+}
+
+// Resume follows the events after lastID, returning those already
+// published. ok is false when some of them are no longer kept, or lastID
+// was never issued, as after a restart; the client has then missed events
+// and only the ones from now on follow.
+func (b *Bus) Resume(lastID uint64) (sub *Subscription, missed []Event, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = b.subscribe()
+	if lastID > b.lastID {
+		return sub, nil, false
+	}
+	oldest := b.lastID - uint64(len(b.history)) + 1
+	if lastID+1 < oldest {
+		return sub, nil, false
+	}
+	return sub, append([]Event(nil), b.history[lastID+1-oldest:]...), true
+}
+
+// LastID is the number of the latest event.
+func (b *Bus) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+func (s *Subscription) offer(events []Event) bool {
+	for _, e := range events {
+		select {
+		case s.ch <- e:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Bus) subscribe() *Subscription {
+	sub := &Subscription{From: b.lastID, ch: make(chan Event, subscriberBuffer), bus: b}
+	sub.C = sub.ch
+	if b.closed {
+		close(sub.ch)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subs[s]; ok {
+		delete(s.bus.subs, s)
+		close(s.ch)
+	}
+}
+
+// Close ends every subscription, so that streams finish on shutdown. Events
+// published afterwards still reach the sinks.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
@@ -0,0 +1,90 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sinkFunc func([]Event)
+
+func (f sinkFunc) Enqueue(events []Event) { f(events) }
+
+func created(code string) Event {
+	return Event{Type: SwiftCodeCreated, Source: SourceAPI, SwiftCode: code}
+}
+
+func TestBus(t *testing.T) {
+	ctx := context.Background()
+	var sunk []Event
+	bus := NewBus(3, sinkFunc(func(events []Event) { sunk = append(sunk, events...) }))
+	start := bus.LastID()
+
+	sub := bus.Subscribe()
+	bus.Publish(ctx, created("AAAAPLPWXXX"), created("BBBBPLPWXXX"))
+	for i, code := range []string{"AAAAPLPWXXX", "BBBBPLPWXXX"} {
+		e := <-sub.C
+		assert.Equal(t, code, e.SwiftCode)
+		assert.Equal(t, start+uint64(i)+1, e.ID)
+		assert.False(t, e.Time.IsZero())
+	}
+	assert.Len(t, sunk, 2, "sinks get every event")
+
+	t.Run("Resume replays what was missed", func(t *testing.T) {
+		resumed, missed, ok := bus.Resume(start + 1)
+		defer resumed.Close()
+		assert.True(t, ok)
+		require.Len(t, missed, 1)
+		assert.Equal(t, "BBBBPLPWXXX", missed[0].SwiftCode)
+
+		_, missed, ok = bus.Resume(bus.LastID())
+		assert.True(t, ok)
+		assert.Empty(t, missed)
+	})
+
+	t.Run("Resume past the history", func(t *testing.T) {
+		bus.Publish(ctx, created("CCCCPLPWXXX"), created("DDDDPLPWXXX"))
+		resumed, missed, ok := bus.Resume(start)
+		defer resumed.Close()
+		assert.False(t, ok, "only three events are kept")
+		assert.Empty(t, missed)
+		assert.Equal(t, bus.LastID(), resumed.From)
+
+		_, _, ok = bus.Resume(bus.LastID() + 1)
+		assert.False(t, ok, "an ID from a later process")
+	})
+
+	t.Run("Slow subscribers are dropped", func(t *testing.T) {
+		slow := bus.Subscribe()
+		for range subscriberBuffer + 1 {
+			bus.Publish(ctx, created("EEEEPLPWXXX"))
+		}
+		n := 0
+		for range slow.C {
+			n++
+		}
+		assert.Equal(t, subscriberBuffer, n)
+		slow.Close()
+	})
+
+	live := bus.Subscribe()
+	bus.Close()
+	_, open := <-live.C
+	assert.False(t, open, "closing the bus ends subscriptions")
+	live.Close()
+	sub.Close()
+	_, open = <-bus.Subscribe().C
+	assert.False(t, open)
+}
+
+func TestParseType(t *testing.T) {
+	for _, typ := range Types {
+		parsed, ok := ParseType(string(typ))
+		assert.True(t, ok)
+		assert.Equal(t, typ, parsed)
+	}
+	_, ok := ParseType("swift_code.renamed")
+	assert.False(t, ok)
+}
@@ -0,0 +1,316 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const secretPrefix = "whsec_"
+
+// Webhook receives the events of the listed types, or of every type when
+// Types is empty. Secret signs each delivery; it is only shown on creation.
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Types     []Type    `json:"types"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (w *Webhook) Wants(t Type) bool {
+	return len(w.Types) == 0 || slices.Contains(w.Types, t)
+}
+
+// NewWebhook returns a webhook with a fresh ID and secret.
+func NewWebhook(url string, types []Type) (Webhook, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return Webhook{}, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return Webhook{}, err
+	}
+	if types == nil {
+		types = []Type{}
+	}
+	return Webhook{ID: id, URL: url, Types: types, Secret: secretPrefix + secret}, nil
+}
+
+// DeadLetter is an event a webhook did not accept within the allowed
+// attempts.
+type DeadLetter struct {
+	ID        int64     `json:"id"`
+	WebhookID string    `json:"webhookId"`
+	Event     Event     `json:"event"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError"`
+	FailedAt  time.Time `json:"failedAt"`
+}
+
+// Store keeps webhooks and their dead letters. Deleting a webhook deletes
+// its dead letters.
+type Store interface {
+	SaveWebhook(ctx context.Context, hook Webhook) error
+	GetWebhook(ctx context.Context, id string) (*Webhook, error)
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) (bool, error)
+	AddDeadLetter(ctx context.Context, letter DeadLetter) error
+	ListDeadLetters(ctx context.Context, webhookID string) ([]DeadLetter, error)
+	GetDeadLetter(ctx context.Context, webhookID string, id int64) (*DeadLetter, error)
+	DeleteDeadLetter(ctx context.Context, webhookID string, id int64) (bool, error)
+}
+
+type DBStore struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+const webhookColumns = `id, url, secret, types, created_at`
+
+func scanWebhook(row interface{ Scan(...any) error }) (Webhook, error) {
+	var hook Webhook
+	var types pq.StringArray
+	err := row.Scan(&hook.ID, &hook.URL, &hook.Secret, &types, &hook.CreatedAt)
+	hook.Types = []Type{}
+	for _, t := range types {
+		hook.Types = append(hook.Types, Type(t))
+	}
+	return hook, err
+}
+
+func (s *DBStore) SaveWebhook(ctx context.Context, hook Webhook) error {
+	types := make(pq.StringArray, len(hook.Types))
+	for i, t := range hook.Types {
+		types[i] = string(t)
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO webhooks (id, url, secret, types) VALUES ($1, $2, $3, $4)`,
+		hook.ID, hook.URL, hook.Secret, types)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error saving webhook", "error", err)
+	}
+	return err
+}
+
+func (s *DBStore) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	hook, err := scanWebhook(s.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching webhook", "error", err)
+		return nil, err
+	}
+	return &hook, nil
+}
+
+func (s *DBStore) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at, id`)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error listing webhooks", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	hooks := []Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error scanning webhook", "error", err)
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, rows.Err()
+}
+
+func (s *DBStore) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error deleting webhook", "error", err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (s *DBStore) AddDeadLetter(ctx context.Context, letter DeadLetter) error {
+	event, err := json.Marshal(letter.Event)
+	if err != nil {
+		return err
+	}
+	// A webhook deleted while its delivery failed takes nothing with it.
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO webhook_dead_letters (webhook_id, event, attempts, last_error)
+		SELECT id, CAST($2 AS TEXT), CAST($3 AS INTEGER), CAST($4 AS TEXT) FROM webhooks WHERE id = $1`,
+		letter.WebhookID, string(event), letter.Attempts, letter.LastError)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error saving dead letter", "webhook_id", letter.WebhookID, "error", err)
+	}
+	return err
+}
+
+const deadLetterColumns = `id, webhook_id, event, attempts, last_error, failed_at`
+
+func scanDeadLetter(row interface{ Scan(...any) error }) (DeadLetter, error) {
+	var letter DeadLetter
+	var event string
+	if err := row.Scan(&letter.ID, &letter.WebhookID, &event, &letter.Attempts, &letter.LastError, &letter.FailedAt); err != nil {
+		return letter, err
+	}
+	return letter, json.Unmarshal([]byte(event), &letter.Event)
+}
+
+func (s *DBStore) ListDeadLetters(ctx context.Context, webhookID string) ([]DeadLetter, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+deadLetterColumns+` FROM webhook_dead_letters WHERE webhook_id = $1 ORDER BY id`, webhookID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error listing dead letters", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []DeadLetter{}
+	for rows.Next() {
+		letter, err := scanDeadLetter(rows)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error scanning dead letter", "error", err)
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, rows.Err()
+}
+
+func (s *DBStore) GetDeadLetter(ctx context.Context, webhookID string, id int64) (*DeadLetter, error) {
+	letter, err := scanDeadLetter(s.db.QueryRowContext(ctx,
+		`SELECT `+deadLetterColumns+` FROM webhook_dead_letters WHERE webhook_id = $1 AND id = $2`, webhookID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching dead letter", "error", err)
+		return nil, err
+	}
+	return &letter, nil
+}
+
+func (s *DBStore) DeleteDeadLetter(ctx context.Context, webhookID string, id int64) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhook_dead_letters WHERE webhook_id = $1 AND id = $2`, webhookID, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error deleting dead letter", "error", err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// MemoryStore is a Store that needs no database, for tests and for services
+// that embed the event feed.
+type MemoryStore struct {
+	mu      sync.Mutex
+	hooks   []Webhook
+	letters []DeadLetter
+	nextID  int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) SaveWebhook(_ context.Context, hook Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hook.CreatedAt = time.Now().UTC()
+	s.hooks = append(s.hooks, hook)
+	return nil
+}
+
+func (s *MemoryStore) GetWebhook(_ context.Context, id string) (*Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, hook := range s.hooks {
+		if hook.ID == id {
+			return &hook, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) ListWebhooks(context.Context) ([]Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Webhook{}, s.hooks...), nil
+}
+
+func (s *MemoryStore) DeleteWebhook(_ context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.hooks)
+	s.hooks = slices.DeleteFunc(s.hooks, func(h Webhook) bool { return h.ID == id })
+	s.letters = slices.DeleteFunc(s.letters, func(l DeadLetter) bool { return l.WebhookID == id })
+	return len(s.hooks) < n, nil
+}
+
+func (s *MemoryStore) AddDeadLetter(_ context.Context, letter DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.ContainsFunc(s.hooks, func(h Webhook) bool { return h.ID == letter.WebhookID }) {
+		return nil
+	}
+	s.nextID++
+	letter.ID = s.nextID
+	letter.FailedAt = time.Now().UTC()
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func (s *MemoryStore) ListDeadLetters(_ context.Context, webhookID string) ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letters := []DeadLetter{}
+	for _, l := range s.letters {
+		if l.WebhookID == webhookID {
+			letters = append(letters, l)
+		}
+	}
+	return letters, nil
+}
+
+func (s *MemoryStore) GetDeadLetter(_ context.Context, webhookID string, id int64) (*DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.letters {
+		if l.WebhookID == webhookID && l.ID == id {
+			return &l, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) DeleteDeadLetter(_ context.Context, webhookID string, id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.letters)
+	s.letters = slices.DeleteFunc(s.letters, func(l DeadLetter) bool { return l.WebhookID == webhookID && l.ID == id })
+	return len(s.letters) < n, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"swift-api/internal/database"
+)
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	hook, err := NewWebhook("https://cache.example.com/hook", []Type{SwiftCodeCreated, SwiftCodeDeleted})
+	require.NoError(t, err)
+	assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, hook.Secret)
+	require.NoError(t, store.SaveWebhook(ctx, hook))
+	all, err := NewWebhook("https://audit.example.com/hook", nil)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveWebhook(ctx, all))
+
+	stored, err := store.GetWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, hook.Secret, stored.Secret)
+	assert.Equal(t, []Type{SwiftCodeCreated, SwiftCodeDeleted}, stored.Types)
+	assert.False(t, stored.CreatedAt.IsZero())
+	assert.False(t, stored.Wants(SwiftCodePlaceholderPromoted))
+
+	hooks, err := store.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	for _, h := range hooks {
+		if h.ID == all.ID {
+			assert.Equal(t, []Type{}, h.Types)
+			assert.True(t, h.Wants(SwiftCodePlaceholderPromoted), "no types means every type")
+		}
+	}
+
+	missing, err := store.GetWebhook(ctx, "ffff")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	ev := Event{ID: 7, Type: SwiftCodeCreated, Source: SourceImport, SwiftCode: "STORPLPWXXX"}
+	for _, id := range []string{hook.ID, hook.ID, all.ID, "ffff"} {
+		require.NoError(t, store.AddDeadLetter(ctx, DeadLetter{WebhookID: id, Event: ev, Attempts: 5, LastError: "timeout"}))
+	}
+	letters, err := store.ListDeadLetters(ctx, hook.ID)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.Equal(t, ev.SwiftCode, letters[0].Event.SwiftCode)
+	assert.Equal(t, "timeout", letters[0].LastError)
+	assert.False(t, letters[0].FailedAt.IsZero())
+	none, err := store.ListDeadLetters(ctx, "ffff")
+	require.NoError(t, err)
+	assert.Empty(t, none, "letters of unknown webhooks are dropped")
+
+	letter, err := store.GetDeadLetter(ctx, hook.ID, letters[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, letter)
+	assert.Equal(t, 5, letter.Attempts)
+	letter, err = store.GetDeadLetter(ctx, all.ID, letters[0].ID)
+	require.NoError(t, err)
+	assert.Nil(t, letter, "a letter belongs to its webhook")
+
+	deleted, err := store.DeleteDeadLetter(ctx, hook.ID, letters[0].ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = store.DeleteDeadLetter(ctx, hook.ID, letters[0].ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = store.DeleteWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	letters, err = store.ListDeadLetters(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.Empty(t, letters, "deleting a webhook deletes its letters")
+	deleted, err = store.DeleteWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestDBStoreSQLite(t *testing.T) {
+	cfg := database.DefaultConfig()
+	cfg.URL = "sqlite:" + filepath.Join(t.TempDir(), "swift.db")
+	db, err := database.ConnectDB(context.Background(), cfg, discard)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, database.Migrate(context.Background(), db, database.SQLite, discard))
+
+	testStore(t, NewStore(db))
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
@@ -0,0 +1,359 @@
+// Package graphql validates and executes GraphQL queries against a schema
+// of object and scalar types. It covers what a read-only API needs:
+// variables, aliases, fragments, @skip and @include, __typename, depth and
+// complexity limits, and resolvers that defer work to batch it. Mutations,
+// subscriptions, interfaces, unions, enums, input objects and introspection
+// are not supported.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Error is a GraphQL error as it appears in the response. Resolvers may
+// return one to set Extensions; Locations and Path are filled in.
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type Params struct {
+	Query         string
+	OperationName string
+	Variables     map[string]any
+	Limits        Limits
+}
+
+// Result is the response to a query. Data is absent when the query failed
+// before execution, and null when an error left no data to return.
+type Result struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Execute parses, validates and runs a query. Fields are resolved a level at
+// a time: the thunks returned for one level run only after every field of
+// that level has been resolved, so loaders see all their keys at once.
+func Execute(ctx context.Context, schema *Schema, params Params) *Result {
+	doc, err := Parse(params.Query)
+	if err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+
+	op, err := operation(doc, params.OperationName)
+	if err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+	vars, errs := coerceVariables(op, params.Variables)
+	if len(errs) > 0 {
+		return &Result{Errors: errs}
+	}
+
+	v := &validator{doc: doc, vars: vars, active: map[string]bool{}}
+	depth, complexity := v.selectionSet(schema.Query, op.SelectionSet, map[string]*FieldSelection{})
+	if len(v.errs) > 0 {
+		return &Result{Errors: v.errs}
+	}
+	if max := params.Limits.MaxDepth; max > 0 && depth > max {
+		return &Result{Errors: []*Error{{Message: fmt.Sprintf("Query depth %d exceeds the maximum of %d.", depth, max), Locations: []Location{op.Loc}}}}
+	}
+	if max := params.Limits.MaxComplexity; max > 0 && complexity > max {
+		return &Result{Errors: []*Error{{Message: fmt.Sprintf("Query complexity %d exceeds the maximum of %d.", complexity, max), Locations: []Location{op.Loc}}}}
+	}
+
+	e := &executor{ctx: ctx, doc: doc, vars: vars}
+	data := e.executeFields(schema.Query, nil, []*FieldSelection{{SelectionSet: op.SelectionSet}}, nil, nil, false)
+	for len(e.queue) > 0 {
+		queue := e.queue
+		e.queue = nil
+		for _, run := range queue {
+			run()
+		}
+	}
+	return &Result{Data: data, Errors: e.errs}
+}
+
+func operation(doc *Document, name string) (*Operation, error) {
+	var op *Operation
+	switch {
+	case name != "":
+		for _, o := range doc.Operations {
+			if o.Name == name {
+				op = o
+			}
+		}
+		if op == nil {
+			return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q.", name)}
+		}
+	case len(doc.Operations) == 1:
+		op = doc.Operations[0]
+	case len(doc.Operations) == 0:
+		return nil, &Error{Message: "Must provide an operation."}
+	default:
+		return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+	}
+	if op.Type != "query" {
+		return nil, &Error{Message: fmt.Sprintf("Schema is not configured to execute %s operation.", op.Type), Locations: []Location{op.Loc}}
+	}
+	return op, nil
+}
+
+func toError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		copied := *e
+		return &copied
+	}
+	return &Error{Message: err.Error()}
+}
+
+// node is an object or list in the result. A null in a non-null position
+// makes the nearest nullable ancestor null.
+type node struct {
+	parent  *node
+	nonNull bool // whether the node's own position is non-null
+	null    bool
+}
+
+func (n *node) nullify() {
+	for ; n != nil && !n.null; n = n.parent {
+		n.null = true
+		if !n.nonNull {
+			return
+		}
+	}
+}
+
+type object struct {
+	node
+	keys   []string
+	values []any
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	if o.null {
+		return []byte("null"), nil
+	}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+type list struct {
+	node
+	items []any
+}
+
+func (l *list) MarshalJSON() ([]byte, error) {
+	if l.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(l.items)
+}
+
+type executor struct {
+	ctx   context.Context
+	doc   *Document
+	vars  variables
+	errs  []*Error
+	queue []func()
+}
+
+// completion is where a resolved value goes: the field it belongs to, its
+// path and the position to store it in.
+type completion struct {
+	parent     *node
+	parentType *Object
+	typ        Type
+	fields     []*FieldSelection
+	path       []any
+	put        func(any)
+}
+
+func (e *executor) fail(c completion, err error) {
+	gqlErr := toError(err)
+	gqlErr.Locations = []Location{c.fields[0].Loc}
+	gqlErr.Path = c.path
+	e.errs = append(e.errs, gqlErr)
+}
+
+// executeFields resolves the fields selected on source and returns the
+// object they form. Values not ready yet are filled in as their thunks run.
+func (e *executor) executeFields(t *Object, source any, fields []*FieldSelection, path []any, parent *node, nonNull bool) *object {
+	keys, grouped := e.collectFields(t, fields)
+	obj := &object{node: node{parent: parent, nonNull: nonNull}, keys: keys, values: make([]any, len(keys))}
+	for i, key := range keys {
+		f := grouped[key][0]
+		if f.Name == "__typename" {
+			obj.values[i] = t.Name
+			continue
+		}
+
+		def := t.Field(f.Name)
+		c := completion{
+			parent:     &obj.node,
+			parentType: t,
+			typ:        def.Type,
+			fields:     grouped[key],
+			path:       appendPath(path, key),
+			put:        func(v any) { obj.values[i] = v },
+		}
+		// Arguments were checked during validation.
+		args, _ := coerceArgs(def, f.Arguments, e.vars, f.Loc)
+		value, err := def.Resolve(ResolveParams{Context: e.ctx, Source: source, Args: args})
+		e.complete(c, value, err)
+	}
+	return obj
+}
+
+func appendPath(path []any, elem any) []any {
+	return append(path[:len(path):len(path)], elem)
+}
+
+func (e *executor) complete(c completion, value any, err error) {
+	typ := c.typ
+	nn, nonNull := typ.(*NonNull)
+	if nonNull {
+		typ = nn.OfType
+	}
+	fail := func(err error) {
+		e.fail(c, err)
+		c.put(nil)
+		if nonNull {
+			c.parent.nullify()
+		}
+	}
+
+	if err != nil {
+		fail(err)
+		return
+	}
+	if thunk, ok := value.(Thunk); ok {
+		e.queue = append(e.queue, func() {
+			value, err := thunk()
+			e.complete(c, value, err)
+		})
+		return
+	}
+
+	rv := reflect.ValueOf(value)
+	if value == nil || rv.Kind() == reflect.Pointer && rv.IsNil() {
+		if nonNull {
+			fail(fmt.Errorf("Cannot return null for non-nullable field %s.%s.", c.parentType.Name, c.fields[0].Name))
+			return
+		}
+		c.put(nil)
+		return
+	}
+
+	switch t := typ.(type) {
+	case *Scalar:
+		v, err := t.serialize(value)
+		if err != nil {
+			fail(err)
+			return
+		}
+		c.put(v)
+	case *Object:
+		c.put(e.executeFields(t, value, c.fields, c.path, c.parent, nonNull))
+	case *List:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			fail(fmt.Errorf("Expected a list for field %s.%s.", c.parentType.Name, c.fields[0].Name))
+			return
+		}
+		l := &list{node: node{parent: c.parent, nonNull: nonNull}, items: make([]any, rv.Len())}
+		c.put(l)
+		for i := range l.items {
+			e.complete(completion{
+				parent:     &l.node,
+				parentType: c.parentType,
+				typ:        t.OfType,
+				fields:     c.fields,
+				path:       appendPath(c.path, i),
+				put:        func(v any) { l.items[i] = v },
+			}, rv.Index(i).Interface(), nil)
+		}
+	}
+}
+
+// collectFields groups the fields selected on t by response key, in query
+// order, expanding fragments and dropping fields excluded by @skip or
+// @include.
+func (e *executor) collectFields(t *Object, fields []*FieldSelection) ([]string, map[string][]*FieldSelection) {
+	var keys []string
+	grouped := map[string][]*FieldSelection{}
+	visited := map[string]bool{}
+	var collect func(sels []Selection)
+	collect = func(sels []Selection) {
+		for _, sel := range sels {
+			switch sel := sel.(type) {
+			case *FieldSelection:
+				if !e.include(sel.Directives) {
+					continue
+				}
+				key := sel.ResponseKey()
+				if _, ok := grouped[key]; !ok {
+					keys = append(keys, key)
+				}
+				grouped[key] = append(grouped[key], sel)
+			case *FragmentSpread:
+				if visited[sel.Name] || !e.include(sel.Directives) {
+					continue
+				}
+				visited[sel.Name] = true
+				collect(e.doc.Fragments[sel.Name].SelectionSet)
+			case *InlineFragment:
+				if e.include(sel.Directives) {
+					collect(sel.SelectionSet)
+				}
+			}
+		}
+	}
+	for _, f := range fields {
+		collect(f.SelectionSet)
+	}
+	return keys, grouped
+}
+
+func (e *executor) include(directives []*Directive) bool {
+	for _, d := range directives {
+		args, _ := coerceArgs(conditionField, d.Arguments, e.vars, d.Loc)
+		if (d.Name == "skip") == args["if"].(bool) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
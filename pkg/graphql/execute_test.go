@@ -0,0 +1,270 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"swift-api/pkg/graphql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID   int
+	Name string
+}
+
+// testSchema serves items 1 to 99. Item parents are fetched through a
+// loader whose batches are recorded.
+func testSchema(t *testing.T) (*graphql.Schema, *[][]int) {
+	var batches [][]int
+	parents := graphql.NewLoader(func(_ context.Context, ids []int) (map[int]*item, error) {
+		batches = append(batches, ids)
+		found := map[int]*item{}
+		for _, id := range ids {
+			found[id] = &item{ID: id, Name: fmt.Sprint("item ", id)}
+		}
+		return found, nil
+	})
+
+	itemType := &graphql.Object{Name: "Item"}
+	itemType.Fields = []*graphql.Field{
+		{Name: "id", Type: &graphql.NonNull{OfType: graphql.Int}, Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(*item).ID, nil
+		}},
+		{Name: "name", Type: &graphql.NonNull{OfType: graphql.String}, Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(*item).Name, nil
+		}},
+		{Name: "parent", Type: itemType, Resolve: func(p graphql.ResolveParams) (any, error) {
+			load := parents.Load(p.Context, p.Source.(*item).ID/10)
+			return graphql.Thunk(func() (any, error) { return load() }), nil
+		}},
+		{Name: "broken", Type: &graphql.NonNull{OfType: graphql.String}, Resolve: func(graphql.ResolveParams) (any, error) {
+			return nil, errors.New("broken")
+		}},
+	}
+
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{
+			Name: "hello",
+			Type: &graphql.NonNull{OfType: graphql.String},
+			Args: []*graphql.Arg{{Name: "name", Type: graphql.String, Default: "world"}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return "hello " + p.Args["name"].(string), nil
+			},
+		},
+		{
+			Name: "item",
+			Type: itemType,
+			Args: []*graphql.Arg{{Name: "id", Type: &graphql.NonNull{OfType: graphql.Int}}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				id := p.Args["id"].(int)
+				if id < 1 || id > 99 {
+					return nil, nil
+				}
+				return &item{ID: id, Name: fmt.Sprint("item ", id)}, nil
+			},
+		},
+		{
+			Name:     "items",
+			Type:     &graphql.NonNull{OfType: &graphql.List{OfType: &graphql.NonNull{OfType: itemType}}},
+			Args:     []*graphql.Arg{{Name: "ids", Type: &graphql.NonNull{OfType: &graphql.List{OfType: &graphql.NonNull{OfType: graphql.Int}}}}},
+			ListSize: func(args map[string]any) int { return len(args["ids"].([]any)) },
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				var items []*item
+				for _, id := range p.Args["ids"].([]any) {
+					items = append(items, &item{ID: id.(int), Name: fmt.Sprint("item ", id)})
+				}
+				return items, nil
+			},
+		},
+	}}
+	schema, err := graphql.NewSchema(query)
+	require.NoError(t, err)
+	return schema, &batches
+}
+
+func execute(t *testing.T, params graphql.Params) (string, []*graphql.Error) {
+	schema, _ := testSchema(t)
+	result := graphql.Execute(context.Background(), schema, params)
+	if result.Data == nil {
+		return "", result.Errors
+	}
+	data, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+	return string(data), result.Errors
+}
+
+func messages(errs []*graphql.Error) []string {
+	var msgs []string
+	for _, e := range errs {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+func TestExecute(t *testing.T) {
+	t.Run("Fields in query order", func(t *testing.T) {
+		data, errs := execute(t, graphql.Params{Query: `{ item(id: 42) { name id __typename } hello }`})
+		assert.Empty(t, errs)
+		assert.Equal(t, `{"item":{"name":"item 42","id":42,"__typename":"Item"},"hello":"hello world"}`, data)
+	})
+
+	t.Run("Aliases and arguments", func(t *testing.T) {
+		data, errs := execute(t, graphql.Params{Query: `{ a: hello(name: "a") b: hello(name: """b""") missing: item(id: 0) { id } }`})
+		assert.Empty(t, errs)
+		assert.JSONEq(t, `{"a":"hello a","b":"hello b","missing":null}`, data)
+	})
+
+	t.Run("Variables", func(t *testing.T) {
+		params := graphql.Params{
+			Query:     `query Q($id: Int!, $name: String = "default") { item(id: $id) { id } hello(name: $name) }`,
+			Variables: map[string]any{"id": float64(7)},
+		}
+		data, errs := execute(t, params)
+		assert.Empty(t, errs)
+		assert.JSONEq(t, `{"item":{"id":7},"hello":"hello default"}`, data)
+
+		params.Variables = map[string]any{"id": "7"}
+		_, errs = execute(t, params)
+		assert.Len(t, errs, 1)
+
+		params.Variables = nil
+		_, errs = execute(t, params)
+		assert.Len(t, errs, 1)
+	})
+
+	t.Run("Fragments and directives", func(t *testing.T) {
+		data, errs := execute(t, graphql.Params{
+			Query: `query ($withName: Boolean!) {
+				item(id: 5) { ...Fields ... on Item { id @skip(if: true) } }
+			}
+			fragment Fields on Item { id name @include(if: $withName) }`,
+			Variables: map[string]any{"withName": false},
+		})
+		assert.Empty(t, errs)
+		assert.JSONEq(t, `{"item":{"id":5}}`, data)
+	})
+
+	t.Run("Errors null the nearest nullable field", func(t *testing.T) {
+		data, errs := execute(t, graphql.Params{Query: `{ item(id: 1) { id broken } hello }`})
+		assert.JSONEq(t, `{"item":null,"hello":"hello world"}`, data)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "broken", errs[0].Message)
+		assert.Equal(t, []any{"item", "broken"}, errs[0].Path)
+		assert.Equal(t, []graphql.Location{{Line: 1, Column: 20}}, errs[0].Locations)
+
+		data, errs = execute(t, graphql.Params{Query: `{ items(ids: [1, 2]) { broken } }`})
+		assert.Equal(t, "null", data)
+		assert.Len(t, errs, 2)
+	})
+
+	t.Run("Invalid queries are not executed", func(t *testing.T) {
+		for query, want := range map[string]string{
+			`{ item(id: 1) { id }`:                  "Syntax Error",
+			`{ nope }`:                              `Cannot query field "nope" on type "Query".`,
+			`{ item(id: 1) }`:                       "must have a selection of subfields",
+			`{ hello { x } }`:                       "must not have a selection",
+			`{ item { id } }`:                       `argument "id"`,
+			`{ item(id: "1") { id } }`:              "Int",
+			`{ ...Missing }`:                        `Unknown fragment "Missing".`,
+			`mutation { hello }`:                    "Schema is not configured to execute mutation operation.",
+			`{ a: hello(name: "x") a: hello }`:      `"a"`,
+			`fragment A on Query { ...A } { ...A }`: `"A"`,
+		} {
+			data, errs := execute(t, graphql.Params{Query: query})
+			assert.Empty(t, data, query)
+			if assert.NotEmpty(t, errs, query) {
+				assert.Contains(t, errs[0].Message, want, query)
+			}
+		}
+	})
+
+	t.Run("Operation name", func(t *testing.T) {
+		query := `query A { hello(name: "a") } query B { hello(name: "b") }`
+		data, errs := execute(t, graphql.Params{Query: query, OperationName: "B"})
+		assert.Empty(t, errs)
+		assert.JSONEq(t, `{"hello":"hello b"}`, data)
+
+		_, errs = execute(t, graphql.Params{Query: query})
+		assert.Equal(t, []string{"Must provide operation name if query contains multiple operations."}, messages(errs))
+	})
+}
+
+func TestLimits(t *testing.T) {
+	query := `{ items(ids: [1, 2, 3]) { id parent { parent { id } } } }`
+
+	_, errs := execute(t, graphql.Params{Query: query, Limits: graphql.Limits{MaxDepth: 3}})
+	assert.Equal(t, []string{"Query depth 4 exceeds the maximum of 3."}, messages(errs))
+
+	// 1 for items, plus 3 items of id (1) and parent (1 + 1 + 1).
+	_, errs = execute(t, graphql.Params{Query: query, Limits: graphql.Limits{MaxComplexity: 12}})
+	assert.Equal(t, []string{"Query complexity 13 exceeds the maximum of 12."}, messages(errs))
+
+	data, errs := execute(t, graphql.Params{Query: query, Limits: graphql.Limits{MaxDepth: 4, MaxComplexity: 13}})
+	assert.Empty(t, errs)
+	assert.NotEmpty(t, data)
+}
+
+func TestBatching(t *testing.T) {
+	schema, batches := testSchema(t)
+	result := graphql.Execute(context.Background(), schema, graphql.Params{
+		Query: `{ items(ids: [11, 12, 25]) { parent { id parent { id } } } }`,
+	})
+	require.Empty(t, result.Errors)
+
+	data, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"items":[
+		{"parent":{"id":1,"parent":{"id":0}}},
+		{"parent":{"id":1,"parent":{"id":0}}},
+		{"parent":{"id":2,"parent":{"id":0}}}
+	]}`, string(data))
+	// One fetch per level, each key once.
+	assert.Equal(t, [][]int{{1, 2}, {0}}, *batches)
+}
+
+func TestLoader(t *testing.T) {
+	calls := 0
+	loader := graphql.NewLoader(func(_ context.Context, keys []string) (map[string]int, error) {
+		calls++
+		if keys[0] == "fail" {
+			return nil, errors.New("down")
+		}
+		values := map[string]int{}
+		for _, k := range keys {
+			values[k] = len(k)
+		}
+		return values, nil
+	})
+	ctx := context.Background()
+
+	loader.Prime("primed", 100)
+	a, bb, primed := loader.Load(ctx, "a"), loader.Load(ctx, "bb"), loader.Load(ctx, "primed")
+	v, err := bb()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	v, _ = a()
+	assert.Equal(t, 1, v)
+	v, _ = primed()
+	assert.Equal(t, 100, v)
+	v, _ = loader.Load(ctx, "a")()
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 1, calls)
+
+	_, err = loader.Load(ctx, "fail")()
+	assert.EqualError(t, err, "down")
+	assert.Equal(t, 2, calls)
+}
+
+func TestSchemaString(t *testing.T) {
+	schema, _ := testSchema(t)
+	sdl := schema.String()
+	assert.Contains(t, sdl, "type Query {")
+	assert.Contains(t, sdl, `hello(name: String = "world"): String!`)
+	assert.Contains(t, sdl, "items(ids: [Int!]!): [Item!]!")
+	assert.Contains(t, sdl, "parent: Item")
+}
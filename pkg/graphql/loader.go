@@ -0,0 +1,81 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc fetches the values of many keys at once. Keys missing from the
+// map load as the zero value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys requested while one level of a query resolves and
+// fetches them with a single call once the first of them is needed. Results
+// are kept for the rest of the query, so a loader is created per request.
+type Loader[K comparable, V any] struct {
+	fetch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	errs    map[K]error
+}
+
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		queued:  map[K]bool{},
+		results: map[K]V{},
+		errs:    map[K]error{},
+	}
+}
+
+// Load queues key for the next batch and returns a function that waits for
+// it. Calling the function runs the batch if it has not run yet.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	_, done := l.results[key]
+	if _, failed := l.errs[key]; !done && !failed && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.queued[key] {
+			l.dispatch(ctx)
+		}
+		return l.results[key], l.errs[key]
+	}
+}
+
+// Prime stores a value fetched some other way, such as embedded in a parent,
+// so that loading key does not fetch it again.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.queued[key] {
+		l.results[key] = value
+		delete(l.errs, key)
+	}
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	for _, key := range keys {
+		delete(l.queued, key)
+	}
+
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.results[key] = values[key]
+	}
+}
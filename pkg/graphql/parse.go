@@ -0,0 +1,677 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Location is a 1-based line and column in the query text.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	Type         string // query, mutation or subscription
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+	Loc          Location
+}
+
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default *Value
+	Loc     Location
+}
+
+// TypeRef is a type as written in a variable definition. Elem is set for a
+// list.
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+// Selection is a *FieldSelection, *FragmentSpread or *InlineFragment.
+type Selection interface {
+	location() Location
+}
+
+type FieldSelection struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Loc          Location
+}
+
+// ResponseKey is the name the field has in the result.
+func (f *FieldSelection) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Loc        Location
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+func (f *FieldSelection) location() Location { return f.Loc }
+func (f *FragmentSpread) location() Location { return f.Loc }
+func (f *InlineFragment) location() Location { return f.Loc }
+
+type Argument struct {
+	Name  string
+	Value *Value
+	Loc   Location
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Loc       Location
+}
+
+type ValueKind int
+
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+// Value is a literal or a variable. Raw holds the variable name, the
+// unquoted string or the digits; List and Fields hold the elements of a list
+// or an input object.
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []*Value
+	Fields []*Argument
+	Loc    Location
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "<EOF>"
+	case tokenString:
+		return strconv.Quote(t.value)
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	// column is the rune count of the line up to columnPos. It is advanced
+	// from there rather than recounted, so a one-line query is lexed in
+	// linear time.
+	column    int
+	columnPos int
+}
+
+func (l *lexer) loc() Location {
+	l.column += utf8.RuneCountInString(l.src[l.columnPos:l.pos])
+	l.columnPos = l.pos
+	return Location{Line: l.line, Column: l.column + 1}
+}
+
+func (l *lexer) errorf(loc Location, format string, args ...any) error {
+	return &Error{Message: "Syntax Error: " + fmt.Sprintf(format, args...), Locations: []Location{loc}}
+}
+
+func (l *lexer) newline() {
+	l.line++
+	l.column = 0
+	l.columnPos = l.pos
+}
+
+// skip moves past whitespace, commas and comments.
+func (l *lexer) skip() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', ',', '\r':
+			l.pos++
+		case '\n':
+			l.pos++
+			l.newline()
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.src[l.pos:], "\ufeff") {
+				l.pos += len("\ufeff")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (l *lexer) next() (token, error) {
+	l.skip()
+	loc := l.loc()
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, loc: loc}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$&()*:=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, value: string(c), loc: loc}, nil
+	case c == '.':
+		if !strings.HasPrefix(l.src[l.pos:], "...") {
+			return token{}, l.errorf(loc, "Unexpected \".\".")
+		}
+		l.pos += 3
+		return token{kind: tokenPunct, value: "...", loc: loc}, nil
+	case isNameStart(c):
+		start := l.pos
+		for l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.number(loc)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.blockString(loc)
+		}
+		return l.string(loc)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, l.errorf(loc, "Unexpected character %q.", r)
+}
+
+func (l *lexer) number(loc Location) (token, error) {
+	start := l.pos
+	digits := func() int {
+		n := 0
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+			n++
+		}
+		return n
+	}
+
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	intStart := l.pos
+	if digits() == 0 {
+		return token{}, l.errorf(loc, "Invalid number, expected digit.")
+	}
+	if l.pos-intStart > 1 && l.src[intStart] == '0' {
+		return token{}, l.errorf(loc, "Invalid number, unexpected digit after 0.")
+	}
+
+	kind := tokenInt
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.pos++
+		kind = tokenFloat
+		if digits() == 0 {
+			return token{}, l.errorf(loc, "Invalid number, expected digit.")
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		kind = tokenFloat
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if digits() == 0 {
+			return token{}, l.errorf(loc, "Invalid number, expected digit.")
+		}
+	}
+	if l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || l.src[l.pos] == '.') {
+		return token{}, l.errorf(loc, "Invalid number, unexpected %q.", l.src[l.pos])
+	}
+	return token{kind: kind, value: l.src[start:l.pos], loc: loc}, nil
+}
+
+var escapes = map[byte]string{'"': `"`, '\\': `\`, '/': "/", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t"}
+
+func (l *lexer) string(loc Location) (token, error) {
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokenString, value: b.String(), loc: loc}, nil
+		case c == '\n' || c == '\r':
+			return token{}, l.errorf(l.loc(), "Unterminated string.")
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, l.errorf(l.loc(), "Unterminated string.")
+			}
+			e := l.src[l.pos+1]
+			if s, ok := escapes[e]; ok {
+				b.WriteString(s)
+				l.pos += 2
+				continue
+			}
+			if e != 'u' || l.pos+6 > len(l.src) {
+				return token{}, l.errorf(l.loc(), "Invalid character escape sequence.")
+			}
+			n, err := strconv.ParseUint(l.src[l.pos+2:l.pos+6], 16, 32)
+			if err != nil {
+				return token{}, l.errorf(l.loc(), "Invalid character escape sequence.")
+			}
+			b.WriteRune(rune(n))
+			l.pos += 6
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, l.errorf(l.loc(), "Unterminated string.")
+}
+
+// blockString reads a """triple-quoted""" string, removing the indentation
+// its lines share and the blank lines around it.
+func (l *lexer) blockString(loc Location) (token, error) {
+	l.pos += 3
+	start := l.pos
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			l.pos += 4
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			raw := strings.ReplaceAll(l.src[start:l.pos], `\"""`, `"""`)
+			l.pos += 3
+			return token{kind: tokenString, value: blockStringValue(raw), loc: loc}, nil
+		case l.src[l.pos] == '\n':
+			l.pos++
+			l.newline()
+		default:
+			l.pos++
+		}
+	}
+	return token{}, l.errorf(l.loc(), "Unterminated string.")
+}
+
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maxNesting bounds how deeply selection sets, list and object values and
+// list types may nest, so a hostile query cannot exhaust the stack before
+// the depth limit is checked.
+const maxNesting = 100
+
+type parser struct {
+	lex   *lexer
+	tok   token
+	depth int
+}
+
+// Parse reads an executable document: operations and fragments.
+func Parse(query string) (doc *Document, err error) {
+	p := &parser{lex: &lexer{src: query, line: 1}}
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, e
+		}
+	}()
+
+	p.advance()
+	doc = &Document{Fragments: map[string]*Fragment{}}
+	if p.tok.kind == tokenEOF {
+		p.fail(p.tok.loc, "Unexpected <EOF>.")
+	}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", Loc: p.tok.loc, SelectionSet: p.selectionSet()})
+		case p.tok.kind == tokenName && (p.tok.value == "query" || p.tok.value == "mutation" || p.tok.value == "subscription"):
+			doc.Operations = append(doc.Operations, p.operation())
+		case p.tok.kind == tokenName && p.tok.value == "fragment":
+			f := p.fragment()
+			if _, ok := doc.Fragments[f.Name]; ok {
+				p.fail(f.Loc, "There can be only one fragment named %q.", f.Name)
+			}
+			doc.Fragments[f.Name] = f
+		default:
+			p.unexpected()
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) fail(loc Location, format string, args ...any) {
+	panic(p.lex.errorf(loc, format, args...))
+}
+
+func (p *parser) unexpected() {
+	p.fail(p.tok.loc, "Unexpected %s.", p.tok)
+}
+
+func (p *parser) advance() token {
+	prev := p.tok
+	tok, err := p.lex.next()
+	if err != nil {
+		panic(err)
+	}
+	p.tok = tok
+	return prev
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) skip(punct string) bool {
+	if p.peek(punct) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+// nest enters one more level of nesting; the caller leaves it with
+// defer p.leave().
+func (p *parser) nest() {
+	p.depth++
+	if p.depth > maxNesting {
+		panic(&Error{Message: fmt.Sprintf("Query is nested deeper than %d levels.", maxNesting), Locations: []Location{p.tok.loc}})
+	}
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) expect(punct string) token {
+	if !p.peek(punct) {
+		p.fail(p.tok.loc, "Expected %q, found %s.", punct, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) name() token {
+	if p.tok.kind != tokenName {
+		p.fail(p.tok.loc, "Expected Name, found %s.", p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) operation() *Operation {
+	start := p.advance()
+	op := &Operation{Type: start.value, Loc: start.loc}
+	if p.tok.kind == tokenName {
+		op.Name = p.advance().value
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			loc := p.expect("$").loc
+			v := &VariableDefinition{Name: p.name().value, Loc: loc}
+			p.expect(":")
+			v.Type = p.typeRef()
+			if p.skip("=") {
+				v.Default = p.value(true)
+			}
+			op.Variables = append(op.Variables, v)
+		}
+	}
+	p.directives()
+	op.SelectionSet = p.selectionSet()
+	return op
+}
+
+func (p *parser) fragment() *Fragment {
+	start := p.advance()
+	name := p.name()
+	if name.value == "on" {
+		p.fail(name.loc, "Unexpected Name \"on\".")
+	}
+	if tok := p.name(); tok.value != "on" {
+		p.fail(tok.loc, "Expected \"on\", found %s.", tok)
+	}
+	f := &Fragment{Name: name.value, TypeCondition: p.name().value, Loc: start.loc}
+	p.directives()
+	f.SelectionSet = p.selectionSet()
+	return f
+}
+
+func (p *parser) typeRef() *TypeRef {
+	p.nest()
+	defer p.leave()
+	var t *TypeRef
+	if p.skip("[") {
+		t = &TypeRef{Elem: p.typeRef()}
+		p.expect("]")
+	} else {
+		t = &TypeRef{Name: p.name().value}
+	}
+	t.NonNull = p.skip("!")
+	return t
+}
+
+func (p *parser) selectionSet() []Selection {
+	p.nest()
+	defer p.leave()
+	p.expect("{")
+	var selections []Selection
+	for !p.skip("}") {
+		selections = append(selections, p.selection())
+	}
+	if len(selections) == 0 {
+		p.fail(p.tok.loc, "Expected Name, found \"}\".")
+	}
+	return selections
+}
+
+func (p *parser) selection() Selection {
+	if p.peek("...") {
+		loc := p.advance().loc
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			return &FragmentSpread{Name: p.advance().value, Directives: p.directives(), Loc: loc}
+		}
+		f := &InlineFragment{Loc: loc}
+		if p.tok.kind == tokenName {
+			p.advance()
+			f.TypeCondition = p.name().value
+		}
+		f.Directives = p.directives()
+		f.SelectionSet = p.selectionSet()
+		return f
+	}
+
+	name := p.name()
+	f := &FieldSelection{Name: name.value, Loc: name.loc}
+	if p.skip(":") {
+		f.Alias = f.Name
+		f.Name = p.name().value
+	}
+	f.Arguments = p.arguments(false)
+	f.Directives = p.directives()
+	if p.peek("{") {
+		f.SelectionSet = p.selectionSet()
+	}
+	return f
+}
+
+func (p *parser) arguments(constant bool) []*Argument {
+	if !p.skip("(") {
+		return nil
+	}
+	var args []*Argument
+	for !p.skip(")") {
+		name := p.name()
+		p.expect(":")
+		args = append(args, &Argument{Name: name.value, Value: p.value(constant), Loc: name.loc})
+	}
+	return args
+}
+
+func (p *parser) directives() []*Directive {
+	var directives []*Directive
+	for p.peek("@") {
+		loc := p.advance().loc
+		directives = append(directives, &Directive{Name: p.name().value, Arguments: p.arguments(false), Loc: loc})
+	}
+	return directives
+}
+
+// value reads a literal; constant ones, such as variable defaults, cannot
+// refer to variables.
+func (p *parser) value(constant bool) *Value {
+	p.nest()
+	defer p.leave()
+	tok := p.tok
+	v := &Value{Raw: tok.value, Loc: tok.loc}
+	switch tok.kind {
+	case tokenInt:
+		v.Kind = IntValue
+	case tokenFloat:
+		v.Kind = FloatValue
+	case tokenString:
+		v.Kind = StringValue
+	case tokenName:
+		switch tok.value {
+		case "true", "false":
+			v.Kind = BooleanValue
+		case "null":
+			v.Kind = NullValue
+		default:
+			v.Kind = EnumValue
+		}
+	case tokenPunct:
+		switch tok.value {
+		case "$":
+			if constant {
+				p.unexpected()
+			}
+			p.advance()
+			v.Kind = VariableValue
+			v.Raw = p.name().value
+			return v
+		case "[":
+			p.advance()
+			v.Kind = ListValue
+			for !p.skip("]") {
+				v.List = append(v.List, p.value(constant))
+			}
+			return v
+		case "{":
+			p.advance()
+			v.Kind = ObjectValue
+			for !p.skip("}") {
+				name := p.name()
+				p.expect(":")
+				v.Fields = append(v.Fields, &Argument{Name: name.value, Value: p.value(constant), Loc: name.loc})
+			}
+			return v
+		}
+		p.unexpected()
+	default:
+		p.unexpected()
+	}
+	p.advance()
+	return v
+}
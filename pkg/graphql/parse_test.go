@@ -0,0 +1,66 @@
+package graphql_test
+
+import (
+	"strings"
+	"swift-api/pkg/graphql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Locations count runes per line", func(t *testing.T) {
+		doc, err := graphql.Parse("{ a(s: \"żółw\") b\n  c # ünïcode\n  d }")
+		require.NoError(t, err)
+		sels := doc.Operations[0].SelectionSet
+		require.Len(t, sels, 4)
+		var locs []graphql.Location
+		for _, sel := range sels {
+			locs = append(locs, sel.(*graphql.FieldSelection).Loc)
+		}
+		assert.Equal(t, []graphql.Location{{Line: 1, Column: 3}, {Line: 1, Column: 16}, {Line: 2, Column: 3}, {Line: 3, Column: 3}}, locs)
+	})
+
+	t.Run("Locations after a block string", func(t *testing.T) {
+		_, err := graphql.Parse("{ a(s: \"\"\"\n  ł\n\"\"\") ! }")
+		var gqlErr *graphql.Error
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, []graphql.Location{{Line: 3, Column: 6}}, gqlErr.Locations)
+	})
+
+	t.Run("Locations at the end of a long line", func(t *testing.T) {
+		query := "{ a(s: \"" + strings.Repeat("ą", 10000) + "\") ! }"
+		_, err := graphql.Parse(query)
+		var gqlErr *graphql.Error
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, []graphql.Location{{Line: 1, Column: 10012}}, gqlErr.Locations)
+	})
+
+	for name, query := range map[string]string{
+		"Selection sets": strings.Repeat("{ a ", 101) + strings.Repeat("}", 101),
+		"List values":    "{ a(x: " + strings.Repeat("[", 101) + strings.Repeat("]", 101) + ") }",
+		"Object values":  "{ a(x: " + strings.Repeat("{ y: ", 101) + "1" + strings.Repeat("}", 101) + ") }",
+		"List types":     "query($v: " + strings.Repeat("[", 101) + "Int" + strings.Repeat("]", 101) + ") { a }",
+	} {
+		t.Run(name+" nest at most 100 levels", func(t *testing.T) {
+			_, err := graphql.Parse(query)
+			require.Error(t, err)
+			assert.Equal(t, "Query is nested deeper than 100 levels.", err.Error())
+		})
+	}
+
+	t.Run("Deep but allowed nesting parses", func(t *testing.T) {
+		_, err := graphql.Parse("{ a(x: " + strings.Repeat("[", 99) + strings.Repeat("]", 99) + ") }")
+		assert.NoError(t, err)
+	})
+}
+
+func BenchmarkParseLongLine(b *testing.B) {
+	query := "{ " + strings.Repeat("hello(name: \"ab\") ", 5000) + "}"
+	for b.Loop() {
+		if _, err := graphql.Parse(query); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type is a *Scalar, *Object, *List or *NonNull.
+type Type interface {
+	String() string
+}
+
+// Scalar is one of the built-in leaf types String, Int and Boolean.
+type Scalar struct {
+	Name string
+}
+
+var (
+	String  = &Scalar{Name: "String"}
+	Int     = &Scalar{Name: "Int"}
+	Boolean = &Scalar{Name: "Boolean"}
+)
+
+var scalars = map[string]*Scalar{"String": String, "Int": Int, "Boolean": Boolean}
+
+func (s *Scalar) String() string { return s.Name }
+
+// serialize converts a resolved Go value to its JSON form.
+func (s *Scalar) serialize(v any) (any, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch s {
+	case String:
+		if rv.Kind() == reflect.String {
+			return rv.String(), nil
+		}
+	case Int:
+		if rv.CanInt() && rv.Int() >= math.MinInt32 && rv.Int() <= math.MaxInt32 {
+			return rv.Int(), nil
+		}
+		if rv.CanUint() && rv.Uint() <= math.MaxInt32 {
+			return rv.Uint(), nil
+		}
+	case Boolean:
+		if rv.Kind() == reflect.Bool {
+			return rv.Bool(), nil
+		}
+	}
+	return nil, fmt.Errorf("%s cannot represent value: %v", s.Name, v)
+}
+
+// parseLiteral coerces an argument written in the query.
+func (s *Scalar) parseLiteral(v *Value) (any, bool) {
+	switch s {
+	case String:
+		return v.Raw, v.Kind == StringValue
+	case Int:
+		n, err := strconv.ParseInt(v.Raw, 10, 32)
+		return int(n), v.Kind == IntValue && err == nil
+	case Boolean:
+		return v.Raw == "true", v.Kind == BooleanValue
+	}
+	return nil, false
+}
+
+// parseValue coerces a variable decoded from JSON.
+func (s *Scalar) parseValue(v any) (any, bool) {
+	switch s {
+	case String:
+		str, ok := v.(string)
+		return str, ok
+	case Int:
+		f, ok := v.(float64)
+		return int(f), ok && f == math.Trunc(f) && f >= math.MinInt32 && f <= math.MaxInt32
+	case Boolean:
+		b, ok := v.(bool)
+		return b, ok
+	}
+	return nil, false
+}
+
+// Object is an output type with fields. Fields may refer back to the object,
+// so they can be set after it is created.
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+
+	fields map[string]*Field
+}
+
+func (o *Object) String() string { return o.Name }
+
+// Field looks a field up by name.
+func (o *Object) Field(name string) *Field {
+	return o.fields[name]
+}
+
+type List struct {
+	OfType Type
+}
+
+func (l *List) String() string { return "[" + l.OfType.String() + "]" }
+
+type NonNull struct {
+	OfType Type
+}
+
+func (n *NonNull) String() string { return n.OfType.String() + "!" }
+
+// ResolveParams are the inputs of a resolver: the object the field belongs
+// to and the coerced arguments, defaults applied. An argument omitted
+// without a default is absent from Args.
+type ResolveParams struct {
+	Context context.Context
+	Source  any
+	Args    map[string]any
+}
+
+// ResolveFunc returns the value of a field. It may return a Thunk to delay
+// the work until the other fields at the same depth have been resolved, so
+// that a loader can fetch for all of them at once. A nil slice resolves to an
+// empty list.
+type ResolveFunc func(p ResolveParams) (any, error)
+
+// Thunk is a value still to be computed.
+type Thunk func() (any, error)
+
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Arg
+	Resolve     ResolveFunc
+	// ListSize estimates how many items a list field returns for the given
+	// arguments, for query complexity. Without it a list counts as one
+	// item.
+	ListSize func(args map[string]any) int
+}
+
+type Arg struct {
+	Name        string
+	Description string
+	Type        Type
+	// Default applies when the argument is omitted; nil means none.
+	Default any
+}
+
+// Schema is the type system a query is validated and executed against. Only
+// queries are supported.
+type Schema struct {
+	Query *Object
+	types []*Object
+}
+
+// NewSchema checks the types reachable from query: every field needs a type
+// and a resolver, and names must be unique.
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{Query: query}
+	seen := map[string]*Object{}
+	var visit func(t Type) error
+	visit = func(t Type) error {
+		switch t := t.(type) {
+		case *NonNull:
+			return visit(t.OfType)
+		case *List:
+			return visit(t.OfType)
+		case *Scalar:
+			if scalars[t.Name] != t {
+				return fmt.Errorf("unknown scalar %s", t.Name)
+			}
+			return nil
+		case *Object:
+			if other, ok := seen[t.Name]; ok {
+				if other != t {
+					return fmt.Errorf("two types are named %s", t.Name)
+				}
+				return nil
+			}
+			seen[t.Name] = t
+			s.types = append(s.types, t)
+			if len(t.Fields) == 0 {
+				return fmt.Errorf("%s has no fields", t.Name)
+			}
+			t.fields = map[string]*Field{}
+			for _, f := range t.Fields {
+				if _, ok := t.fields[f.Name]; ok || strings.HasPrefix(f.Name, "__") {
+					return fmt.Errorf("%s.%s: duplicate or reserved name", t.Name, f.Name)
+				}
+				t.fields[f.Name] = f
+				if f.Type == nil || f.Resolve == nil {
+					return fmt.Errorf("%s.%s needs a type and a resolver", t.Name, f.Name)
+				}
+				for _, arg := range f.Args {
+					if _, ok := leafType(arg.Type).(*Scalar); !ok {
+						return fmt.Errorf("%s.%s(%s:) must be a scalar or a list of scalars", t.Name, f.Name, arg.Name)
+					}
+				}
+				if err := visit(f.Type); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("unsupported type %T", t)
+	}
+	if err := visit(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func leafType(t Type) Type {
+	for {
+		switch u := t.(type) {
+		case *NonNull:
+			t = u.OfType
+		case *List:
+			t = u.OfType
+		default:
+			return t
+		}
+	}
+}
+
+// String prints the schema in the GraphQL schema definition language, query
+// type first and the others by name.
+func (s *Schema) String() string {
+	types := append([]*Object(nil), s.types[1:]...)
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+
+	var b strings.Builder
+	for i, t := range append([]*Object{s.Query}, types...) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeDescription(&b, "", t.Description)
+		fmt.Fprintf(&b, "type %s {\n", t.Name)
+		for _, f := range t.Fields {
+			writeDescription(&b, "  ", f.Description)
+			b.WriteString("  " + f.Name)
+			if len(f.Args) > 0 {
+				args := make([]string, len(f.Args))
+				for i, arg := range f.Args {
+					args[i] = arg.Name + ": " + arg.Type.String()
+					if arg.Default != nil {
+						args[i] += " = " + literal(arg.Default)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		fmt.Fprintf(b, "%s%s\n", indent, strconv.Quote(description))
+	}
+}
+
+func literal(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(v)
+}
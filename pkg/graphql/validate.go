@@ -0,0 +1,427 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// Limits bound the queries Execute accepts. Zero disables a limit.
+type Limits struct {
+	// MaxDepth is how deeply fields may nest; a query of top-level scalar
+	// fields has depth 1.
+	MaxDepth int
+	// MaxComplexity caps the estimated number of fields resolved. Each field
+	// costs one, and the fields selected under a list count once per
+	// expected item, see Field.ListSize.
+	MaxComplexity int
+}
+
+type validator struct {
+	doc    *Document
+	vars   variables
+	errs   []*Error
+	active map[string]bool // fragments being expanded, to catch cycles
+}
+
+// selectionSet checks sels against t and returns how deep they nest and the
+// complexity of resolving them once. keys holds the fields already selected
+// at this level, fragments included, by response key.
+func (v *validator) selectionSet(t *Object, sels []Selection, keys map[string]*FieldSelection) (depth, complexity int) {
+	for _, sel := range sels {
+		var d, c int
+		switch sel := sel.(type) {
+		case *FieldSelection:
+			d, c = v.field(t, sel, keys)
+		case *FragmentSpread:
+			v.directives(sel.Directives)
+			f, ok := v.doc.Fragments[sel.Name]
+			if !ok {
+				v.errorf(sel.Loc, "Unknown fragment %q.", sel.Name)
+				continue
+			}
+			if v.active[sel.Name] {
+				v.errorf(sel.Loc, "Cannot spread fragment %q within itself.", sel.Name)
+				continue
+			}
+			if !v.typeCondition(t, f.TypeCondition, f.Loc, fmt.Sprintf("Fragment %q", sel.Name)) {
+				continue
+			}
+			v.active[sel.Name] = true
+			d, c = v.selectionSet(t, f.SelectionSet, keys)
+			delete(v.active, sel.Name)
+		case *InlineFragment:
+			v.directives(sel.Directives)
+			if sel.TypeCondition != "" && !v.typeCondition(t, sel.TypeCondition, sel.Loc, "Fragment") {
+				continue
+			}
+			d, c = v.selectionSet(t, sel.SelectionSet, keys)
+		}
+		depth = max(depth, d)
+		complexity += c
+	}
+	return depth, complexity
+}
+
+// typeCondition reports whether a fragment on typeName applies to t. With
+// object types only, that means naming t itself.
+func (v *validator) typeCondition(t *Object, typeName string, loc Location, what string) bool {
+	if typeName == t.Name {
+		return true
+	}
+	v.errorf(loc, "%s cannot be spread here as objects of type %q can never be of type %q.", what, t.Name, typeName)
+	return false
+}
+
+func (v *validator) field(t *Object, f *FieldSelection, keys map[string]*FieldSelection) (depth, complexity int) {
+	v.directives(f.Directives)
+	key := f.ResponseKey()
+	if other, ok := keys[key]; ok && other.Name != f.Name {
+		v.errorf(f.Loc, "Fields %q conflict because %q and %q are different fields.", key, other.Name, f.Name)
+	} else if ok && !sameArguments(other.Arguments, f.Arguments) {
+		v.errorf(f.Loc, "Fields %q conflict because they have differing arguments.", key)
+	}
+	keys[key] = f
+
+	if f.Name == "__typename" {
+		if len(f.Arguments) > 0 || f.SelectionSet != nil {
+			v.errorf(f.Loc, "__typename takes no arguments or subfields.")
+		}
+		return 1, 0
+	}
+	def := t.Field(f.Name)
+	if def == nil {
+		v.errorf(f.Loc, "Cannot query field %q on type %q.", f.Name, t.Name)
+		return 0, 0
+	}
+	args, err := coerceArgs(def, f.Arguments, v.vars, f.Loc)
+	if err != nil {
+		v.errs = append(v.errs, err)
+	}
+
+	obj, ok := leafType(def.Type).(*Object)
+	if !ok {
+		if f.SelectionSet != nil {
+			v.errorf(f.Loc, "Field %q must not have a selection since type %q has no subfields.", f.Name, def.Type)
+		}
+		return 1, 1
+	}
+	if f.SelectionSet == nil {
+		v.errorf(f.Loc, "Field %q of type %q must have a selection of subfields.", f.Name, def.Type)
+		return 1, 1
+	}
+
+	d, c := v.selectionSet(obj, f.SelectionSet, map[string]*FieldSelection{})
+	size := 1
+	if def.ListSize != nil && err == nil {
+		size = max(def.ListSize(args), 0)
+	}
+	return 1 + d, 1 + size*c
+}
+
+func sameArguments(a, b []*Argument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if x.Name == y.Name {
+				found = printValue(x.Value) == printValue(y.Value)
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// directives checks @skip and @include, the only directives supported.
+func (v *validator) directives(directives []*Directive) {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			v.errorf(d.Loc, "Unknown directive \"@%s\".", d.Name)
+			continue
+		}
+		if _, err := coerceArgs(conditionField, d.Arguments, v.vars, d.Loc); err != nil {
+			v.errs = append(v.errs, err)
+		}
+	}
+}
+
+// conditionField declares the argument of @skip and @include.
+var conditionField = &Field{Name: "@if", Args: []*Arg{{Name: "if", Type: &NonNull{OfType: Boolean}}}}
+
+func (v *validator) errorf(loc Location, format string, args ...any) {
+	v.errs = append(v.errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+// variables are the coerced variable values of an operation, with the
+// definitions they were coerced against.
+type variables struct {
+	defs   map[string]*VariableDefinition
+	values map[string]any
+}
+
+// coerceVariables checks the values supplied for op against its variable
+// definitions and applies defaults. A variable neither supplied nor
+// defaulted is left out, so an argument it is passed to counts as omitted.
+func coerceVariables(op *Operation, supplied map[string]any) (variables, []*Error) {
+	vars := variables{defs: map[string]*VariableDefinition{}, values: map[string]any{}}
+	var errs []*Error
+	fail := func(loc Location, format string, args ...any) {
+		errs = append(errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+	}
+
+	for _, def := range op.Variables {
+		if _, ok := vars.defs[def.Name]; ok {
+			fail(def.Loc, "There can be only one variable named \"$%s\".", def.Name)
+			continue
+		}
+		vars.defs[def.Name] = def
+		t, ok := inputType(def.Type)
+		if !ok {
+			fail(def.Loc, "Variable \"$%s\" cannot be of type %q.", def.Name, def.Type)
+			continue
+		}
+
+		value, supplied := supplied[def.Name]
+		switch {
+		case supplied:
+			coerced, ok := coerceValue(value, t)
+			if !ok {
+				fail(def.Loc, "Variable \"$%s\" got invalid value %s; expected type %q.", def.Name, jsonString(value), t)
+				continue
+			}
+			vars.values[def.Name] = coerced
+		case def.Default != nil:
+			coerced, err := coerceLiteral(def.Default, t, variables{})
+			if err != nil {
+				fail(def.Default.Loc, "Variable \"$%s\" has an invalid default value: %s", def.Name, err.Message)
+				continue
+			}
+			vars.values[def.Name] = coerced
+		case def.Type.NonNull:
+			fail(def.Loc, "Variable \"$%s\" of required type %q was not provided.", def.Name, def.Type)
+		}
+	}
+	return vars, errs
+}
+
+// inputType resolves a variable's type; only scalars and lists of them are
+// inputs.
+func inputType(ref *TypeRef) (Type, bool) {
+	var t Type
+	if ref.Elem != nil {
+		elem, ok := inputType(ref.Elem)
+		if !ok {
+			return nil, false
+		}
+		t = &List{OfType: elem}
+	} else {
+		s, ok := scalars[ref.Name]
+		if !ok {
+			return nil, false
+		}
+		t = s
+	}
+	if ref.NonNull {
+		t = &NonNull{OfType: t}
+	}
+	return t, true
+}
+
+// coerceValue coerces a JSON value. A single value where a list is expected
+// becomes a list of one.
+func coerceValue(v any, t Type) (any, bool) {
+	if nn, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, false
+		}
+		return coerceValue(v, nn.OfType)
+	}
+	if v == nil {
+		return nil, true
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := v.([]any)
+		if !ok {
+			items = []any{v}
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			if out[i], ok = coerceValue(item, t.OfType); !ok {
+				return nil, false
+			}
+		}
+		return out, true
+	case *Scalar:
+		return t.parseValue(v)
+	}
+	return nil, false
+}
+
+// coerceLiteral coerces a value written in the query, substituting
+// variables.
+func coerceLiteral(v *Value, t Type, vars variables) (any, *Error) {
+	invalid := func() (any, *Error) {
+		return nil, &Error{Message: fmt.Sprintf("Expected value of type %q, found %s.", t, printValue(v)), Locations: []Location{v.Loc}}
+	}
+
+	if v.Kind == VariableValue {
+		def, ok := vars.defs[v.Raw]
+		if !ok {
+			return nil, &Error{Message: fmt.Sprintf("Variable \"$%s\" is not defined.", v.Raw), Locations: []Location{v.Loc}}
+		}
+		if !fits(def, t) {
+			return nil, &Error{
+				Message:   fmt.Sprintf("Variable \"$%s\" of type %q used in position expecting type %q.", v.Raw, def.Type, t),
+				Locations: []Location{v.Loc},
+			}
+		}
+		return vars.values[v.Raw], nil
+	}
+
+	if nn, ok := t.(*NonNull); ok {
+		if v.Kind == NullValue {
+			return invalid()
+		}
+		value, err := coerceLiteral(v, nn.OfType, vars)
+		if err == nil && value == nil {
+			return invalid()
+		}
+		return value, err
+	}
+	if v.Kind == NullValue {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		if v.Kind != ListValue {
+			item, err := coerceLiteral(v, t.OfType, vars)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		out := make([]any, len(v.List))
+		for i, item := range v.List {
+			var err *Error
+			if out[i], err = coerceLiteral(item, t.OfType, vars); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case *Scalar:
+		if value, ok := t.parseLiteral(v); ok {
+			return value, nil
+		}
+	}
+	return invalid()
+}
+
+// fits reports whether a variable can be passed where t is expected. A
+// nullable variable fits a non-null position only with a default.
+func fits(def *VariableDefinition, t Type) bool {
+	ref := def.Type
+	if nn, ok := t.(*NonNull); ok {
+		if !ref.NonNull && def.Default == nil {
+			return false
+		}
+		t = nn.OfType
+	}
+	var match func(ref *TypeRef, t Type) bool
+	match = func(ref *TypeRef, t Type) bool {
+		if nn, ok := t.(*NonNull); ok {
+			return ref.NonNull && match(&TypeRef{Name: ref.Name, Elem: ref.Elem}, nn.OfType)
+		}
+		if l, ok := t.(*List); ok {
+			return ref.Elem != nil && match(ref.Elem, l.OfType)
+		}
+		return ref.Elem == nil && ref.Name == t.String()
+	}
+	return match(&TypeRef{Name: ref.Name, Elem: ref.Elem}, t)
+}
+
+// coerceArgs coerces the arguments given to a field against its
+// definition and applies defaults.
+func coerceArgs(def *Field, given []*Argument, vars variables, loc Location) (map[string]any, *Error) {
+	args := map[string]any{}
+	seen := map[string]bool{}
+	for _, arg := range given {
+		if seen[arg.Name] {
+			return nil, &Error{Message: fmt.Sprintf("There can be only one argument named %q.", arg.Name), Locations: []Location{arg.Loc}}
+		}
+		seen[arg.Name] = true
+		if argDef(def, arg.Name) == nil {
+			return nil, &Error{Message: fmt.Sprintf("Unknown argument %q on field %q.", arg.Name, def.Name), Locations: []Location{arg.Loc}}
+		}
+	}
+
+	for _, a := range def.Args {
+		var value *Value
+		for _, arg := range given {
+			if arg.Name == a.Name {
+				value = arg.Value
+			}
+		}
+		if value != nil && value.Kind == VariableValue {
+			if _, ok := vars.values[value.Raw]; !ok && vars.defs[value.Raw] != nil {
+				value = nil
+			}
+		}
+
+		if value == nil {
+			if a.Default != nil {
+				args[a.Name] = a.Default
+			} else if _, ok := a.Type.(*NonNull); ok {
+				return nil, &Error{Message: fmt.Sprintf("Field %q argument %q of type %q is required, but it was not provided.", def.Name, a.Name, a.Type), Locations: []Location{loc}}
+			}
+			continue
+		}
+		coerced, err := coerceLiteral(value, a.Type, vars)
+		if err != nil {
+			err.Message = fmt.Sprintf("Argument %q has invalid value %s. %s", a.Name, printValue(value), err.Message)
+			return nil, err
+		}
+		args[a.Name] = coerced
+	}
+	return args, nil
+}
+
+func argDef(def *Field, name string) *Arg {
+	for _, a := range def.Args {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func printValue(v *Value) string {
+	switch v.Kind {
+	case VariableValue:
+		return "$" + v.Raw
+	case StringValue:
+		return jsonString(v.Raw)
+	case ListValue:
+		s := "["
+		for i, item := range v.List {
+			if i > 0 {
+				s += ", "
+			}
+			s += printValue(item)
+		}
+		return s + "]"
+	case ObjectValue:
+		s := "{"
+		for i, f := range v.Fields {
+			if i > 0 {
+				s += ", "
+			}
+			s += f.Name + ": " + printValue(f.Value)
+		}
+		return s + "}"
+	}
+	return v.Raw
+}
@@ -0,0 +1,104 @@
+// Package graphqlapi serves the SWIFT codes, countries and institutions as a
+// GraphQL schema at /graphql, through the same service layer as the REST
+// API.
+package graphqlapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"swift-api/pkg/graphql"
+	"swift-api/pkg/service"
+)
+
+// maxBodyBytes bounds a POSTed query with its variables.
+const maxBodyBytes = 1 << 20
+
+type Config struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+func DefaultConfig() Config {
+	return Config{MaxDepth: 10, MaxComplexity: 5000}
+}
+
+// Handler answers GraphQL queries sent as GET with query parameters or as
+// POST with a JSON body. Every request gets its own loaders.
+type Handler struct {
+	svc    *service.Service
+	schema *graphql.Schema
+	limits graphql.Limits
+}
+
+func New(svc *service.Service, cfg Config) *Handler {
+	schema, err := newSchema(svc)
+	if err != nil {
+		panic("graphqlapi: " + err.Error())
+	}
+	return &Handler{svc: svc, schema: schema, limits: graphql.Limits{MaxDepth: cfg.MaxDepth, MaxComplexity: cfg.MaxComplexity}}
+}
+
+// Schema returns the schema in the GraphQL schema definition language.
+func (h *Handler) Schema() string {
+	return h.schema.String()
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if vars := query.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				writeErrors(w, http.StatusBadRequest, "Variables must be a JSON object.")
+				return
+			}
+		}
+	} else {
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req)
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			writeErrors(w, http.StatusRequestEntityTooLarge, "Request body is too large.")
+			return
+		case err != nil:
+			writeErrors(w, http.StatusBadRequest, "Request body must be a JSON object with a query.")
+			return
+		}
+	}
+	if req.Query == "" {
+		writeErrors(w, http.StatusBadRequest, "Must provide query string.")
+		return
+	}
+
+	ctx := withLoaders(r.Context(), newLoaders(h.svc.Repo))
+	result := graphql.Execute(ctx, h.schema, graphql.Params{
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+		Limits:        h.limits,
+	})
+
+	// A query rejected before execution has no data.
+	status := http.StatusOK
+	if result.Data == nil {
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func writeErrors(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(graphql.Result{Errors: []*graphql.Error{{Message: message}}})
+}
@@ -0,0 +1,287 @@
+package graphqlapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"swift-api/pkg/graphqlapi"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"swift-api/pkg/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRepo counts the repository calls a query makes.
+type countingRepo struct {
+	repository.Repository
+	calls map[string]int
+	err   error
+}
+
+func (r *countingRepo) GetSwiftCodesByCodes(ctx context.Context, codes []string) ([]models.SwiftCode, error) {
+	r.calls["GetSwiftCodesByCodes"]++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.Repository.GetSwiftCodesByCodes(ctx, codes)
+}
+
+func (r *countingRepo) GetBranchesByHeadquarters(ctx context.Context, hqs []string) ([]models.SwiftCode, error) {
+	r.calls["GetBranchesByHeadquarters"]++
+	return r.Repository.GetBranchesByHeadquarters(ctx, hqs)
+}
+
+func (r *countingRepo) GetSwiftCodesByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	r.calls["GetSwiftCodesByCountry"]++
+	return r.Repository.GetSwiftCodesByCountry(ctx, iso2)
+}
+
+func (r *countingRepo) SearchInstitutions(ctx context.Context, q repository.InstitutionQuery) ([]models.Institution, int, error) {
+	r.calls["SearchInstitutions"]++
+	return r.Repository.SearchInstitutions(ctx, q)
+}
+
+// seed stores three Polish headquarters with two branches each and one
+// German headquarter.
+func seed(t *testing.T) repository.Repository {
+	var codes []models.SwiftCode
+	for i := range 3 {
+		hq := fmt.Sprintf("GQ%02dPLPWXXX", i)
+		codes = append(codes,
+			models.SwiftCode{SwiftCode: hq, BankName: "GRAPH BANK", TownName: "WARSZAWA", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+			models.SwiftCode{SwiftCode: fmt.Sprintf("GQ%02dPLPW001", i), BankName: "GRAPH BANK", TownName: "KRAKOW", CountryISO2: "PL", CountryName: "POLAND", HeadquarterSWIFTCode: &hq},
+			models.SwiftCode{SwiftCode: fmt.Sprintf("GQ%02dPLPW002", i), BankName: "GRAPH BANK", TownName: "GDANSK", CountryISO2: "PL", CountryName: "POLAND", HeadquarterSWIFTCode: &hq},
+		)
+	}
+	codes = append(codes, models.SwiftCode{SwiftCode: "GQ00DEFFXXX", BankName: "GRAPH BANK", TownName: "FRANKFURT", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true})
+
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.InsertSwiftCodes(context.Background(), codes))
+	require.NoError(t, repo.RefreshInstitutions(context.Background(), nil))
+	return repo
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func post(t *testing.T, h http.Handler, query string, variables map[string]any) (int, response) {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var resp response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+	return rec.Code, resp
+}
+
+func newHandler(repo repository.Repository) *graphqlapi.Handler {
+	return graphqlapi.New(service.New(repo, nil), graphqlapi.DefaultConfig())
+}
+
+func TestBatching(t *testing.T) {
+	memory := seed(t)
+
+	t.Run("Country, headquarters and branches take one query", func(t *testing.T) {
+		repo := &countingRepo{Repository: memory, calls: map[string]int{}}
+		code, resp := post(t, newHandler(repo), `{
+			country(iso2: "pl") {
+				countryName
+				headquarters {
+					swiftCode
+					branchCount
+					branches(limit: 1, offset: 1) { swiftCode townName headquarter { swiftCode } }
+				}
+			}
+		}`, nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"country":{"countryName":"POLAND","headquarters":[
+			{"swiftCode":"GQ00PLPWXXX","branchCount":2,"branches":[{"swiftCode":"GQ00PLPW002","townName":"GDANSK","headquarter":{"swiftCode":"GQ00PLPWXXX"}}]},
+			{"swiftCode":"GQ01PLPWXXX","branchCount":2,"branches":[{"swiftCode":"GQ01PLPW002","townName":"GDANSK","headquarter":{"swiftCode":"GQ01PLPWXXX"}}]},
+			{"swiftCode":"GQ02PLPWXXX","branchCount":2,"branches":[{"swiftCode":"GQ02PLPW002","townName":"GDANSK","headquarter":{"swiftCode":"GQ02PLPWXXX"}}]}
+		]}}`, string(resp.Data))
+		assert.Equal(t, map[string]int{"GetSwiftCodesByCountry": 1}, repo.calls)
+	})
+
+	t.Run("Codes at the same depth are fetched together", func(t *testing.T) {
+		repo := &countingRepo{Repository: memory, calls: map[string]int{}}
+		code, resp := post(t, newHandler(repo), `{
+			swiftCodes(codes: ["GQ00PLPW001", "gq01plpw001", "GQ02PLPW", "NONEXISTXXX"]) {
+				swiftCode
+				headquarter { swiftCode branches(town: "krakow") { swiftCode } }
+				branches { swiftCode }
+			}
+		}`, nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"swiftCodes":[
+			{"swiftCode":"GQ00PLPW001","headquarter":{"swiftCode":"GQ00PLPWXXX","branches":[{"swiftCode":"GQ00PLPW001"}]},"branches":[]},
+			{"swiftCode":"GQ01PLPW001","headquarter":{"swiftCode":"GQ01PLPWXXX","branches":[{"swiftCode":"GQ01PLPW001"}]},"branches":[]},
+			{"swiftCode":"GQ02PLPWXXX","headquarter":null,"branches":[{"swiftCode":"GQ02PLPW001"},{"swiftCode":"GQ02PLPW002"}]},
+			null
+		]}`, string(resp.Data))
+		assert.Equal(t, map[string]int{"GetSwiftCodesByCodes": 2, "GetBranchesByHeadquarters": 1}, repo.calls)
+	})
+
+	t.Run("Institution headquarters are fetched together", func(t *testing.T) {
+		repo := &countingRepo{Repository: memory, calls: map[string]int{}}
+		code, resp := post(t, newHandler(repo), `{
+			institutions(query: "GQ", limit: 10) {
+				total
+				institutions { institutionCode name headquarters { swiftCode countryISO2 } }
+			}
+		}`, nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"institutions":{"total":3,"institutions":[
+			{"institutionCode":"GQ00","name":"GRAPH BANK","headquarters":[{"swiftCode":"GQ00DEFFXXX","countryISO2":"DE"},{"swiftCode":"GQ00PLPWXXX","countryISO2":"PL"}]},
+			{"institutionCode":"GQ01","name":"GRAPH BANK","headquarters":[{"swiftCode":"GQ01PLPWXXX","countryISO2":"PL"}]},
+			{"institutionCode":"GQ02","name":"GRAPH BANK","headquarters":[{"swiftCode":"GQ02PLPWXXX","countryISO2":"PL"}]}
+		]}}`, string(resp.Data))
+		assert.Equal(t, map[string]int{"SearchInstitutions": 1, "GetSwiftCodesByCodes": 1}, repo.calls)
+	})
+}
+
+func TestQueries(t *testing.T) {
+	h := newHandler(seed(t))
+
+	t.Run("Unknown values are null", func(t *testing.T) {
+		code, resp := post(t, h, `{ swiftCode(code: "NONEXISTXXX") { swiftCode } country(iso2: "FR") { countryName } institution(code: "NONE") { name } }`, nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"swiftCode":null,"country":null,"institution":null}`, string(resp.Data))
+	})
+
+	t.Run("Variables and nested country", func(t *testing.T) {
+		code, resp := post(t, h, `query Lookup($code: String!) {
+			swiftCode(code: $code) { bankName address isHeadquarter country { countryISO2 swiftCodes { swiftCode } } }
+		}`, map[string]any{"code": "GQ00DEFFXXX"})
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"swiftCode":{"bankName":"GRAPH BANK","address":null,"isHeadquarter":true,
+			"country":{"countryISO2":"DE","swiftCodes":[{"swiftCode":"GQ00DEFFXXX"}]}}}`, string(resp.Data))
+	})
+
+	t.Run("Invalid arguments are field errors", func(t *testing.T) {
+		code, resp := post(t, h, `{ a: swiftCode(code: "SHORT") { swiftCode } b: country(iso2: "POL") { countryName } c: swiftCode(code: "GQ00DEFFXXX") { swiftCode } }`, nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{"a":null,"b":null,"c":{"swiftCode":"GQ00DEFFXXX"}}`, string(resp.Data))
+		require.Len(t, resp.Errors, 2)
+		for _, e := range resp.Errors {
+			assert.Equal(t, "BAD_USER_INPUT", e.Extensions["code"])
+		}
+	})
+
+	t.Run("Institution page bounds", func(t *testing.T) {
+		_, resp := post(t, h, `{ institutions(limit: 1000) { total } }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "BAD_USER_INPUT", resp.Errors[0].Extensions["code"])
+		assert.Equal(t, "null", string(resp.Data))
+	})
+
+	t.Run("Null page arguments take the defaults", func(t *testing.T) {
+		code, resp := post(t, h, `{ institutions(limit: null, offset: null) { limit offset } }`, nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"institutions":{"limit":20,"offset":0}}`, string(resp.Data))
+
+		code, resp = post(t, h, `query($limit: Int, $offset: Int) { institutions(limit: $limit, offset: $offset) { limit offset } }`,
+			map[string]any{"limit": nil, "offset": nil})
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"institutions":{"limit":20,"offset":0}}`, string(resp.Data))
+	})
+
+	t.Run("Repository errors are not exposed", func(t *testing.T) {
+		failing := &countingRepo{Repository: seed(t), calls: map[string]int{}, err: errors.New("connection refused")}
+		_, resp := post(t, newHandler(failing), `{ swiftCode(code: "GQ00DEFFXXX") { swiftCode } }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "Error retrieving SWIFT code", resp.Errors[0].Message)
+		assert.Equal(t, "INTERNAL_SERVER_ERROR", resp.Errors[0].Extensions["code"])
+	})
+}
+
+func TestLimits(t *testing.T) {
+	h := graphqlapi.New(service.New(seed(t), nil), graphqlapi.Config{MaxDepth: 3, MaxComplexity: 100})
+
+	code, resp := post(t, h, `{ country(iso2: "PL") { headquarters { branches { swiftCode } } } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Nil(t, resp.Data)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "Query depth 4 exceeds the maximum of 3.", resp.Errors[0].Message)
+
+	// 20 headquarters assumed, each with 50 branches.
+	code, resp = post(t, h, `{ country(iso2: "PL") { headquarters { branches(limit: 50) } } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.NotEmpty(t, resp.Errors)
+
+	code, resp = post(t, h, `{ country(iso2: "PL") { headquarters { swiftCode } } }`, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, resp.Errors)
+}
+
+func TestHTTP(t *testing.T) {
+	h := newHandler(seed(t))
+
+	t.Run("GET", func(t *testing.T) {
+		params := url.Values{
+			"query":     {`query ($c: String!) { swiftCode(code: $c) { townName } }`},
+			"variables": {`{"c": "GQ00DEFFXXX"}`},
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?"+params.Encode(), nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data":{"swiftCode":{"townName":"FRANKFURT"}}}`, rec.Body.String())
+	})
+
+	t.Run("Bad requests", func(t *testing.T) {
+		for name, req := range map[string]*http.Request{
+			"no query":         httptest.NewRequest(http.MethodGet, "/graphql", nil),
+			"bad variables":    httptest.NewRequest(http.MethodGet, "/graphql?query=%7Bx%7D&variables=%5B", nil),
+			"bad body":         httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{")),
+			"syntax error":     httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ swiftCode("}`)),
+			"mutation":         httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "mutation { swiftCode }"}`)),
+			"too large":        httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "`+strings.Repeat(" ", 1<<20)+`"}`)),
+			"unknown field":    httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ codes }"}`)),
+			"missing variable": httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "query ($c: String!) { swiftCode(code: $c) { swiftCode } }"}`)),
+		} {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.GreaterOrEqual(t, rec.Code, 400, name)
+			var resp response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), name)
+			assert.Nil(t, resp.Data, name)
+			assert.NotEmpty(t, resp.Errors, name)
+		}
+	})
+}
+
+func TestSchema(t *testing.T) {
+	sdl := newHandler(repository.NewMemoryRepository()).Schema()
+	for _, want := range []string{
+		"type Query {",
+		"swiftCode(code: String!): SwiftCode",
+		"country(iso2: String!): Country",
+		"branches(town: String, limit: Int, offset: Int = 0): [SwiftCode!]!",
+		"institutions(query: String, country: String, limit: Int = 20, offset: Int = 0): InstitutionPage!",
+	} {
+		assert.Contains(t, sdl, want)
+	}
+}
@@ -0,0 +1,94 @@
+package graphqlapi
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"swift-api/pkg/graphql"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+)
+
+// loaders batch the lookups of one query. Codes and branches are fetched
+// for every parent at the same depth with one query each; a country's codes
+// also fill the branches of its headquarters, so a country, its
+// headquarters and their branches take a single query.
+type loaders struct {
+	codes     *graphql.Loader[string, *models.SwiftCode]
+	branches  *graphql.Loader[string, []models.SwiftCode]
+	countries *graphql.Loader[string, []models.SwiftCode]
+}
+
+type loadersKey struct{}
+
+func newLoaders(repo repository.Repository) *loaders {
+	l := &loaders{}
+	l.codes = graphql.NewLoader(func(ctx context.Context, keys []string) (map[string]*models.SwiftCode, error) {
+		codes, err := repo.GetSwiftCodesByCodes(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[string]*models.SwiftCode, len(codes))
+		for i := range codes {
+			found[codes[i].SwiftCode] = &codes[i]
+		}
+		return found, nil
+	})
+	l.branches = graphql.NewLoader(func(ctx context.Context, keys []string) (map[string][]models.SwiftCode, error) {
+		branches, err := repo.GetBranchesByHeadquarters(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		byHeadquarter := make(map[string][]models.SwiftCode, len(keys))
+		for _, b := range branches {
+			byHeadquarter[*b.HeadquarterSWIFTCode] = append(byHeadquarter[*b.HeadquarterSWIFTCode], b)
+		}
+		return byHeadquarter, nil
+	})
+	// The repository reads one country at a time; a query rarely names more.
+	l.countries = graphql.NewLoader(func(ctx context.Context, keys []string) (map[string][]models.SwiftCode, error) {
+		byCountry := make(map[string][]models.SwiftCode, len(keys))
+		for _, iso2 := range keys {
+			codes, _, err := repo.GetSwiftCodesByCountry(ctx, iso2)
+			if err != nil {
+				return nil, err
+			}
+			l.primeCountry(iso2, codes)
+			byCountry[iso2] = codes
+		}
+		return byCountry, nil
+	})
+	return l
+}
+
+// primeCountry sorts the codes of a country and stores each code and the
+// branches of each headquarter among them. A branch shares the first eight
+// characters, country included, with its headquarter, so none is missing.
+func (l *loaders) primeCountry(iso2 string, codes []models.SwiftCode) {
+	slices.SortFunc(codes, func(a, b models.SwiftCode) int { return strings.Compare(a.SwiftCode, b.SwiftCode) })
+	branches := map[string][]models.SwiftCode{}
+	for i, code := range codes {
+		l.codes.Prime(code.SwiftCode, &codes[i])
+		if code.IsHeadquarter {
+			branches[code.SwiftCode] = nil
+		}
+	}
+	for _, code := range codes {
+		if hq := code.HeadquarterSWIFTCode; hq != nil {
+			if _, ok := branches[*hq]; ok {
+				branches[*hq] = append(branches[*hq], code)
+			}
+		}
+	}
+	for hq, b := range branches {
+		l.branches.Prime(hq, b)
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
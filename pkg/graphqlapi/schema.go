@@ -0,0 +1,439 @@
+package graphqlapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"swift-api/pkg/graphql"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"swift-api/pkg/service"
+)
+
+// defaultListSize is the number of items a list without a limit is expected
+// to hold when estimating query complexity.
+const defaultListSize = 20
+
+type country struct {
+	iso2, name string
+}
+
+type institutionPage struct {
+	total, limit, offset int
+	institutions         []models.Institution
+}
+
+func nonNull(t graphql.Type) graphql.Type {
+	return &graphql.NonNull{OfType: t}
+}
+
+func listOf(t graphql.Type) graphql.Type {
+	return &graphql.List{OfType: t}
+}
+
+func listSize(args map[string]any) int {
+	if limit, ok := args["limit"].(int); ok && limit > 0 {
+		return limit
+	}
+	return defaultListSize
+}
+
+// toError turns a service error into a GraphQL error with its client message
+// and a code in the extensions.
+func toError(err error) error {
+	code, message := "INTERNAL_SERVER_ERROR", "Internal server error"
+	var e *service.Error
+	if errors.As(err, &e) {
+		message = e.Message
+		switch {
+		case errors.Is(e.Kind, service.ErrInvalid):
+			code = "BAD_USER_INPUT"
+		case errors.Is(e.Kind, service.ErrNotFound):
+			code = "NOT_FOUND"
+		}
+	}
+	return &graphql.Error{Message: message, Extensions: map[string]any{"code": code}}
+}
+
+func badInput(message string) error {
+	return &graphql.Error{Message: message, Extensions: map[string]any{"code": "BAD_USER_INPUT"}}
+}
+
+// loadError hides the database error behind the message REST answers with.
+// The repository has logged it.
+func loadError(message string) error {
+	return &graphql.Error{Message: message, Extensions: map[string]any{"code": "INTERNAL_SERVER_ERROR"}}
+}
+
+func sourceCode(source any) models.SwiftCode {
+	if code, ok := source.(*models.SwiftCode); ok {
+		return *code
+	}
+	return source.(models.SwiftCode)
+}
+
+func sourceInstitution(source any) models.Institution {
+	if institution, ok := source.(*models.Institution); ok {
+		return *institution
+	}
+	return source.(models.Institution)
+}
+
+func codeField(name, description string, typ graphql.Type, get func(models.SwiftCode) any) *graphql.Field {
+	return &graphql.Field{Name: name, Description: description, Type: typ, Resolve: func(p graphql.ResolveParams) (any, error) {
+		return get(sourceCode(p.Source)), nil
+	}}
+}
+
+// filterBranches applies the town, offset and limit arguments the way
+// repository.BranchQuery does.
+func filterBranches(branches []models.SwiftCode, args map[string]any) ([]models.SwiftCode, error) {
+	if town, ok := args["town"].(string); ok && town != "" {
+		var matching []models.SwiftCode
+		for _, b := range branches {
+			if strings.EqualFold(b.TownName, town) {
+				matching = append(matching, b)
+			}
+		}
+		branches = matching
+	}
+	if offset, ok := args["offset"].(int); ok {
+		if offset < 0 {
+			return nil, badInput("offset must not be negative")
+		}
+		branches = branches[min(offset, len(branches)):]
+	}
+	if limit, ok := args["limit"].(int); ok {
+		if limit < 0 {
+			return nil, badInput("limit must not be negative")
+		}
+		if limit > 0 {
+			branches = branches[:min(limit, len(branches))]
+		}
+	}
+	return branches, nil
+}
+
+// newSchema builds the query type. Lookups by code go through the loaders,
+// so a code's headquarter or branches are fetched once for all the codes at
+// the same depth.
+func newSchema(svc *service.Service) (*graphql.Schema, error) {
+	swiftCode := &graphql.Object{Name: "SwiftCode", Description: "A headquarter or a branch of a bank."}
+	countryType := &graphql.Object{Name: "Country"}
+	institution := &graphql.Object{Name: "Institution", Description: "The headquarters whose SWIFT codes share the first four characters."}
+	pageType := &graphql.Object{Name: "InstitutionPage"}
+
+	branchesOf := func(p graphql.ResolveParams) (func() ([]models.SwiftCode, error), bool) {
+		code := sourceCode(p.Source)
+		if !code.IsHeadquarter {
+			return nil, false
+		}
+		return loadersFrom(p.Context).branches.Load(p.Context, code.SwiftCode), true
+	}
+	townArg := &graphql.Arg{Name: "town", Type: graphql.String, Description: "Only branches in this town, ignoring case."}
+
+	swiftCode.Fields = []*graphql.Field{
+		codeField("swiftCode", "", nonNull(graphql.String), func(c models.SwiftCode) any { return c.SwiftCode }),
+		codeField("bankName", "", nonNull(graphql.String), func(c models.SwiftCode) any { return c.BankName }),
+		codeField("address", "", graphql.String, func(c models.SwiftCode) any { return c.Address }),
+		codeField("townName", "", nonNull(graphql.String), func(c models.SwiftCode) any { return c.TownName }),
+		codeField("countryISO2", "", nonNull(graphql.String), func(c models.SwiftCode) any { return c.CountryISO2 }),
+		codeField("countryName", "", nonNull(graphql.String), func(c models.SwiftCode) any { return c.CountryName }),
+		codeField("timezone", "", nonNull(graphql.String), func(c models.SwiftCode) any { return c.Timezone }),
+		codeField("isHeadquarter", "", nonNull(graphql.Boolean), func(c models.SwiftCode) any { return c.IsHeadquarter }),
+		codeField("headquarterSwiftCode", "", graphql.String, func(c models.SwiftCode) any { return c.HeadquarterSWIFTCode }),
+		{
+			Name:        "headquarter",
+			Description: "The headquarter of a branch; null for a headquarter.",
+			Type:        swiftCode,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				code := sourceCode(p.Source)
+				if code.HeadquarterSWIFTCode == nil {
+					return nil, nil
+				}
+				load := loadersFrom(p.Context).codes.Load(p.Context, *code.HeadquarterSWIFTCode)
+				return graphql.Thunk(func() (any, error) {
+					hq, err := load()
+					if err != nil {
+						return nil, loadError("Error retrieving SWIFT code")
+					}
+					return hq, nil
+				}), nil
+			},
+		},
+		{
+			Name:        "branches",
+			Description: "The branches of a headquarter ordered by SWIFT code; empty for a branch.",
+			Type:        nonNull(listOf(nonNull(swiftCode))),
+			Args: []*graphql.Arg{
+				townArg,
+				{Name: "limit", Type: graphql.Int, Description: "At most this many branches; 0 or omitted returns all."},
+				{Name: "offset", Type: graphql.Int, Default: 0},
+			},
+			ListSize: listSize,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				load, ok := branchesOf(p)
+				if !ok {
+					return []models.SwiftCode{}, nil
+				}
+				return graphql.Thunk(func() (any, error) {
+					branches, err := load()
+					if err != nil {
+						return nil, loadError("Error retrieving branches")
+					}
+					return filterBranches(branches, p.Args)
+				}), nil
+			},
+		},
+		{
+			Name:        "branchCount",
+			Description: "How many branches a headquarter has; 0 for a branch.",
+			Type:        nonNull(graphql.Int),
+			Args:        []*graphql.Arg{townArg},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				load, ok := branchesOf(p)
+				if !ok {
+					return 0, nil
+				}
+				return graphql.Thunk(func() (any, error) {
+					branches, err := load()
+					if err != nil {
+						return nil, loadError("Error retrieving branches")
+					}
+					branches, err = filterBranches(branches, p.Args)
+					return len(branches), err
+				}), nil
+			},
+		},
+		{
+			Name: "country",
+			Type: nonNull(countryType),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				code := sourceCode(p.Source)
+				return country{iso2: code.CountryISO2, name: code.CountryName}, nil
+			},
+		},
+	}
+
+	countryCodes := func(p graphql.ResolveParams, keep func(models.SwiftCode) bool) (any, error) {
+		c := p.Source.(country)
+		load := loadersFrom(p.Context).countries.Load(p.Context, c.iso2)
+		return graphql.Thunk(func() (any, error) {
+			codes, err := load()
+			if err != nil {
+				return nil, loadError("Error retrieving SWIFT codes")
+			}
+			var kept []models.SwiftCode
+			for _, code := range codes {
+				if keep(code) {
+					kept = append(kept, code)
+				}
+			}
+			return kept, nil
+		}), nil
+	}
+	countryType.Fields = []*graphql.Field{
+		{Name: "countryISO2", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(country).iso2, nil
+		}},
+		{Name: "countryName", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(country).name, nil
+		}},
+		{
+			Name:        "swiftCodes",
+			Description: "Every code of the country ordered by SWIFT code.",
+			Type:        nonNull(listOf(nonNull(swiftCode))),
+			ListSize:    listSize,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return countryCodes(p, func(models.SwiftCode) bool { return true })
+			},
+		},
+		{
+			Name:        "headquarters",
+			Description: "The headquarters of the country ordered by SWIFT code. Their branches come with them, without another query.",
+			Type:        nonNull(listOf(nonNull(swiftCode))),
+			ListSize:    listSize,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return countryCodes(p, func(c models.SwiftCode) bool { return c.IsHeadquarter })
+			},
+		},
+	}
+
+	institution.Fields = []*graphql.Field{
+		{Name: "institutionCode", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+			return sourceInstitution(p.Source).Code, nil
+		}},
+		{Name: "name", Type: nonNull(graphql.String), Description: "The bank name most of its headquarters share.", Resolve: func(p graphql.ResolveParams) (any, error) {
+			return sourceInstitution(p.Source).Name(), nil
+		}},
+		{
+			Name:        "headquarters",
+			Description: "The headquarters ordered by country and SWIFT code.",
+			Type:        nonNull(listOf(nonNull(swiftCode))),
+			ListSize:    listSize,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				codes := loadersFrom(p.Context).codes
+				var loads []func() (*models.SwiftCode, error)
+				for _, hq := range sourceInstitution(p.Source).Headquarters {
+					loads = append(loads, codes.Load(p.Context, hq.SwiftCode))
+				}
+				return graphql.Thunk(func() (any, error) {
+					var headquarters []*models.SwiftCode
+					for _, load := range loads {
+						hq, err := load()
+						if err != nil {
+							return nil, loadError("Error retrieving SWIFT codes")
+						}
+						// The institution view is refreshed after writes, so
+						// a code deleted since is skipped.
+						if hq != nil {
+							headquarters = append(headquarters, hq)
+						}
+					}
+					return headquarters, nil
+				}), nil
+			},
+		},
+	}
+
+	pageType.Fields = []*graphql.Field{
+		{Name: "total", Type: nonNull(graphql.Int), Description: "How many institutions match in all.", Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(institutionPage).total, nil
+		}},
+		{Name: "limit", Type: nonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(institutionPage).limit, nil
+		}},
+		{Name: "offset", Type: nonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(institutionPage).offset, nil
+		}},
+		{Name: "institutions", Type: nonNull(listOf(nonNull(institution))), Resolve: func(p graphql.ResolveParams) (any, error) {
+			return p.Source.(institutionPage).institutions, nil
+		}},
+	}
+
+	query := &graphql.Object{Name: "Query"}
+	query.Fields = []*graphql.Field{
+		{
+			Name:        "swiftCode",
+			Description: "Looks up a BIC8 or BIC11, ignoring case; null when unknown.",
+			Type:        swiftCode,
+			Args:        []*graphql.Arg{{Name: "code", Type: nonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				code, err := service.NormalizeBIC(p.Args["code"].(string))
+				if err != nil {
+					return nil, badInput(err.Error())
+				}
+				load := loadersFrom(p.Context).codes.Load(p.Context, code)
+				return graphql.Thunk(func() (any, error) {
+					found, err := load()
+					if err != nil {
+						return nil, loadError("Error retrieving SWIFT code")
+					}
+					return found, nil
+				}), nil
+			},
+		},
+		{
+			Name:        "swiftCodes",
+			Description: fmt.Sprintf("Looks up to %d codes at once, in order; null for each unknown one.", service.MaxBatchLookup),
+			Type:        nonNull(listOf(swiftCode)),
+			Args:        []*graphql.Arg{{Name: "codes", Type: nonNull(listOf(nonNull(graphql.String)))}},
+			ListSize: func(args map[string]any) int {
+				codes, _ := args["codes"].([]any)
+				return len(codes)
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				queries := p.Args["codes"].([]any)
+				if len(queries) > service.MaxBatchLookup {
+					return nil, badInput(fmt.Sprintf("codes must not have more than %d entries", service.MaxBatchLookup))
+				}
+				codes := loadersFrom(p.Context).codes
+				results := make([]any, len(queries))
+				for i, query := range queries {
+					code, err := service.NormalizeBIC(query.(string))
+					if err != nil {
+						return nil, badInput(fmt.Sprintf("%q: %v", query, err))
+					}
+					load := codes.Load(p.Context, code)
+					results[i] = graphql.Thunk(func() (any, error) {
+						found, err := load()
+						if err != nil {
+							return nil, loadError("Error retrieving SWIFT codes")
+						}
+						return found, nil
+					})
+				}
+				return results, nil
+			},
+		},
+		{
+			Name:        "country",
+			Description: "A country by ISO2 code, ignoring case; null when it has no codes.",
+			Type:        countryType,
+			Args:        []*graphql.Arg{{Name: "iso2", Type: nonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				iso2 := strings.ToUpper(p.Args["iso2"].(string))
+				codes, name, err := svc.ListByCountry(p.Context, iso2)
+				if errors.Is(err, service.ErrNotFound) {
+					return nil, nil
+				}
+				if err != nil {
+					return nil, toError(err)
+				}
+				l := loadersFrom(p.Context)
+				l.primeCountry(iso2, codes)
+				l.countries.Prime(iso2, codes)
+				return country{iso2: iso2, name: name}, nil
+			},
+		},
+		{
+			Name:        "institution",
+			Description: "An institution by code, ignoring case; null when unknown.",
+			Type:        institution,
+			Args:        []*graphql.Arg{{Name: "code", Type: nonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				found, err := svc.GetInstitution(p.Context, p.Args["code"].(string))
+				if errors.Is(err, service.ErrNotFound) {
+					return nil, nil
+				}
+				if err != nil {
+					return nil, toError(err)
+				}
+				return found, nil
+			},
+		},
+		{
+			Name:        "institutions",
+			Description: "Searches institutions by the start of their code or part of a bank name.",
+			Type:        nonNull(pageType),
+			Args: []*graphql.Arg{
+				{Name: "query", Type: graphql.String},
+				{Name: "country", Type: graphql.String, Description: "Only institutions with a headquarter in this country."},
+				{Name: "limit", Type: graphql.Int, Default: service.DefaultInstitutionLimit},
+				{Name: "offset", Type: graphql.Int, Default: 0},
+			},
+			ListSize: listSize,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				// An explicit null reaches the resolver as nil, not the default.
+				q := repository.InstitutionQuery{Limit: service.DefaultInstitutionLimit}
+				if limit, ok := p.Args["limit"].(int); ok {
+					q.Limit = limit
+				}
+				q.Offset, _ = p.Args["offset"].(int)
+				if q.Limit == 0 {
+					return nil, badInput(fmt.Sprintf("limit must be an integer between 1 and %d", service.MaxInstitutionLimit))
+				}
+				q.Text, _ = p.Args["query"].(string)
+				if country, ok := p.Args["country"].(string); ok {
+					q.CountryISO2 = strings.ToUpper(country)
+				}
+				institutions, total, err := svc.SearchInstitutions(p.Context, q)
+				if err != nil {
+					return nil, toError(err)
+				}
+				return institutionPage{total: total, limit: q.Limit, offset: q.Offset, institutions: institutions}, nil
+			},
+		},
+	}
+	return graphql.NewSchema(query)
+}
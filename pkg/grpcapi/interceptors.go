@@ -0,0 +1,174 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strconv"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/grpcapi/swiftv1"
+	"swift-api/pkg/ratelimit"
+	"swift-api/pkg/repository"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// writes are the methods that change data. They need the editor role and
+// read from the primary throughout.
+var writes = map[string]bool{
+	swiftv1.SwiftCodes_CreateSwiftCode_FullMethodName: true,
+	swiftv1.SwiftCodes_UpdateSwiftCode_FullMethodName: true,
+	swiftv1.SwiftCodes_DeleteSwiftCode_FullMethodName: true,
+}
+
+// Limits are the rate limiters applied to gRPC calls, shared with the REST
+// API so a client has one budget across both. A nil limiter turns that
+// class off.
+type Limits struct {
+	// IP is checked before authentication, by peer address.
+	IP    ratelimit.Limiter
+	Read  ratelimit.Limiter
+	Write ratelimit.Limiter
+	// Proxies may report the caller's address in x-forwarded-for.
+	Proxies ratelimit.Proxies
+}
+
+func logCalls(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		if code == codes.Internal || code == codes.Unknown {
+			level = slog.LevelError
+		}
+		var remoteAddr string
+		if p, ok := peer.FromContext(ctx); ok {
+			remoteAddr = p.Addr.String()
+		}
+		logger.LogAttrs(ctx, level, "gRPC call",
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_addr", remoteAddr),
+		)
+		return resp, err
+	}
+}
+
+// withTimeout gives every call a deadline of at most timeout. The handlers
+// pass the context down to the repository, so an expired deadline cancels
+// the query and the call fails with DeadlineExceeded.
+func withTimeout(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authorize resolves the caller from the request metadata and checks the
+// role the method needs, answering like the REST middleware does.
+func authorize(authn auth.Authenticator, readRole auth.Role) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		p, err := authn.Authenticate(credentials(ctx))
+		switch {
+		case err == nil:
+			ctx = auth.NewContext(ctx, p)
+		case errors.Is(err, auth.ErrNoCredentials):
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, "Invalid credentials")
+		default:
+			return nil, status.Error(codes.Internal, "Error verifying credentials")
+		}
+
+		role := readRole
+		if writes[info.FullMethod] {
+			role = auth.RoleEditor
+		}
+		if role != "" {
+			if p == nil {
+				return nil, status.Error(codes.Unauthenticated, "Authentication required")
+			}
+			if !p.HasRole(role) {
+				return nil, status.Error(codes.PermissionDenied, "Insufficient role, "+string(role)+" required")
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// credentials reads the caller's credentials from the metadata keys named
+// like the HTTP headers.
+func credentials(ctx context.Context) auth.Credentials {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return auth.Credentials{APIKey: first("x-api-key"), Authorization: first("authorization")}
+}
+
+// rateLimit throttles calls with limiter, picked by whether the method
+// writes, answering ResourceExhausted with a retry-after header like the
+// REST middleware's 429.
+func rateLimit(limiter func(method string) ratelimit.Limiter, proxies ratelimit.Proxies, logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		l := limiter(info.FullMethod)
+		if l == nil {
+			return handler(ctx, req)
+		}
+		res, err := l.Allow(ctx, clientKey(ctx, proxies))
+		if err != nil {
+			logger.WarnContext(ctx, "Rate limiter unavailable, allowing call", "error", err)
+			return handler(ctx, req)
+		}
+		if !res.Allowed {
+			retryAfter := int(math.Ceil(res.RetryAfter.Seconds()))
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+			return nil, status.Error(codes.ResourceExhausted, "Rate limit exceeded")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// clientKey buckets calls by API key or token subject once authenticated,
+// and by peer address, read through proxies, before.
+func clientKey(ctx context.Context, proxies ratelimit.Proxies) string {
+	if p, ok := auth.FromContext(ctx); ok && p != nil {
+		if p.KeyID != "" {
+			return "key:" + p.KeyID
+		}
+		return "sub:" + p.Subject
+	}
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return "ip:" + proxies.ClientIP(addr, md.Get("x-forwarded-for"))
+}
+
+// readYourWrites pins writes to the primary, as the REST middleware does for
+// unsafe methods.
+func readYourWrites() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = repository.WithReadYourWrites(ctx)
+		if writes[info.FullMethod] {
+			ctx = repository.PinPrimary(ctx)
+		}
+		return handler(ctx, req)
+	}
+}
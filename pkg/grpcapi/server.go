@@ -0,0 +1,117 @@
+// Package grpcapi serves the SWIFT code operations over gRPC on a port of
+// their own, through the same service layer as the REST API.
+package grpcapi
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=swift-api --go-grpc_out=../.. --go-grpc_opt=module=swift-api swift/v1/swift.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/grpcapi/swiftv1"
+	"swift-api/pkg/ratelimit"
+	"swift-api/pkg/service"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type Config struct {
+	Enabled bool
+	Addr    string
+	// Timeout bounds every call. A shorter client deadline still applies.
+	Timeout time.Duration
+	// ShutdownTimeout bounds the graceful stop. It is not a setting of its
+	// own; the service passes the HTTP server's.
+	ShutdownTimeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Addr:            ":9090",
+		Timeout:         30 * time.Second,
+		ShutdownTimeout: 20 * time.Second,
+	}
+}
+
+// Server is a gRPC server for the SwiftCodes service with server reflection.
+type Server struct {
+	cfg    Config
+	srv    *grpc.Server
+	logger *slog.Logger
+}
+
+// New builds the server. Callers authenticate with the same credentials as
+// over HTTP: reads need readRole, which may be empty for anonymous access,
+// and writes need the editor role. Interceptors passed in opts, such as
+// metrics and tracing, run before the server's own.
+func New(cfg Config, svc *service.Service, authn auth.Authenticator, readRole auth.Role, limits Limits, logger *slog.Logger, opts ...grpc.ServerOption) *Server {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		logCalls(logger),
+		withTimeout(cfg.Timeout),
+		rateLimit(func(string) ratelimit.Limiter { return limits.IP }, limits.Proxies, logger),
+		authorize(authn, readRole),
+		rateLimit(func(method string) ratelimit.Limiter {
+			if writes[method] {
+				return limits.Write
+			}
+			return limits.Read
+		}, limits.Proxies, logger),
+		readYourWrites(),
+	))
+
+	srv := grpc.NewServer(opts...)
+	swiftv1.RegisterSwiftCodesServer(srv, &swiftCodes{svc: svc})
+	reflection.Register(srv)
+	return &Server{cfg: cfg, srv: srv, logger: logger}
+}
+
+// Run listens on cfg.Addr and serves until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts connections on ln until ctx is cancelled, then waits for
+// in-flight calls. Calls still running after ShutdownTimeout are cancelled.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		s.logger.Info("gRPC server running", "addr", ln.Addr().String())
+		serveErr <- s.srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("Shutting down gRPC server", "timeout", s.cfg.ShutdownTimeout.String())
+	stopped := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(s.cfg.ShutdownTimeout):
+		s.srv.Stop()
+		return errors.New("could not drain gRPC calls")
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("gRPC server: %w", err)
+	}
+	s.logger.Info("gRPC server stopped")
+	return nil
+}
@@ -0,0 +1,316 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/grpcapi"
+	"swift-api/pkg/grpcapi/swiftv1"
+	"swift-api/pkg/models"
+	"swift-api/pkg/ratelimit"
+	"swift-api/pkg/repository"
+	"swift-api/pkg/service"
+)
+
+type stubAuthenticator map[string]*auth.Principal
+
+func (s stubAuthenticator) Authenticate(c auth.Credentials) (*auth.Principal, error) {
+	key := c.APIKey
+	if key == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	p, ok := s[key]
+	if !ok {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return p, nil
+}
+
+var authn = stubAuthenticator{
+	"reader-key": {Subject: "r", Roles: []auth.Role{auth.RoleReader}},
+	"editor-key": {Subject: "e", Roles: []auth.Role{auth.RoleEditor}},
+}
+
+// slowRepo answers lookups only once the call's context is done.
+type slowRepo struct {
+	repository.Repository
+}
+
+func (slowRepo) GetSwiftCodeWithBranches(ctx context.Context, _ string) (*models.SwiftCode, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func seed(t *testing.T) repository.Repository {
+	hqCode := "GRPCPLPWXXX"
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.InsertSwiftCodes(context.Background(), []models.SwiftCode{
+		{SwiftCode: hqCode, BankName: "GRPC BANK", TownName: "WARSZAWA", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+		{SwiftCode: "GRPCPLPW001", BankName: "GRPC BANK", TownName: "KRAKOW", CountryISO2: "PL", CountryName: "POLAND", HeadquarterSWIFTCode: &hqCode},
+	}))
+	require.NoError(t, repo.RefreshInstitutions(context.Background(), nil))
+	return repo
+}
+
+// dial serves repo over an in-memory listener and returns a connected client.
+func dial(t *testing.T, cfg grpcapi.Config, repo repository.Repository, readRole auth.Role, limits grpcapi.Limits) *grpc.ClientConn {
+	ln := bufconn.Listen(1 << 20)
+	srv := grpcapi.New(cfg, service.New(repo, nil), authn, readRole, limits, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	return conn
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+func TestSwiftCodes(t *testing.T) {
+	client := swiftv1.NewSwiftCodesClient(dial(t, grpcapi.DefaultConfig(), seed(t), "", grpcapi.Limits{}))
+	ctx := context.Background()
+
+	t.Run("Headquarter with branches", func(t *testing.T) {
+		code, err := client.GetSwiftCode(ctx, &swiftv1.GetSwiftCodeRequest{SwiftCode: "GRPCPLPWXXX"})
+		require.NoError(t, err)
+		assert.Equal(t, "GRPCPLPWXXX", code.SwiftCode)
+		assert.True(t, code.IsHeadquarter)
+		require.Len(t, code.Branches, 1)
+		assert.Equal(t, "GRPCPLPW001", code.Branches[0].SwiftCode)
+		assert.Equal(t, "GRPCPLPWXXX", code.Branches[0].HeadquarterSwiftCode)
+
+		code, err = client.GetSwiftCode(ctx, &swiftv1.GetSwiftCodeRequest{SwiftCode: "GRPCPLPWXXX", SkipBranches: true})
+		require.NoError(t, err)
+		assert.Empty(t, code.Branches)
+	})
+
+	t.Run("Country", func(t *testing.T) {
+		resp, err := client.ListSwiftCodesByCountry(ctx, &swiftv1.ListSwiftCodesByCountryRequest{CountryIso2: "pl"})
+		require.NoError(t, err)
+		assert.Equal(t, "PL", resp.CountryIso2)
+		assert.Equal(t, "POLAND", resp.CountryName)
+		assert.Len(t, resp.SwiftCodes, 2)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		resp, err := client.BatchGetSwiftCodes(ctx, &swiftv1.BatchGetSwiftCodesRequest{SwiftCodes: []string{" grpcplpw001", "MISSINGGXXX", "BAD"}})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, resp.Found)
+		assert.EqualValues(t, 2, resp.NotFound)
+		require.Len(t, resp.Results, 3)
+		assert.True(t, resp.Results[0].Found)
+		assert.Equal(t, "GRPCPLPW001", resp.Results[0].Details.SwiftCode)
+		assert.False(t, resp.Results[1].Found)
+		assert.NotEmpty(t, resp.Results[2].Error)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		resp, err := client.SearchInstitutions(ctx, &swiftv1.SearchInstitutionsRequest{Query: "grpc"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, resp.Total)
+		assert.EqualValues(t, service.DefaultInstitutionLimit, resp.Limit)
+		require.Len(t, resp.Institutions, 1)
+		assert.Equal(t, "GRPC", resp.Institutions[0].InstitutionCode)
+		assert.Equal(t, "GRPC BANK", resp.Institutions[0].Name)
+		require.Len(t, resp.Institutions[0].Headquarters, 1)
+		assert.EqualValues(t, 1, resp.Institutions[0].Headquarters[0].BranchCount)
+	})
+
+	t.Run("Create, update and delete", func(t *testing.T) {
+		ctx := withKey("editor-key")
+		_, err := client.CreateSwiftCode(ctx, &swiftv1.CreateSwiftCodeRequest{
+			SwiftCode: "GRPCPLPW002", BankName: "GRPC BANK", Address: "ul. Prosta 1", CountryIso2: "pl", CountryName: "poland",
+		})
+		require.NoError(t, err)
+
+		code, err := client.GetSwiftCode(ctx, &swiftv1.GetSwiftCodeRequest{SwiftCode: "GRPCPLPW002"})
+		require.NoError(t, err)
+		assert.Equal(t, "POLAND", code.CountryName)
+
+		_, err = client.UpdateSwiftCode(ctx, &swiftv1.UpdateSwiftCodeRequest{
+			SwiftCode: "GRPCPLPW002", BankName: "RENAMED BANK", Address: "ul. Prosta 1", CountryIso2: "PL", CountryName: "POLAND",
+		})
+		require.NoError(t, err)
+		code, err = client.GetSwiftCode(ctx, &swiftv1.GetSwiftCodeRequest{SwiftCode: "GRPCPLPW002"})
+		require.NoError(t, err)
+		assert.Equal(t, "RENAMED BANK", code.BankName)
+
+		_, err = client.DeleteSwiftCode(ctx, &swiftv1.DeleteSwiftCodeRequest{SwiftCode: "GRPCPLPW002"})
+		require.NoError(t, err)
+	})
+}
+
+func TestStatusCodes(t *testing.T) {
+	client := swiftv1.NewSwiftCodesClient(dial(t, grpcapi.DefaultConfig(), seed(t), "", grpcapi.Limits{}))
+	ctx := withKey("editor-key")
+
+	for _, tc := range []struct {
+		name    string
+		call    func() error
+		code    codes.Code
+		message string
+	}{
+		{"Invalid code", func() error {
+			_, err := client.GetSwiftCode(ctx, &swiftv1.GetSwiftCodeRequest{SwiftCode: "SHORT"})
+			return err
+		}, codes.InvalidArgument, "SWIFT code must be exactly 11 characters"},
+		{"Negative branch limit", func() error {
+			_, err := client.GetSwiftCode(ctx, &swiftv1.GetSwiftCodeRequest{SwiftCode: "GRPCPLPWXXX", BranchLimit: -1})
+			return err
+		}, codes.InvalidArgument, "branch_limit must not be negative"},
+		{"Unknown code", func() error {
+			_, err := client.GetSwiftCode(ctx, &swiftv1.GetSwiftCodeRequest{SwiftCode: "MISSINGGXXX"})
+			return err
+		}, codes.NotFound, "SWIFT code not found"},
+		{"Unknown country", func() error {
+			_, err := client.ListSwiftCodesByCountry(ctx, &swiftv1.ListSwiftCodesByCountryRequest{CountryIso2: "DE"})
+			return err
+		}, codes.NotFound, "No SWIFT codes found for this country"},
+		{"Duplicate code", func() error {
+			_, err := client.CreateSwiftCode(ctx, &swiftv1.CreateSwiftCodeRequest{
+				SwiftCode: "GRPCPLPW001", BankName: "GRPC BANK", Address: "ul. Prosta 1", CountryIso2: "PL", CountryName: "POLAND",
+			})
+			return err
+		}, codes.AlreadyExists, "SWIFT code already exists"},
+		{"Update of an unknown code", func() error {
+			_, err := client.UpdateSwiftCode(ctx, &swiftv1.UpdateSwiftCodeRequest{
+				SwiftCode: "MISSINGGXXX", BankName: "GRPC BANK", Address: "ul. Prosta 1", CountryIso2: "PL", CountryName: "POLAND", IsHeadquarter: true,
+			})
+			return err
+		}, codes.NotFound, "SWIFT code not found"},
+		{"Headquarter with branches", func() error {
+			_, err := client.DeleteSwiftCode(ctx, &swiftv1.DeleteSwiftCodeRequest{SwiftCode: "GRPCPLPWXXX"})
+			return err
+		}, codes.FailedPrecondition, "Cannot delete headquarter with existing branches"},
+		{"Search past the limit", func() error {
+			_, err := client.SearchInstitutions(ctx, &swiftv1.SearchInstitutionsRequest{Limit: 101})
+			return err
+		}, codes.InvalidArgument, "limit must be an integer between 1 and 100"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			assert.Equal(t, tc.code, status.Code(err))
+			assert.Equal(t, tc.message, status.Convert(err).Message())
+		})
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	client := swiftv1.NewSwiftCodesClient(dial(t, grpcapi.DefaultConfig(), seed(t), auth.RoleReader, grpcapi.Limits{}))
+	get := func(ctx context.Context) error {
+		_, err := client.GetSwiftCode(ctx, &swiftv1.GetSwiftCodeRequest{SwiftCode: "GRPCPLPWXXX"})
+		return err
+	}
+	del := func(ctx context.Context) error {
+		_, err := client.DeleteSwiftCode(ctx, &swiftv1.DeleteSwiftCodeRequest{SwiftCode: "GRPCPLPW001"})
+		return err
+	}
+
+	for _, tc := range []struct {
+		name    string
+		call    func(context.Context) error
+		key     string
+		code    codes.Code
+		message string
+	}{
+		{"Anonymous read", get, "", codes.Unauthenticated, "Authentication required"},
+		{"Invalid key", get, "bogus", codes.Unauthenticated, "Invalid credentials"},
+		{"Reader read", get, "reader-key", codes.OK, ""},
+		{"Reader write", del, "reader-key", codes.PermissionDenied, "Insufficient role, editor required"},
+		{"Editor write", del, "editor-key", codes.OK, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.key != "" {
+				ctx = withKey(tc.key)
+			}
+			err := tc.call(ctx)
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.message != "" {
+				assert.Equal(t, tc.message, status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limits := grpcapi.Limits{
+		IP:    ratelimit.NewMemoryLimiter(ratelimit.Policy{Rate: 0.001, Burst: 3}),
+		Read:  ratelimit.NewMemoryLimiter(ratelimit.Policy{Rate: 0.001, Burst: 1}),
+		Write: ratelimit.NewMemoryLimiter(ratelimit.Policy{Rate: 0.001, Burst: 1}),
+	}
+	client := swiftv1.NewSwiftCodesClient(dial(t, grpcapi.DefaultConfig(), seed(t), auth.RoleReader, limits))
+	get := func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := client.GetSwiftCode(ctx, &swiftv1.GetSwiftCodeRequest{SwiftCode: "GRPCPLPWXXX"}, opts...)
+		return err
+	}
+
+	t.Run("Reads are limited per key", func(t *testing.T) {
+		assert.NoError(t, get(withKey("reader-key")))
+
+		var header metadata.MD
+		err := get(withKey("reader-key"), grpc.Header(&header))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, []string{"1000"}, header.Get("retry-after"))
+
+		assert.NoError(t, get(withKey("editor-key")))
+	})
+
+	t.Run("Bad credentials are limited by address", func(t *testing.T) {
+		// The three calls above used up the address's bucket.
+		err := get(withKey("bogus"))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	cfg := grpcapi.DefaultConfig()
+	cfg.Timeout = 50 * time.Millisecond
+	client := swiftv1.NewSwiftCodesClient(dial(t, cfg, slowRepo{seed(t)}, "", grpcapi.Limits{}))
+
+	start := time.Now()
+	_, err := client.GetSwiftCode(context.Background(), &swiftv1.GetSwiftCodeRequest{SwiftCode: "GRPCPLPWXXX"})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestReflection(t *testing.T) {
+	client := reflectionpb.NewServerReflectionClient(dial(t, grpcapi.DefaultConfig(), seed(t), "", grpcapi.Limits{}))
+	stream, err := client.ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.Name)
+	}
+	assert.Contains(t, names, "swift.v1.SwiftCodes")
+	require.NoError(t, stream.CloseSend())
+}
@@ -0,0 +1,185 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"swift-api/pkg/grpcapi/swiftv1"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"swift-api/pkg/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type swiftCodes struct {
+	swiftv1.UnimplementedSwiftCodesServer
+	svc *service.Service
+}
+
+// toStatus maps an error of the service layer to the status REST answers
+// with as an HTTP status: 400 InvalidArgument, 404 NotFound, 409
+// AlreadyExists or FailedPrecondition, 500 Internal.
+func toStatus(err error) error {
+	message := "Internal server error"
+	var e *service.Error
+	if errors.As(err, &e) {
+		message = e.Message
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, service.ErrInvalid):
+		code = codes.InvalidArgument
+	case errors.Is(err, service.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, service.ErrExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, service.ErrConflict):
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, message)
+}
+
+func newSwiftCode(code models.SwiftCode) *swiftv1.SwiftCode {
+	pb := &swiftv1.SwiftCode{
+		SwiftCode:     code.SwiftCode,
+		BankName:      code.BankName,
+		TownName:      code.TownName,
+		CountryIso2:   code.CountryISO2,
+		CountryName:   code.CountryName,
+		Timezone:      code.Timezone,
+		IsHeadquarter: code.IsHeadquarter,
+	}
+	if code.Address != nil {
+		pb.Address = *code.Address
+	}
+	if code.HeadquarterSWIFTCode != nil {
+		pb.HeadquarterSwiftCode = *code.HeadquarterSWIFTCode
+	}
+	for _, b := range code.Branches {
+		pb.Branches = append(pb.Branches, newSwiftCode(b))
+	}
+	return pb
+}
+
+func (s *swiftCodes) GetSwiftCode(ctx context.Context, req *swiftv1.GetSwiftCodeRequest) (*swiftv1.SwiftCode, error) {
+	if req.BranchLimit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "branch_limit must not be negative")
+	}
+	code, err := s.svc.GetSwiftCode(ctx, req.SwiftCode, service.LookupOptions{
+		Branches:    !req.SkipBranches,
+		BranchLimit: int(req.BranchLimit),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return newSwiftCode(*code), nil
+}
+
+func (s *swiftCodes) ListSwiftCodesByCountry(ctx context.Context, req *swiftv1.ListSwiftCodesByCountryRequest) (*swiftv1.ListSwiftCodesByCountryResponse, error) {
+	codes, countryName, err := s.svc.ListByCountry(ctx, req.CountryIso2)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &swiftv1.ListSwiftCodesByCountryResponse{CountryIso2: codes[0].CountryISO2, CountryName: countryName}
+	for _, code := range codes {
+		resp.SwiftCodes = append(resp.SwiftCodes, newSwiftCode(code))
+	}
+	return resp, nil
+}
+
+func (s *swiftCodes) CreateSwiftCode(ctx context.Context, req *swiftv1.CreateSwiftCodeRequest) (*swiftv1.CreateSwiftCodeResponse, error) {
+	err := s.svc.CreateSwiftCode(ctx, service.NewSwiftCode{
+		Address:       req.Address,
+		BankName:      req.BankName,
+		CountryISO2:   req.CountryIso2,
+		CountryName:   req.CountryName,
+		IsHeadquarter: req.IsHeadquarter,
+		SwiftCode:     req.SwiftCode,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &swiftv1.CreateSwiftCodeResponse{}, nil
+}
+
+func (s *swiftCodes) UpdateSwiftCode(ctx context.Context, req *swiftv1.UpdateSwiftCodeRequest) (*swiftv1.UpdateSwiftCodeResponse, error) {
+	err := s.svc.UpdateSwiftCode(ctx, req.SwiftCode, service.NewSwiftCode{
+		Address:       req.Address,
+		BankName:      req.BankName,
+		CountryISO2:   req.CountryIso2,
+		CountryName:   req.CountryName,
+		IsHeadquarter: req.IsHeadquarter,
+		SwiftCode:     req.SwiftCode,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &swiftv1.UpdateSwiftCodeResponse{}, nil
+}
+
+func (s *swiftCodes) DeleteSwiftCode(ctx context.Context, req *swiftv1.DeleteSwiftCodeRequest) (*swiftv1.DeleteSwiftCodeResponse, error) {
+	if err := s.svc.DeleteSwiftCode(ctx, req.SwiftCode); err != nil {
+		return nil, toStatus(err)
+	}
+	return &swiftv1.DeleteSwiftCodeResponse{}, nil
+}
+
+func (s *swiftCodes) BatchGetSwiftCodes(ctx context.Context, req *swiftv1.BatchGetSwiftCodesRequest) (*swiftv1.BatchGetSwiftCodesResponse, error) {
+	results, err := s.svc.LookupSwiftCodes(ctx, req.SwiftCodes)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &swiftv1.BatchGetSwiftCodesResponse{Results: make([]*swiftv1.LookupResult, len(results))}
+	for i, result := range results {
+		resp.Results[i] = &swiftv1.LookupResult{Query: result.Query, SwiftCode: result.SwiftCode, Error: result.Error}
+		if result.Code == nil {
+			resp.NotFound++
+			continue
+		}
+		resp.Found++
+		resp.Results[i].Found = true
+		resp.Results[i].Details = newSwiftCode(*result.Code)
+	}
+	return resp, nil
+}
+
+func (s *swiftCodes) SearchInstitutions(ctx context.Context, req *swiftv1.SearchInstitutionsRequest) (*swiftv1.SearchInstitutionsResponse, error) {
+	q := repository.InstitutionQuery{
+		Text:        req.Query,
+		CountryISO2: req.CountryIso2,
+		Limit:       int(req.Limit),
+		Offset:      int(req.Offset),
+	}
+	if q.Limit == 0 {
+		q.Limit = service.DefaultInstitutionLimit
+	}
+	institutions, total, err := s.svc.SearchInstitutions(ctx, q)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &swiftv1.SearchInstitutionsResponse{Total: int32(total), Limit: int32(q.Limit), Offset: req.Offset}
+	for _, institution := range institutions {
+		pb := &swiftv1.Institution{InstitutionCode: institution.Code, Name: institution.Name()}
+		for _, hq := range institution.Headquarters {
+			pb.Headquarters = append(pb.Headquarters, &swiftv1.InstitutionHeadquarter{
+				SwiftCode:   hq.SwiftCode,
+				BankName:    hq.BankName,
+				TownName:    hq.TownName,
+				CountryIso2: hq.CountryISO2,
+				CountryName: hq.CountryName,
+				BranchCount: int32(hq.BranchCount),
+			})
+		}
+		resp.Institutions = append(resp.Institutions, pb)
+	}
+	return resp, nil
+}
@@ -0,0 +1,1273 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: swift/v1/swift.proto
+
+package swiftv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SwiftCode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	BankName      string                 `protobuf:"bytes,2,opt,name=bank_name,json=bankName,proto3" json:"bank_name,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	TownName      string                 `protobuf:"bytes,4,opt,name=town_name,json=townName,proto3" json:"town_name,omitempty"`
+	CountryIso2   string                 `protobuf:"bytes,5,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	CountryName   string                 `protobuf:"bytes,6,opt,name=country_name,json=countryName,proto3" json:"country_name,omitempty"`
+	Timezone      string                 `protobuf:"bytes,7,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	IsHeadquarter bool                   `protobuf:"varint,8,opt,name=is_headquarter,json=isHeadquarter,proto3" json:"is_headquarter,omitempty"`
+	// Empty for a headquarter.
+	HeadquarterSwiftCode string `protobuf:"bytes,9,opt,name=headquarter_swift_code,json=headquarterSwiftCode,proto3" json:"headquarter_swift_code,omitempty"`
+	// The branches of a headquarter, ordered by SWIFT code.
+	Branches      []*SwiftCode `protobuf:"bytes,10,rep,name=branches,proto3" json:"branches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SwiftCode) Reset() {
+	*x = SwiftCode{}
+	mi := &file_swift_v1_swift_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SwiftCode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwiftCode) ProtoMessage() {}
+
+func (x *SwiftCode) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwiftCode.ProtoReflect.Descriptor instead.
+func (*SwiftCode) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SwiftCode) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetBankName() string {
+	if x != nil {
+		return x.BankName
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetTownName() string {
+	if x != nil {
+		return x.TownName
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetCountryName() string {
+	if x != nil {
+		return x.CountryName
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetIsHeadquarter() bool {
+	if x != nil {
+		return x.IsHeadquarter
+	}
+	return false
+}
+
+func (x *SwiftCode) GetHeadquarterSwiftCode() string {
+	if x != nil {
+		return x.HeadquarterSwiftCode
+	}
+	return ""
+}
+
+func (x *SwiftCode) GetBranches() []*SwiftCode {
+	if x != nil {
+		return x.Branches
+	}
+	return nil
+}
+
+type GetSwiftCodeRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode    string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	SkipBranches bool                   `protobuf:"varint,2,opt,name=skip_branches,json=skipBranches,proto3" json:"skip_branches,omitempty"`
+	// Embed at most this many branches, ordered by SWIFT code. 0 embeds all.
+	BranchLimit   int32 `protobuf:"varint,3,opt,name=branch_limit,json=branchLimit,proto3" json:"branch_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSwiftCodeRequest) Reset() {
+	*x = GetSwiftCodeRequest{}
+	mi := &file_swift_v1_swift_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSwiftCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSwiftCodeRequest) ProtoMessage() {}
+
+func (x *GetSwiftCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSwiftCodeRequest.ProtoReflect.Descriptor instead.
+func (*GetSwiftCodeRequest) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetSwiftCodeRequest) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *GetSwiftCodeRequest) GetSkipBranches() bool {
+	if x != nil {
+		return x.SkipBranches
+	}
+	return false
+}
+
+func (x *GetSwiftCodeRequest) GetBranchLimit() int32 {
+	if x != nil {
+		return x.BranchLimit
+	}
+	return 0
+}
+
+type ListSwiftCodesByCountryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CountryIso2   string                 `protobuf:"bytes,1,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSwiftCodesByCountryRequest) Reset() {
+	*x = ListSwiftCodesByCountryRequest{}
+	mi := &file_swift_v1_swift_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSwiftCodesByCountryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSwiftCodesByCountryRequest) ProtoMessage() {}
+
+func (x *ListSwiftCodesByCountryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSwiftCodesByCountryRequest.ProtoReflect.Descriptor instead.
+func (*ListSwiftCodesByCountryRequest) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListSwiftCodesByCountryRequest) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+type ListSwiftCodesByCountryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CountryIso2   string                 `protobuf:"bytes,1,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	CountryName   string                 `protobuf:"bytes,2,opt,name=country_name,json=countryName,proto3" json:"country_name,omitempty"`
+	SwiftCodes    []*SwiftCode           `protobuf:"bytes,3,rep,name=swift_codes,json=swiftCodes,proto3" json:"swift_codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSwiftCodesByCountryResponse) Reset() {
+	*x = ListSwiftCodesByCountryResponse{}
+	mi := &file_swift_v1_swift_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSwiftCodesByCountryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSwiftCodesByCountryResponse) ProtoMessage() {}
+
+func (x *ListSwiftCodesByCountryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSwiftCodesByCountryResponse.ProtoReflect.Descriptor instead.
+func (*ListSwiftCodesByCountryResponse) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListSwiftCodesByCountryResponse) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *ListSwiftCodesByCountryResponse) GetCountryName() string {
+	if x != nil {
+		return x.CountryName
+	}
+	return ""
+}
+
+func (x *ListSwiftCodesByCountryResponse) GetSwiftCodes() []*SwiftCode {
+	if x != nil {
+		return x.SwiftCodes
+	}
+	return nil
+}
+
+type CreateSwiftCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	BankName      string                 `protobuf:"bytes,2,opt,name=bank_name,json=bankName,proto3" json:"bank_name,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	CountryIso2   string                 `protobuf:"bytes,4,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	CountryName   string                 `protobuf:"bytes,5,opt,name=country_name,json=countryName,proto3" json:"country_name,omitempty"`
+	IsHeadquarter bool                   `protobuf:"varint,6,opt,name=is_headquarter,json=isHeadquarter,proto3" json:"is_headquarter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSwiftCodeRequest) Reset() {
+	*x = CreateSwiftCodeRequest{}
+	mi := &file_swift_v1_swift_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSwiftCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSwiftCodeRequest) ProtoMessage() {}
+
+func (x *CreateSwiftCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSwiftCodeRequest.ProtoReflect.Descriptor instead.
+func (*CreateSwiftCodeRequest) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateSwiftCodeRequest) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *CreateSwiftCodeRequest) GetBankName() string {
+	if x != nil {
+		return x.BankName
+	}
+	return ""
+}
+
+func (x *CreateSwiftCodeRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CreateSwiftCodeRequest) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *CreateSwiftCodeRequest) GetCountryName() string {
+	if x != nil {
+		return x.CountryName
+	}
+	return ""
+}
+
+func (x *CreateSwiftCodeRequest) GetIsHeadquarter() bool {
+	if x != nil {
+		return x.IsHeadquarter
+	}
+	return false
+}
+
+type CreateSwiftCodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSwiftCodeResponse) Reset() {
+	*x = CreateSwiftCodeResponse{}
+	mi := &file_swift_v1_swift_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSwiftCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSwiftCodeResponse) ProtoMessage() {}
+
+func (x *CreateSwiftCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSwiftCodeResponse.ProtoReflect.Descriptor instead.
+func (*CreateSwiftCodeResponse) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{5}
+}
+
+type UpdateSwiftCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	BankName      string                 `protobuf:"bytes,2,opt,name=bank_name,json=bankName,proto3" json:"bank_name,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	CountryIso2   string                 `protobuf:"bytes,4,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	CountryName   string                 `protobuf:"bytes,5,opt,name=country_name,json=countryName,proto3" json:"country_name,omitempty"`
+	IsHeadquarter bool                   `protobuf:"varint,6,opt,name=is_headquarter,json=isHeadquarter,proto3" json:"is_headquarter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSwiftCodeRequest) Reset() {
+	*x = UpdateSwiftCodeRequest{}
+	mi := &file_swift_v1_swift_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSwiftCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSwiftCodeRequest) ProtoMessage() {}
+
+func (x *UpdateSwiftCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSwiftCodeRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSwiftCodeRequest) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateSwiftCodeRequest) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *UpdateSwiftCodeRequest) GetBankName() string {
+	if x != nil {
+		return x.BankName
+	}
+	return ""
+}
+
+func (x *UpdateSwiftCodeRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *UpdateSwiftCodeRequest) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *UpdateSwiftCodeRequest) GetCountryName() string {
+	if x != nil {
+		return x.CountryName
+	}
+	return ""
+}
+
+func (x *UpdateSwiftCodeRequest) GetIsHeadquarter() bool {
+	if x != nil {
+		return x.IsHeadquarter
+	}
+	return false
+}
+
+type UpdateSwiftCodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSwiftCodeResponse) Reset() {
+	*x = UpdateSwiftCodeResponse{}
+	mi := &file_swift_v1_swift_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSwiftCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSwiftCodeResponse) ProtoMessage() {}
+
+func (x *UpdateSwiftCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSwiftCodeResponse.ProtoReflect.Descriptor instead.
+func (*UpdateSwiftCodeResponse) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{7}
+}
+
+type DeleteSwiftCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSwiftCodeRequest) Reset() {
+	*x = DeleteSwiftCodeRequest{}
+	mi := &file_swift_v1_swift_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSwiftCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSwiftCodeRequest) ProtoMessage() {}
+
+func (x *DeleteSwiftCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSwiftCodeRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSwiftCodeRequest) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteSwiftCodeRequest) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+type DeleteSwiftCodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSwiftCodeResponse) Reset() {
+	*x = DeleteSwiftCodeResponse{}
+	mi := &file_swift_v1_swift_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSwiftCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSwiftCodeResponse) ProtoMessage() {}
+
+func (x *DeleteSwiftCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSwiftCodeResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSwiftCodeResponse) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{9}
+}
+
+type BatchGetSwiftCodesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCodes    []string               `protobuf:"bytes,1,rep,name=swift_codes,json=swiftCodes,proto3" json:"swift_codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetSwiftCodesRequest) Reset() {
+	*x = BatchGetSwiftCodesRequest{}
+	mi := &file_swift_v1_swift_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetSwiftCodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetSwiftCodesRequest) ProtoMessage() {}
+
+func (x *BatchGetSwiftCodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetSwiftCodesRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetSwiftCodesRequest) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BatchGetSwiftCodesRequest) GetSwiftCodes() []string {
+	if x != nil {
+		return x.SwiftCodes
+	}
+	return nil
+}
+
+type BatchGetSwiftCodesResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Found    int32                  `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	NotFound int32                  `protobuf:"varint,2,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	// One result per requested code, in request order.
+	Results       []*LookupResult `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetSwiftCodesResponse) Reset() {
+	*x = BatchGetSwiftCodesResponse{}
+	mi := &file_swift_v1_swift_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetSwiftCodesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetSwiftCodesResponse) ProtoMessage() {}
+
+func (x *BatchGetSwiftCodesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetSwiftCodesResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetSwiftCodesResponse) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BatchGetSwiftCodesResponse) GetFound() int32 {
+	if x != nil {
+		return x.Found
+	}
+	return 0
+}
+
+func (x *BatchGetSwiftCodesResponse) GetNotFound() int32 {
+	if x != nil {
+		return x.NotFound
+	}
+	return 0
+}
+
+func (x *BatchGetSwiftCodesResponse) GetResults() []*LookupResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type LookupResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Query string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// The normalized code, empty when the query is invalid.
+	SwiftCode string `protobuf:"bytes,2,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	Found     bool   `protobuf:"varint,3,opt,name=found,proto3" json:"found,omitempty"`
+	// Why the query is invalid.
+	Error         string     `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Details       *SwiftCode `protobuf:"bytes,5,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResult) Reset() {
+	*x = LookupResult{}
+	mi := &file_swift_v1_swift_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResult) ProtoMessage() {}
+
+func (x *LookupResult) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResult.ProtoReflect.Descriptor instead.
+func (*LookupResult) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LookupResult) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *LookupResult) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *LookupResult) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *LookupResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *LookupResult) GetDetails() *SwiftCode {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+type SearchInstitutionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Matches an institution code prefix or part of a bank name.
+	Query       string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	CountryIso2 string `protobuf:"bytes,2,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	// 1 to 100; 0 means 20.
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchInstitutionsRequest) Reset() {
+	*x = SearchInstitutionsRequest{}
+	mi := &file_swift_v1_swift_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchInstitutionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchInstitutionsRequest) ProtoMessage() {}
+
+func (x *SearchInstitutionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchInstitutionsRequest.ProtoReflect.Descriptor instead.
+func (*SearchInstitutionsRequest) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SearchInstitutionsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchInstitutionsRequest) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *SearchInstitutionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchInstitutionsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type SearchInstitutionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Institutions  []*Institution         `protobuf:"bytes,4,rep,name=institutions,proto3" json:"institutions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchInstitutionsResponse) Reset() {
+	*x = SearchInstitutionsResponse{}
+	mi := &file_swift_v1_swift_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchInstitutionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchInstitutionsResponse) ProtoMessage() {}
+
+func (x *SearchInstitutionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchInstitutionsResponse.ProtoReflect.Descriptor instead.
+func (*SearchInstitutionsResponse) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SearchInstitutionsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchInstitutionsResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchInstitutionsResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SearchInstitutionsResponse) GetInstitutions() []*Institution {
+	if x != nil {
+		return x.Institutions
+	}
+	return nil
+}
+
+type Institution struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	InstitutionCode string                 `protobuf:"bytes,1,opt,name=institution_code,json=institutionCode,proto3" json:"institution_code,omitempty"`
+	// The bank name most of its headquarters share.
+	Name          string                    `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Headquarters  []*InstitutionHeadquarter `protobuf:"bytes,3,rep,name=headquarters,proto3" json:"headquarters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Institution) Reset() {
+	*x = Institution{}
+	mi := &file_swift_v1_swift_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Institution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Institution) ProtoMessage() {}
+
+func (x *Institution) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Institution.ProtoReflect.Descriptor instead.
+func (*Institution) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Institution) GetInstitutionCode() string {
+	if x != nil {
+		return x.InstitutionCode
+	}
+	return ""
+}
+
+func (x *Institution) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Institution) GetHeadquarters() []*InstitutionHeadquarter {
+	if x != nil {
+		return x.Headquarters
+	}
+	return nil
+}
+
+type InstitutionHeadquarter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SwiftCode     string                 `protobuf:"bytes,1,opt,name=swift_code,json=swiftCode,proto3" json:"swift_code,omitempty"`
+	BankName      string                 `protobuf:"bytes,2,opt,name=bank_name,json=bankName,proto3" json:"bank_name,omitempty"`
+	TownName      string                 `protobuf:"bytes,3,opt,name=town_name,json=townName,proto3" json:"town_name,omitempty"`
+	CountryIso2   string                 `protobuf:"bytes,4,opt,name=country_iso2,json=countryIso2,proto3" json:"country_iso2,omitempty"`
+	CountryName   string                 `protobuf:"bytes,5,opt,name=country_name,json=countryName,proto3" json:"country_name,omitempty"`
+	BranchCount   int32                  `protobuf:"varint,6,opt,name=branch_count,json=branchCount,proto3" json:"branch_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstitutionHeadquarter) Reset() {
+	*x = InstitutionHeadquarter{}
+	mi := &file_swift_v1_swift_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstitutionHeadquarter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstitutionHeadquarter) ProtoMessage() {}
+
+func (x *InstitutionHeadquarter) ProtoReflect() protoreflect.Message {
+	mi := &file_swift_v1_swift_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstitutionHeadquarter.ProtoReflect.Descriptor instead.
+func (*InstitutionHeadquarter) Descriptor() ([]byte, []int) {
+	return file_swift_v1_swift_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *InstitutionHeadquarter) GetSwiftCode() string {
+	if x != nil {
+		return x.SwiftCode
+	}
+	return ""
+}
+
+func (x *InstitutionHeadquarter) GetBankName() string {
+	if x != nil {
+		return x.BankName
+	}
+	return ""
+}
+
+func (x *InstitutionHeadquarter) GetTownName() string {
+	if x != nil {
+		return x.TownName
+	}
+	return ""
+}
+
+func (x *InstitutionHeadquarter) GetCountryIso2() string {
+	if x != nil {
+		return x.CountryIso2
+	}
+	return ""
+}
+
+func (x *InstitutionHeadquarter) GetCountryName() string {
+	if x != nil {
+		return x.CountryName
+	}
+	return ""
+}
+
+func (x *InstitutionHeadquarter) GetBranchCount() int32 {
+	if x != nil {
+		return x.BranchCount
+	}
+	return 0
+}
+
+var File_swift_v1_swift_proto protoreflect.FileDescriptor
+
+const file_swift_v1_swift_proto_rawDesc = "" +
+	"\n" +
+	"\x14swift/v1/swift.proto\x12\bswift.v1\"\xee\x02\n" +
+	"\tSwiftCode\x12\x1d\n" +
+	"\n" +
+	"swift_code\x18\x01 \x01(\tR\tswiftCode\x12\x1b\n" +
+	"\tbank_name\x18\x02 \x01(\tR\bbankName\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12\x1b\n" +
+	"\ttown_name\x18\x04 \x01(\tR\btownName\x12!\n" +
+	"\fcountry_iso2\x18\x05 \x01(\tR\vcountryIso2\x12!\n" +
+	"\fcountry_name\x18\x06 \x01(\tR\vcountryName\x12\x1a\n" +
+	"\btimezone\x18\a \x01(\tR\btimezone\x12%\n" +
+	"\x0eis_headquarter\x18\b \x01(\bR\risHeadquarter\x124\n" +
+	"\x16headquarter_swift_code\x18\t \x01(\tR\x14headquarterSwiftCode\x12/\n" +
+	"\bbranches\x18\n" +
+	" \x03(\v2\x13.swift.v1.SwiftCodeR\bbranches\"|\n" +
+	"\x13GetSwiftCodeRequest\x12\x1d\n" +
+	"\n" +
+	"swift_code\x18\x01 \x01(\tR\tswiftCode\x12#\n" +
+	"\rskip_branches\x18\x02 \x01(\bR\fskipBranches\x12!\n" +
+	"\fbranch_limit\x18\x03 \x01(\x05R\vbranchLimit\"C\n" +
+	"\x1eListSwiftCodesByCountryRequest\x12!\n" +
+	"\fcountry_iso2\x18\x01 \x01(\tR\vcountryIso2\"\x9d\x01\n" +
+	"\x1fListSwiftCodesByCountryResponse\x12!\n" +
+	"\fcountry_iso2\x18\x01 \x01(\tR\vcountryIso2\x12!\n" +
+	"\fcountry_name\x18\x02 \x01(\tR\vcountryName\x124\n" +
+	"\vswift_codes\x18\x03 \x03(\v2\x13.swift.v1.SwiftCodeR\n" +
+	"swiftCodes\"\xdb\x01\n" +
+	"\x16CreateSwiftCodeRequest\x12\x1d\n" +
+	"\n" +
+	"swift_code\x18\x01 \x01(\tR\tswiftCode\x12\x1b\n" +
+	"\tbank_name\x18\x02 \x01(\tR\bbankName\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12!\n" +
+	"\fcountry_iso2\x18\x04 \x01(\tR\vcountryIso2\x12!\n" +
+	"\fcountry_name\x18\x05 \x01(\tR\vcountryName\x12%\n" +
+	"\x0eis_headquarter\x18\x06 \x01(\bR\risHeadquarter\"\x19\n" +
+	"\x17CreateSwiftCodeResponse\"\xdb\x01\n" +
+	"\x16UpdateSwiftCodeRequest\x12\x1d\n" +
+	"\n" +
+	"swift_code\x18\x01 \x01(\tR\tswiftCode\x12\x1b\n" +
+	"\tbank_name\x18\x02 \x01(\tR\bbankName\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12!\n" +
+	"\fcountry_iso2\x18\x04 \x01(\tR\vcountryIso2\x12!\n" +
+	"\fcountry_name\x18\x05 \x01(\tR\vcountryName\x12%\n" +
+	"\x0eis_headquarter\x18\x06 \x01(\bR\risHeadquarter\"\x19\n" +
+	"\x17UpdateSwiftCodeResponse\"7\n" +
+	"\x16DeleteSwiftCodeRequest\x12\x1d\n" +
+	"\n" +
+	"swift_code\x18\x01 \x01(\tR\tswiftCode\"\x19\n" +
+	"\x17DeleteSwiftCodeResponse\"<\n" +
+	"\x19BatchGetSwiftCodesRequest\x12\x1f\n" +
+	"\vswift_codes\x18\x01 \x03(\tR\n" +
+	"swiftCodes\"\x81\x01\n" +
+	"\x1aBatchGetSwiftCodesResponse\x12\x14\n" +
+	"\x05found\x18\x01 \x01(\x05R\x05found\x12\x1b\n" +
+	"\tnot_found\x18\x02 \x01(\x05R\bnotFound\x120\n" +
+	"\aresults\x18\x03 \x03(\v2\x16.swift.v1.LookupResultR\aresults\"\x9e\x01\n" +
+	"\fLookupResult\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1d\n" +
+	"\n" +
+	"swift_code\x18\x02 \x01(\tR\tswiftCode\x12\x14\n" +
+	"\x05found\x18\x03 \x01(\bR\x05found\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12-\n" +
+	"\adetails\x18\x05 \x01(\v2\x13.swift.v1.SwiftCodeR\adetails\"\x82\x01\n" +
+	"\x19SearchInstitutionsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12!\n" +
+	"\fcountry_iso2\x18\x02 \x01(\tR\vcountryIso2\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\"\x9b\x01\n" +
+	"\x1aSearchInstitutionsResponse\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x129\n" +
+	"\finstitutions\x18\x04 \x03(\v2\x15.swift.v1.InstitutionR\finstitutions\"\x92\x01\n" +
+	"\vInstitution\x12)\n" +
+	"\x10institution_code\x18\x01 \x01(\tR\x0finstitutionCode\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12D\n" +
+	"\fheadquarters\x18\x03 \x03(\v2 .swift.v1.InstitutionHeadquarterR\fheadquarters\"\xda\x01\n" +
+	"\x16InstitutionHeadquarter\x12\x1d\n" +
+	"\n" +
+	"swift_code\x18\x01 \x01(\tR\tswiftCode\x12\x1b\n" +
+	"\tbank_name\x18\x02 \x01(\tR\bbankName\x12\x1b\n" +
+	"\ttown_name\x18\x03 \x01(\tR\btownName\x12!\n" +
+	"\fcountry_iso2\x18\x04 \x01(\tR\vcountryIso2\x12!\n" +
+	"\fcountry_name\x18\x05 \x01(\tR\vcountryName\x12!\n" +
+	"\fbranch_count\x18\x06 \x01(\x05R\vbranchCount2\x8a\x05\n" +
+	"\n" +
+	"SwiftCodes\x12B\n" +
+	"\fGetSwiftCode\x12\x1d.swift.v1.GetSwiftCodeRequest\x1a\x13.swift.v1.SwiftCode\x12n\n" +
+	"\x17ListSwiftCodesByCountry\x12(.swift.v1.ListSwiftCodesByCountryRequest\x1a).swift.v1.ListSwiftCodesByCountryResponse\x12V\n" +
+	"\x0fCreateSwiftCode\x12 .swift.v1.CreateSwiftCodeRequest\x1a!.swift.v1.CreateSwiftCodeResponse\x12V\n" +
+	"\x0fUpdateSwiftCode\x12 .swift.v1.UpdateSwiftCodeRequest\x1a!.swift.v1.UpdateSwiftCodeResponse\x12V\n" +
+	"\x0fDeleteSwiftCode\x12 .swift.v1.DeleteSwiftCodeRequest\x1a!.swift.v1.DeleteSwiftCodeResponse\x12_\n" +
+	"\x12BatchGetSwiftCodes\x12#.swift.v1.BatchGetSwiftCodesRequest\x1a$.swift.v1.BatchGetSwiftCodesResponse\x12_\n" +
+	"\x12SearchInstitutions\x12#.swift.v1.SearchInstitutionsRequest\x1a$.swift.v1.SearchInstitutionsResponseB'Z%swift-api/pkg/grpcapi/swiftv1;swiftv1b\x06proto3"
+
+var (
+	file_swift_v1_swift_proto_rawDescOnce sync.Once
+	file_swift_v1_swift_proto_rawDescData []byte
+)
+
+func file_swift_v1_swift_proto_rawDescGZIP() []byte {
+	file_swift_v1_swift_proto_rawDescOnce.Do(func() {
+		file_swift_v1_swift_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_swift_v1_swift_proto_rawDesc), len(file_swift_v1_swift_proto_rawDesc)))
+	})
+	return file_swift_v1_swift_proto_rawDescData
+}
+
+var file_swift_v1_swift_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_swift_v1_swift_proto_goTypes = []any{
+	(*SwiftCode)(nil),                       // 0: swift.v1.SwiftCode
+	(*GetSwiftCodeRequest)(nil),             // 1: swift.v1.GetSwiftCodeRequest
+	(*ListSwiftCodesByCountryRequest)(nil),  // 2: swift.v1.ListSwiftCodesByCountryRequest
+	(*ListSwiftCodesByCountryResponse)(nil), // 3: swift.v1.ListSwiftCodesByCountryResponse
+	(*CreateSwiftCodeRequest)(nil),          // 4: swift.v1.CreateSwiftCodeRequest
+	(*CreateSwiftCodeResponse)(nil),         // 5: swift.v1.CreateSwiftCodeResponse
+	(*UpdateSwiftCodeRequest)(nil),          // 6: swift.v1.UpdateSwiftCodeRequest
+	(*UpdateSwiftCodeResponse)(nil),         // 7: swift.v1.UpdateSwiftCodeResponse
+	(*DeleteSwiftCodeRequest)(nil),          // 8: swift.v1.DeleteSwiftCodeRequest
+	(*DeleteSwiftCodeResponse)(nil),         // 9: swift.v1.DeleteSwiftCodeResponse
+	(*BatchGetSwiftCodesRequest)(nil),       // 10: swift.v1.BatchGetSwiftCodesRequest
+	(*BatchGetSwiftCodesResponse)(nil),      // 11: swift.v1.BatchGetSwiftCodesResponse
+	(*LookupResult)(nil),                    // 12: swift.v1.LookupResult
+	(*SearchInstitutionsRequest)(nil),       // 13: swift.v1.SearchInstitutionsRequest
+	(*SearchInstitutionsResponse)(nil),      // 14: swift.v1.SearchInstitutionsResponse
+	(*Institution)(nil),                     // 15: swift.v1.Institution
+	(*InstitutionHeadquarter)(nil),          // 16: swift.v1.InstitutionHeadquarter
+}
+var file_swift_v1_swift_proto_depIdxs = []int32{
+	0,  // 0: swift.v1.SwiftCode.branches:type_name -> swift.v1.SwiftCode
+	0,  // 1: swift.v1.ListSwiftCodesByCountryResponse.swift_codes:type_name -> swift.v1.SwiftCode
+	12, // 2: swift.v1.BatchGetSwiftCodesResponse.results:type_name -> swift.v1.LookupResult
+	0,  // 3: swift.v1.LookupResult.details:type_name -> swift.v1.SwiftCode
+	15, // 4: swift.v1.SearchInstitutionsResponse.institutions:type_name -> swift.v1.Institution
+	16, // 5: swift.v1.Institution.headquarters:type_name -> swift.v1.InstitutionHeadquarter
+	1,  // 6: swift.v1.SwiftCodes.GetSwiftCode:input_type -> swift.v1.GetSwiftCodeRequest
+	2,  // 7: swift.v1.SwiftCodes.ListSwiftCodesByCountry:input_type -> swift.v1.ListSwiftCodesByCountryRequest
+	4,  // 8: swift.v1.SwiftCodes.CreateSwiftCode:input_type -> swift.v1.CreateSwiftCodeRequest
+	6,  // 9: swift.v1.SwiftCodes.UpdateSwiftCode:input_type -> swift.v1.UpdateSwiftCodeRequest
+	8,  // 10: swift.v1.SwiftCodes.DeleteSwiftCode:input_type -> swift.v1.DeleteSwiftCodeRequest
+	10, // 11: swift.v1.SwiftCodes.BatchGetSwiftCodes:input_type -> swift.v1.BatchGetSwiftCodesRequest
+	13, // 12: swift.v1.SwiftCodes.SearchInstitutions:input_type -> swift.v1.SearchInstitutionsRequest
+	0,  // 13: swift.v1.SwiftCodes.GetSwiftCode:output_type -> swift.v1.SwiftCode
+	3,  // 14: swift.v1.SwiftCodes.ListSwiftCodesByCountry:output_type -> swift.v1.ListSwiftCodesByCountryResponse
+	5,  // 15: swift.v1.SwiftCodes.CreateSwiftCode:output_type -> swift.v1.CreateSwiftCodeResponse
+	7,  // 16: swift.v1.SwiftCodes.UpdateSwiftCode:output_type -> swift.v1.UpdateSwiftCodeResponse
+	9,  // 17: swift.v1.SwiftCodes.DeleteSwiftCode:output_type -> swift.v1.DeleteSwiftCodeResponse
+	11, // 18: swift.v1.SwiftCodes.BatchGetSwiftCodes:output_type -> swift.v1.BatchGetSwiftCodesResponse
+	14, // 19: swift.v1.SwiftCodes.SearchInstitutions:output_type -> swift.v1.SearchInstitutionsResponse
+	13, // [13:20] is the sub-list for method output_type
+	6,  // [6:13] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_swift_v1_swift_proto_init() }
+func file_swift_v1_swift_proto_init() {
+	if File_swift_v1_swift_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_swift_v1_swift_proto_rawDesc), len(file_swift_v1_swift_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_swift_v1_swift_proto_goTypes,
+		DependencyIndexes: file_swift_v1_swift_proto_depIdxs,
+		MessageInfos:      file_swift_v1_swift_proto_msgTypes,
+	}.Build()
+	File_swift_v1_swift_proto = out.File
+	file_swift_v1_swift_proto_goTypes = nil
+	file_swift_v1_swift_proto_depIdxs = nil
+}
@@ -0,0 +1,375 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: swift/v1/swift.proto
+
+package swiftv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SwiftCodes_GetSwiftCode_FullMethodName            = "/swift.v1.SwiftCodes/GetSwiftCode"
+	SwiftCodes_ListSwiftCodesByCountry_FullMethodName = "/swift.v1.SwiftCodes/ListSwiftCodesByCountry"
+	SwiftCodes_CreateSwiftCode_FullMethodName         = "/swift.v1.SwiftCodes/CreateSwiftCode"
+	SwiftCodes_UpdateSwiftCode_FullMethodName         = "/swift.v1.SwiftCodes/UpdateSwiftCode"
+	SwiftCodes_DeleteSwiftCode_FullMethodName         = "/swift.v1.SwiftCodes/DeleteSwiftCode"
+	SwiftCodes_BatchGetSwiftCodes_FullMethodName      = "/swift.v1.SwiftCodes/BatchGetSwiftCodes"
+	SwiftCodes_SearchInstitutions_FullMethodName      = "/swift.v1.SwiftCodes/SearchInstitutions"
+)
+
+// SwiftCodesClient is the client API for SwiftCodes service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SwiftCodes mirrors the REST API. Failures use the status codes listed in
+// the README, mapped from the same errors the REST API answers with.
+type SwiftCodesClient interface {
+	// GetSwiftCode returns a code. A headquarter embeds its branches unless
+	// skip_branches is set.
+	GetSwiftCode(ctx context.Context, in *GetSwiftCodeRequest, opts ...grpc.CallOption) (*SwiftCode, error)
+	// ListSwiftCodesByCountry returns every code of a country.
+	ListSwiftCodesByCountry(ctx context.Context, in *ListSwiftCodesByCountryRequest, opts ...grpc.CallOption) (*ListSwiftCodesByCountryResponse, error)
+	// CreateSwiftCode adds a code. A branch whose headquarter is unknown gets a
+	// placeholder headquarter.
+	CreateSwiftCode(ctx context.Context, in *CreateSwiftCodeRequest, opts ...grpc.CallOption) (*CreateSwiftCodeResponse, error)
+	// UpdateSwiftCode replaces the bank name, address and country of a code,
+	// keeping its town and timezone. Updating a placeholder promotes it.
+	UpdateSwiftCode(ctx context.Context, in *UpdateSwiftCodeRequest, opts ...grpc.CallOption) (*UpdateSwiftCodeResponse, error)
+	// DeleteSwiftCode removes a branch, or a headquarter without branches.
+	DeleteSwiftCode(ctx context.Context, in *DeleteSwiftCodeRequest, opts ...grpc.CallOption) (*DeleteSwiftCodeResponse, error)
+	// BatchGetSwiftCodes resolves up to 1000 BIC8 or BIC11 codes at once.
+	BatchGetSwiftCodes(ctx context.Context, in *BatchGetSwiftCodesRequest, opts ...grpc.CallOption) (*BatchGetSwiftCodesResponse, error)
+	// SearchInstitutions pages through institutions by code prefix or name.
+	SearchInstitutions(ctx context.Context, in *SearchInstitutionsRequest, opts ...grpc.CallOption) (*SearchInstitutionsResponse, error)
+}
+
+type swiftCodesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSwiftCodesClient(cc grpc.ClientConnInterface) SwiftCodesClient {
+	return &swiftCodesClient{cc}
+}
+
+func (c *swiftCodesClient) GetSwiftCode(ctx context.Context, in *GetSwiftCodeRequest, opts ...grpc.CallOption) (*SwiftCode, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SwiftCode)
+	err := c.cc.Invoke(ctx, SwiftCodes_GetSwiftCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) ListSwiftCodesByCountry(ctx context.Context, in *ListSwiftCodesByCountryRequest, opts ...grpc.CallOption) (*ListSwiftCodesByCountryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSwiftCodesByCountryResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_ListSwiftCodesByCountry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) CreateSwiftCode(ctx context.Context, in *CreateSwiftCodeRequest, opts ...grpc.CallOption) (*CreateSwiftCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSwiftCodeResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_CreateSwiftCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) UpdateSwiftCode(ctx context.Context, in *UpdateSwiftCodeRequest, opts ...grpc.CallOption) (*UpdateSwiftCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateSwiftCodeResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_UpdateSwiftCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) DeleteSwiftCode(ctx context.Context, in *DeleteSwiftCodeRequest, opts ...grpc.CallOption) (*DeleteSwiftCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSwiftCodeResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_DeleteSwiftCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) BatchGetSwiftCodes(ctx context.Context, in *BatchGetSwiftCodesRequest, opts ...grpc.CallOption) (*BatchGetSwiftCodesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetSwiftCodesResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_BatchGetSwiftCodes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) SearchInstitutions(ctx context.Context, in *SearchInstitutionsRequest, opts ...grpc.CallOption) (*SearchInstitutionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchInstitutionsResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_SearchInstitutions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SwiftCodesServer is the server API for SwiftCodes service.
+// All implementations must embed UnimplementedSwiftCodesServer
+// for forward compatibility.
+//
+// SwiftCodes mirrors the REST API. Failures use the status codes listed in
+// the README, mapped from the same errors the REST API answers with.
+type SwiftCodesServer interface {
+	// GetSwiftCode returns a code. A headquarter embeds its branches unless
+	// skip_branches is set.
+	GetSwiftCode(context.Context, *GetSwiftCodeRequest) (*SwiftCode, error)
+	// ListSwiftCodesByCountry returns every code of a country.
+	ListSwiftCodesByCountry(context.Context, *ListSwiftCodesByCountryRequest) (*ListSwiftCodesByCountryResponse, error)
+	// CreateSwiftCode adds a code. A branch whose headquarter is unknown gets a
+	// placeholder headquarter.
+	CreateSwiftCode(context.Context, *CreateSwiftCodeRequest) (*CreateSwiftCodeResponse, error)
+	// UpdateSwiftCode replaces the bank name, address and country of a code,
+	// keeping its town and timezone. Updating a placeholder promotes it.
+	UpdateSwiftCode(context.Context, *UpdateSwiftCodeRequest) (*UpdateSwiftCodeResponse, error)
+	// DeleteSwiftCode removes a branch, or a headquarter without branches.
+	DeleteSwiftCode(context.Context, *DeleteSwiftCodeRequest) (*DeleteSwiftCodeResponse, error)
+	// BatchGetSwiftCodes resolves up to 1000 BIC8 or BIC11 codes at once.
+	BatchGetSwiftCodes(context.Context, *BatchGetSwiftCodesRequest) (*BatchGetSwiftCodesResponse, error)
+	// SearchInstitutions pages through institutions by code prefix or name.
+	SearchInstitutions(context.Context, *SearchInstitutionsRequest) (*SearchInstitutionsResponse, error)
+	mustEmbedUnimplementedSwiftCodesServer()
+}
+
+// UnimplementedSwiftCodesServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSwiftCodesServer struct{}
+
+func (UnimplementedSwiftCodesServer) GetSwiftCode(context.Context, *GetSwiftCodeRequest) (*SwiftCode, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSwiftCode not implemented")
+}
+func (UnimplementedSwiftCodesServer) ListSwiftCodesByCountry(context.Context, *ListSwiftCodesByCountryRequest) (*ListSwiftCodesByCountryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSwiftCodesByCountry not implemented")
+}
+func (UnimplementedSwiftCodesServer) CreateSwiftCode(context.Context, *CreateSwiftCodeRequest) (*CreateSwiftCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSwiftCode not implemented")
+}
+func (UnimplementedSwiftCodesServer) UpdateSwiftCode(context.Context, *UpdateSwiftCodeRequest) (*UpdateSwiftCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSwiftCode not implemented")
+}
+func (UnimplementedSwiftCodesServer) DeleteSwiftCode(context.Context, *DeleteSwiftCodeRequest) (*DeleteSwiftCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSwiftCode not implemented")
+}
+func (UnimplementedSwiftCodesServer) BatchGetSwiftCodes(context.Context, *BatchGetSwiftCodesRequest) (*BatchGetSwiftCodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetSwiftCodes not implemented")
+}
+func (UnimplementedSwiftCodesServer) SearchInstitutions(context.Context, *SearchInstitutionsRequest) (*SearchInstitutionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchInstitutions not implemented")
+}
+func (UnimplementedSwiftCodesServer) mustEmbedUnimplementedSwiftCodesServer() {}
+func (UnimplementedSwiftCodesServer) testEmbeddedByValue()                    {}
+
+// UnsafeSwiftCodesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SwiftCodesServer will
+// result in compilation errors.
+type UnsafeSwiftCodesServer interface {
+	mustEmbedUnimplementedSwiftCodesServer()
+}
+
+func RegisterSwiftCodesServer(s grpc.ServiceRegistrar, srv SwiftCodesServer) {
+	// If the following call pancis, it indicates UnimplementedSwiftCodesServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SwiftCodes_ServiceDesc, srv)
+}
+
+func _SwiftCodes_GetSwiftCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSwiftCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).GetSwiftCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_GetSwiftCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).GetSwiftCode(ctx, req.(*GetSwiftCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_ListSwiftCodesByCountry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSwiftCodesByCountryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).ListSwiftCodesByCountry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_ListSwiftCodesByCountry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).ListSwiftCodesByCountry(ctx, req.(*ListSwiftCodesByCountryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_CreateSwiftCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSwiftCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).CreateSwiftCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_CreateSwiftCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).CreateSwiftCode(ctx, req.(*CreateSwiftCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_UpdateSwiftCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSwiftCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).UpdateSwiftCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_UpdateSwiftCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).UpdateSwiftCode(ctx, req.(*UpdateSwiftCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_DeleteSwiftCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSwiftCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).DeleteSwiftCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_DeleteSwiftCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).DeleteSwiftCode(ctx, req.(*DeleteSwiftCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_BatchGetSwiftCodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetSwiftCodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).BatchGetSwiftCodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_BatchGetSwiftCodes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).BatchGetSwiftCodes(ctx, req.(*BatchGetSwiftCodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_SearchInstitutions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchInstitutionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).SearchInstitutions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_SearchInstitutions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).SearchInstitutions(ctx, req.(*SearchInstitutionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SwiftCodes_ServiceDesc is the grpc.ServiceDesc for SwiftCodes service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SwiftCodes_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "swift.v1.SwiftCodes",
+	HandlerType: (*SwiftCodesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSwiftCode",
+			Handler:    _SwiftCodes_GetSwiftCode_Handler,
+		},
+		{
+			MethodName: "ListSwiftCodesByCountry",
+			Handler:    _SwiftCodes_ListSwiftCodesByCountry_Handler,
+		},
+		{
+			MethodName: "CreateSwiftCode",
+			Handler:    _SwiftCodes_CreateSwiftCode_Handler,
+		},
+		{
+			MethodName: "UpdateSwiftCode",
+			Handler:    _SwiftCodes_UpdateSwiftCode_Handler,
+		},
+		{
+			MethodName: "DeleteSwiftCode",
+			Handler:    _SwiftCodes_DeleteSwiftCode_Handler,
+		},
+		{
+			MethodName: "BatchGetSwiftCodes",
+			Handler:    _SwiftCodes_BatchGetSwiftCodes_Handler,
+		},
+		{
+			MethodName: "SearchInstitutions",
+			Handler:    _SwiftCodes_SearchInstitutions_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "swift/v1/swift.proto",
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"net/http"
+	"swift-api/pkg/auth"
+	"time"
+)
+
+type APIKeyHandler struct {
+	Keys auth.KeyStore
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type APIKeySecretResponse struct {
+	auth.APIKey
+	Key string `json:"key"`
+}
+
+func NewAPIKeyHandler(keys auth.KeyStore) *APIKeyHandler {
+	return &APIKeyHandler{Keys: keys}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if len(req.Scopes) == 0 {
+		writeError(w, http.StatusBadRequest, "scopes must not be empty")
+		return
+	}
+	scopes := make([]auth.Role, 0, len(req.Scopes))
+	for _, s := range req.Scopes {
+		role, ok := auth.ParseRole(s)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "unknown scope: "+s)
+			return
+		}
+		scopes = append(scopes, role)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		writeError(w, http.StatusBadRequest, "expiresAt must be in the future")
+		return
+	}
+
+	plaintext, key, err := auth.GenerateAPIKey(req.Name, scopes, req.ExpiresAt)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to generate API key")
+		return
+	}
+	if err := h.Keys.SaveAPIKey(key); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to store API key")
+		return
+	}
+
+	stored, err := h.Keys.GetAPIKey(key.ID)
+	if err != nil || stored == nil {
+		writeError(w, http.StatusInternalServerError, "Error retrieving API key")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, APIKeySecretResponse{APIKey: *stored, Key: plaintext})
+}
+
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.Keys.ListAPIKeys()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error retrieving API keys")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]auth.APIKey{"apiKeys": keys})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	revoked, err := h.Keys.RevokeAPIKey(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error revoking API key")
+		return
+	}
+	if !revoked {
+		writeError(w, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	writeSuccess(w, "API key revoked successfully")
+}
+
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	plaintext, secret, err := auth.RegenerateSecret(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to generate API key")
+		return
+	}
+
+	rotated, err := h.Keys.RotateAPIKey(id, secret.Salt, secret.KeyHash)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error rotating API key")
+		return
+	}
+	if !rotated {
+		writeError(w, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	stored, err := h.Keys.GetAPIKey(id)
+	if err != nil || stored == nil {
+		writeError(w, http.StatusInternalServerError, "Error retrieving API key")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIKeySecretResponse{APIKey: *stored, Key: plaintext})
+}
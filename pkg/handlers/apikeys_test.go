@@ -0,0 +1,146 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/handlers"
+	"testing"
+	"time"
+)
+
+type memoryKeyStore map[string]auth.APIKey
+
+func (s memoryKeyStore) GetAPIKey(id string) (*auth.APIKey, error) {
+	key, ok := s[id]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+func (s memoryKeyStore) ListAPIKeys() ([]auth.APIKey, error) {
+	keys := []auth.APIKey{}
+	for _, k := range s {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (s memoryKeyStore) SaveAPIKey(key auth.APIKey) error {
+	key.CreatedAt = time.Now()
+	s[key.ID] = key
+	return nil
+}
+
+func (s memoryKeyStore) RevokeAPIKey(id string) (bool, error) {
+	key, ok := s[id]
+	if !ok || key.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	s[id] = key
+	return true, nil
+}
+
+func (s memoryKeyStore) RotateAPIKey(id, salt, keyHash string) (bool, error) {
+	key, ok := s[id]
+	if !ok || key.RevokedAt != nil {
+		return false, nil
+	}
+	key.Salt, key.KeyHash = salt, keyHash
+	s[id] = key
+	return true, nil
+}
+
+func (s memoryKeyStore) RecordUsage(map[string]auth.Usage) error {
+	return nil
+}
+
+func TestAPIKeyManagement(t *testing.T) {
+	store := memoryKeyStore{}
+	h := handlers.NewAPIKeyHandler(store)
+
+	var created handlers.APIKeySecretResponse
+
+	t.Run("Create key", func(t *testing.T) {
+		body := `{"name":"batch-job","scopes":["reader","editor"]}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/api-keys", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.CreateAPIKey(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+		assert.True(t, strings.HasPrefix(created.Key, "swk_"+created.ID+"_"))
+		assert.Equal(t, []auth.Role{auth.RoleReader, auth.RoleEditor}, created.Scopes)
+		assert.NotContains(t, rec.Body.String(), store[created.ID].KeyHash)
+	})
+
+	t.Run("Create key with unknown scope", func(t *testing.T) {
+		body := `{"name":"bad","scopes":["root"]}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/api-keys", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.CreateAPIKey(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unknown scope: root")
+	})
+
+	t.Run("Create key already expired", func(t *testing.T) {
+		body := `{"name":"old","scopes":["reader"],"expiresAt":"2000-01-01T00:00:00Z"}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/api-keys", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.CreateAPIKey(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("List keys hides secrets", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/api-keys", nil)
+		rec := httptest.NewRecorder()
+		h.ListAPIKeys(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), created.ID)
+		assert.NotContains(t, rec.Body.String(), created.Key)
+		assert.NotContains(t, rec.Body.String(), "salt")
+	})
+
+	t.Run("Rotate key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/api-keys/"+created.ID+":rotate", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": created.ID})
+		rec := httptest.NewRecorder()
+		h.RotateAPIKey(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var rotated handlers.APIKeySecretResponse
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&rotated))
+		assert.Equal(t, created.ID, rotated.ID)
+		assert.NotEqual(t, created.Key, rotated.Key)
+	})
+
+	t.Run("Revoke key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/v1/admin/api-keys/"+created.ID, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": created.ID})
+		rec := httptest.NewRecorder()
+		h.RevokeAPIKey(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = httptest.NewRecorder()
+		h.RevokeAPIKey(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Rotate revoked key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/api-keys/"+created.ID+":rotate", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": created.ID})
+		rec := httptest.NewRecorder()
+		h.RotateAPIKey(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
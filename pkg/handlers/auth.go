@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"swift-api/pkg/auth"
+)
+
+// Authenticate resolves the caller with authn and stores the principal in
+// the request context. Requests without credentials pass through
+// anonymously; RequireRole decides whether the route accepts them.
+func Authenticate(authn auth.Authenticator) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := authn.Authenticate(auth.RequestCredentials(r))
+			switch {
+			case err == nil:
+				r = r.WithContext(auth.NewContext(r.Context(), p))
+			case errors.Is(err, auth.ErrNoCredentials):
+			case errors.Is(err, auth.ErrInvalidCredentials):
+				w.Header().Set("WWW-Authenticate", `Bearer realm="swift-api"`)
+				writeError(w, http.StatusUnauthorized, "Invalid credentials")
+				return
+			default:
+				writeError(w, http.StatusInternalServerError, "Error verifying credentials")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func RequireRole(role auth.Role) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := auth.FromContext(r.Context())
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="swift-api"`)
+				writeError(w, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+			if !p.HasRole(role) {
+				writeError(w, http.StatusForbidden, "Insufficient role, "+string(role)+" required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
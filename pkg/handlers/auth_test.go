@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/handlers"
+	"testing"
+)
+
+type stubAuthenticator map[string]*auth.Principal
+
+func (s stubAuthenticator) Authenticate(c auth.Credentials) (*auth.Principal, error) {
+	key := c.APIKey
+	if key == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	p, ok := s[key]
+	if !ok {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return p, nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	authn := stubAuthenticator{
+		"reader-key": {Subject: "r", Roles: []auth.Role{auth.RoleReader}},
+		"editor-key": {Subject: "e", Roles: []auth.Role{auth.RoleEditor}},
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	protected := handlers.Authenticate(authn)(handlers.RequireRole(auth.RoleEditor)(ok))
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+		body   string
+	}{
+		{name: "Anonymous", key: "", status: http.StatusUnauthorized, body: "Authentication required"},
+		{name: "Invalid key", key: "bogus", status: http.StatusUnauthorized, body: "Invalid credentials"},
+		{name: "Insufficient role", key: "reader-key", status: http.StatusForbidden, body: "editor required"},
+		{name: "Sufficient role", key: "editor-key", status: http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/swift-codes", nil)
+			if tc.key != "" {
+				req.Header.Set("X-API-Key", tc.key)
+			}
+			rec := httptest.NewRecorder()
+			protected.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.body != "" {
+				assert.Contains(t, rec.Body.String(), `"message":`)
+				assert.Contains(t, rec.Body.String(), tc.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"swift-api/pkg/repository"
+	"swift-api/pkg/service"
+)
+
+type BranchPageItem struct {
+	Address       string `json:"address"`
+	BankName      string `json:"bankName"`
+	CountryISO2   string `json:"countryISO2"`
+	IsHeadquarter bool   `json:"isHeadquarter"`
+	SwiftCode     string `json:"swiftCode"`
+	TownName      string `json:"townName"`
+}
+
+// CountryHeadquartersResponse lists a country's headquarters, each with
+// every one of its branches.
+type CountryHeadquartersResponse struct {
+	CountryISO2  string                `json:"countryISO2"`
+	CountryName  string                `json:"countryName"`
+	Headquarters []HeadquarterResponse `json:"headquarters"`
+}
+
+// BranchPageResponse is one page of a headquarter's branches. Total counts
+// every branch matching the town filter.
+type BranchPageResponse struct {
+	HeadquarterSwiftCode string           `json:"headquarterSwiftCode"`
+	Town                 string           `json:"town,omitempty"`
+	Total                int              `json:"total"`
+	Limit                int              `json:"limit"`
+	Offset               int              `json:"offset"`
+	Branches             []BranchPageItem `json:"branches"`
+}
+
+type BankCountryResponse struct {
+	CountryISO2 string               `json:"countryISO2"`
+	CountryName string               `json:"countryName"`
+	SwiftCodes  []BranchInHQResponse `json:"swiftCodes"`
+}
+
+// BankResponse groups by country every code of an institution, the first
+// four characters of a BIC.
+type BankResponse struct {
+	InstitutionCode string                `json:"institutionCode"`
+	Total           int                   `json:"total"`
+	Countries       []BankCountryResponse `json:"countries"`
+}
+
+// intParam parses an optional integer query parameter within [min, max].
+func intParam(query url.Values, name string, def, min, max int) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s must be an integer between %d and %d", name, min, max)
+	}
+	return n, nil
+}
+
+func (h *Handler) ListBranches(w http.ResponseWriter, r *http.Request) {
+	swiftCode := mux.Vars(r)["swift-code"]
+	query := r.URL.Query()
+	limit, err := intParam(query, "limit", service.DefaultBranchLimit, 1, service.MaxBranchLimit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := intParam(query, "offset", 0, 0, math.MaxInt32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	town := strings.TrimSpace(query.Get("town"))
+
+	branches, total, err := h.service().ListBranches(r.Context(), swiftCode, repository.BranchQuery{Town: town, Limit: limit, Offset: offset})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := BranchPageResponse{
+		HeadquarterSwiftCode: swiftCode,
+		Town:                 town,
+		Total:                total,
+		Limit:                limit,
+		Offset:               offset,
+		Branches:             []BranchPageItem{},
+	}
+	for _, b := range branches {
+		resp.Branches = append(resp.Branches, BranchPageItem{
+			Address:       strOrEmpty(b.Address),
+			BankName:      b.BankName,
+			CountryISO2:   b.CountryISO2,
+			IsHeadquarter: b.IsHeadquarter,
+			SwiftCode:     b.SwiftCode,
+			TownName:      b.TownName,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) GetBank(w http.ResponseWriter, r *http.Request) {
+	institutionCode := strings.ToUpper(mux.Vars(r)["institutionCode"])
+	codes, err := h.service().ListByInstitution(r.Context(), institutionCode)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	byCountry := make(map[string]*BankCountryResponse)
+	for _, code := range codes {
+		country, ok := byCountry[code.CountryISO2]
+		if !ok {
+			country = &BankCountryResponse{CountryISO2: code.CountryISO2, CountryName: code.CountryName}
+			byCountry[code.CountryISO2] = country
+		}
+		country.SwiftCodes = append(country.SwiftCodes, newBranchInHQ(code))
+	}
+
+	resp := BankResponse{InstitutionCode: institutionCode, Total: len(codes)}
+	for _, country := range byCountry {
+		resp.Countries = append(resp.Countries, *country)
+	}
+	sort.Slice(resp.Countries, func(i, j int) bool { return resp.Countries[i].CountryISO2 < resp.Countries[j].CountryISO2 })
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetHeadquartersByCountry reads the headquarters of a country and their
+// branches with one query.
+func (h *Handler) GetHeadquartersByCountry(w http.ResponseWriter, r *http.Request) {
+	iso2 := strings.ToUpper(mux.Vars(r)["countryISO2code"])
+	headquarters, countryName, err := h.service().ListHeadquartersByCountry(r.Context(), iso2)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := CountryHeadquartersResponse{
+		CountryISO2:  iso2,
+		CountryName:  countryName,
+		Headquarters: make([]HeadquarterResponse, 0, len(headquarters)),
+	}
+	for _, hq := range headquarters {
+		item := newHeadquarter(hq, hq.Branches)
+		if item.Branches == nil {
+			item.Branches = []BranchInHQResponse{}
+		}
+		resp.Headquarters = append(resp.Headquarters, item)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,164 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"swift-api/pkg/handlers"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"testing"
+)
+
+func setupBranchesHandler(t *testing.T) *handlers.Handler {
+	hqCode := "PAGEPLPWXXX"
+	codes := []models.SwiftCode{
+		{SwiftCode: hqCode, BankName: "PAGE BANK", TownName: "WARSZAWA", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+		{SwiftCode: "PAGEDEFFXXX", BankName: "PAGE BANK AG", TownName: "FRANKFURT", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+	}
+	for i, town := range []string{"KRAKOW", "WARSZAWA", "KRAKOW", "GDANSK", "KRAKOW"} {
+		codes = append(codes, models.SwiftCode{
+			SwiftCode: fmt.Sprintf("PAGEPLPW%03d", i), BankName: "PAGE BANK", TownName: town,
+			CountryISO2: "PL", CountryName: "POLAND", HeadquarterSWIFTCode: &hqCode,
+		})
+	}
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.InsertSwiftCodes(context.Background(), codes))
+	return &handlers.Handler{Repo: repo}
+}
+
+func getWithQuery(h http.HandlerFunc, target string, vars map[string]string) *httptest.ResponseRecorder {
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, target, nil), vars)
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec
+}
+
+func TestListBranches(t *testing.T) {
+	h := setupBranchesHandler(t)
+	vars := map[string]string{"swift-code": "PAGEPLPWXXX"}
+
+	page := func(t *testing.T, query string) handlers.BranchPageResponse {
+		t.Helper()
+		rec := getWithQuery(h.ListBranches, "/v1/swift-codes/PAGEPLPWXXX/branches"+query, vars)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp handlers.BranchPageResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+	codes := func(resp handlers.BranchPageResponse) []string {
+		var out []string
+		for _, b := range resp.Branches {
+			out = append(out, b.SwiftCode)
+		}
+		return out
+	}
+
+	t.Run("Defaults return every branch", func(t *testing.T) {
+		resp := page(t, "")
+		assert.Equal(t, 5, resp.Total)
+		assert.Equal(t, 50, resp.Limit)
+		assert.Equal(t, 0, resp.Offset)
+		assert.Equal(t, []string{"PAGEPLPW000", "PAGEPLPW001", "PAGEPLPW002", "PAGEPLPW003", "PAGEPLPW004"}, codes(resp))
+		assert.Equal(t, "KRAKOW", resp.Branches[0].TownName)
+	})
+
+	t.Run("Limit and offset page through the branches", func(t *testing.T) {
+		resp := page(t, "?limit=2&offset=2")
+		assert.Equal(t, 5, resp.Total)
+		assert.Equal(t, []string{"PAGEPLPW002", "PAGEPLPW003"}, codes(resp))
+
+		resp = page(t, "?limit=2&offset=4")
+		assert.Equal(t, []string{"PAGEPLPW004"}, codes(resp))
+	})
+
+	t.Run("Town filters the page and the total", func(t *testing.T) {
+		resp := page(t, "?town=krakow&limit=2&offset=1")
+		assert.Equal(t, 3, resp.Total)
+		assert.Equal(t, "krakow", resp.Town)
+		assert.Equal(t, []string{"PAGEPLPW002", "PAGEPLPW004"}, codes(resp))
+	})
+
+	t.Run("Past the end is an empty page", func(t *testing.T) {
+		rec := getWithQuery(h.ListBranches, "/v1/swift-codes/PAGEPLPWXXX/branches?offset=9", vars)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"branches":[]`)
+		assert.Contains(t, rec.Body.String(), `"total":5`)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		for _, query := range []string{"?limit=0", "?limit=501", "?limit=x", "?offset=-1"} {
+			rec := getWithQuery(h.ListBranches, "/v1/swift-codes/PAGEPLPWXXX/branches"+query, vars)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+
+		rec := getWithQuery(h.ListBranches, "/", map[string]string{"swift-code": "PAGEPLPW001"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "not a headquarter")
+
+		assert.Equal(t, http.StatusBadRequest, getWithQuery(h.ListBranches, "/", map[string]string{"swift-code": "SHORT"}).Code)
+		assert.Equal(t, http.StatusNotFound, getWithQuery(h.ListBranches, "/", map[string]string{"swift-code": "MISSINGGXXX"}).Code)
+	})
+}
+
+func TestGetBank(t *testing.T) {
+	h := setupBranchesHandler(t)
+
+	t.Run("Codes are grouped by country", func(t *testing.T) {
+		rec := getWithQuery(h.GetBank, "/v1/banks/page", map[string]string{"institutionCode": "page"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp handlers.BankResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "PAGE", resp.InstitutionCode)
+		assert.Equal(t, 7, resp.Total)
+		require.Len(t, resp.Countries, 2)
+		assert.Equal(t, "DE", resp.Countries[0].CountryISO2)
+		assert.Equal(t, "GERMANY", resp.Countries[0].CountryName)
+		assert.Len(t, resp.Countries[0].SwiftCodes, 1)
+		assert.Equal(t, "PL", resp.Countries[1].CountryISO2)
+		assert.Len(t, resp.Countries[1].SwiftCodes, 6)
+		assert.Equal(t, "PAGEPLPW000", resp.Countries[1].SwiftCodes[0].SwiftCode)
+	})
+
+	t.Run("Invalid or unknown institution", func(t *testing.T) {
+		for _, code := range []string{"PAG", "PAGES", "PA-E"} {
+			assert.Equal(t, http.StatusBadRequest, getWithQuery(h.GetBank, "/", map[string]string{"institutionCode": code}).Code, code)
+		}
+		assert.Equal(t, http.StatusNotFound, getWithQuery(h.GetBank, "/", map[string]string{"institutionCode": "NONE"}).Code)
+	})
+}
+
+func TestGetHeadquartersByCountry(t *testing.T) {
+	h := setupBranchesHandler(t)
+
+	t.Run("Headquarters embed every branch", func(t *testing.T) {
+		rec := getWithQuery(h.GetHeadquartersByCountry, "/v1/swift-codes/country/pl/headquarters", map[string]string{"countryISO2code": "pl"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp handlers.CountryHeadquartersResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "PL", resp.CountryISO2)
+		assert.Equal(t, "POLAND", resp.CountryName)
+		require.Len(t, resp.Headquarters, 1)
+		assert.Equal(t, "PAGEPLPWXXX", resp.Headquarters[0].SwiftCode)
+		require.Len(t, resp.Headquarters[0].Branches, 5)
+		assert.Equal(t, "PAGEPLPW000", resp.Headquarters[0].Branches[0].SwiftCode)
+	})
+
+	t.Run("Headquarters without branches have an empty list", func(t *testing.T) {
+		rec := getWithQuery(h.GetHeadquartersByCountry, "/", map[string]string{"countryISO2code": "DE"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"branches":[]`)
+	})
+
+	t.Run("Invalid or unknown country", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, getWithQuery(h.GetHeadquartersByCountry, "/", map[string]string{"countryISO2code": "POL"}).Code)
+		assert.Equal(t, http.StatusNotFound, getWithQuery(h.GetHeadquartersByCountry, "/", map[string]string{"countryISO2code": "XX"}).Code)
+	})
+}
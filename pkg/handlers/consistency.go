@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"strings"
+	"swift-api/pkg/repository"
+)
+
+// ReadYourWrites gives each request read-your-writes consistency when reads
+// are served by replicas. Requests with unsafe methods read from the primary
+// throughout, so the checks that precede a write see current data; any other
+// request is pinned to the primary once it has written. Queries sent as POST
+// because their input does not fit in a URL count as reads.
+func ReadYourWrites() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := repository.WithReadYourWrites(r.Context())
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if !isQuery(r) {
+					ctx = repository.PinPrimary(ctx)
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// isQuery reports whether r calls a read-only custom method such as
+// POST /v1/swift-codes:lookup, or POSTs a GraphQL query, the schema having
+// no mutations.
+func isQuery(r *http.Request) bool {
+	return r.Method == http.MethodPost && (strings.HasSuffix(r.URL.Path, ":lookup") || r.URL.Path == "/graphql")
+}
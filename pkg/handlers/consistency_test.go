@@ -0,0 +1,39 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"swift-api/pkg/handlers"
+	"swift-api/pkg/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	pinned := func(method, url string) bool {
+		var got bool
+		h := handlers.ReadYourWrites()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = repository.PinnedToPrimary(r.Context())
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, url, nil))
+		return got
+	}
+
+	t.Run("Reads are not pinned", func(t *testing.T) {
+		assert.False(t, pinned(http.MethodGet, "/v1/swift-codes/AAAAPLPWXXX"))
+	})
+
+	t.Run("Writes are pinned", func(t *testing.T) {
+		assert.True(t, pinned(http.MethodPost, "/v1/swift-codes"))
+		assert.True(t, pinned(http.MethodDelete, "/v1/swift-codes/AAAAPLPWXXX"))
+	})
+
+	t.Run("Batch lookups are reads", func(t *testing.T) {
+		assert.False(t, pinned(http.MethodPost, "/v1/swift-codes:lookup"))
+	})
+
+	t.Run("GraphQL queries are reads", func(t *testing.T) {
+		assert.False(t, pinned(http.MethodPost, "/graphql"))
+	})
+}
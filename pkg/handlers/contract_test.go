@@ -0,0 +1,358 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/events"
+	"swift-api/pkg/handlers"
+	"swift-api/pkg/health"
+	"swift-api/pkg/metrics"
+	"swift-api/pkg/openapi"
+	"swift-api/pkg/ratelimit"
+	"swift-api/pkg/repository"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contract sends requests through a router wired like cmd/main.go and
+// validates each request and response against the OpenAPI document.
+type contract struct {
+	t         *testing.T
+	validator *openapi.Validator
+	covered   map[openapi.Operation]bool
+	keys      map[auth.Role]string
+	ready     atomic.Bool
+	bus       *events.Bus
+	webhooks  *events.MemoryStore
+}
+
+var routeVar = regexp.MustCompile(`\{([^:}]+):[^}]+\}`)
+
+func newContract(t *testing.T) *contract {
+	v, err := openapi.NewValidator()
+	require.NoError(t, err)
+
+	c := &contract{t: t, validator: v, covered: map[openapi.Operation]bool{}, keys: map[auth.Role]string{},
+		bus: events.NewBus(100), webhooks: events.NewMemoryStore()}
+	c.ready.Store(true)
+	// Streams from a closed bus end once they have replayed what they missed.
+	c.bus.Close()
+	return c
+}
+
+func (c *contract) router(store auth.KeyStore, limiter ratelimit.Limiter) *mux.Router {
+	for _, role := range []auth.Role{auth.RoleReader, auth.RoleEditor, auth.RoleAdmin} {
+		if _, ok := c.keys[role]; ok {
+			continue
+		}
+		plaintext, key, err := auth.GenerateAPIKey(string(role), []auth.Role{role}, nil)
+		require.NoError(c.t, err)
+		require.NoError(c.t, store.SaveAPIKey(key))
+		c.keys[role] = plaintext
+	}
+
+	checker := health.NewChecker(time.Second)
+	checker.Add("database", func(context.Context) error {
+		if !c.ready.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	h := handlers.NewHandler(repository.NewMemoryRepository(), nil)
+	h.Events = c.bus
+	keys := handlers.NewAPIKeyHandler(store)
+	// The dispatcher is not run, so one redelivery fills its queue.
+	webhookConfig := events.DefaultWebhookConfig()
+	webhookConfig.MaxQueue = 1
+	feed := handlers.NewEventsHandler(c.bus, c.webhooks, events.NewDispatcher(c.webhooks, webhookConfig, nil), time.Second)
+	m := metrics.New()
+
+	protect := func(role auth.Role, next http.HandlerFunc) http.Handler {
+		if role == "" {
+			return next
+		}
+		return handlers.RequireRole(role)(next)
+	}
+	limit := handlers.RateLimit(limiter)
+
+	r := mux.NewRouter()
+	r.Use(handlers.RequestID())
+	r.Use(m.Middleware)
+	r.Use(handlers.Authenticate(auth.Chain{&auth.APIKeyAuthenticator{Store: store}}))
+	r.Use(handlers.ReadYourWrites())
+
+	r.Handle("/v1/swift-codes/{swift-code}", limit(protect("", h.GetSwiftCode))).Methods("GET")
+	r.Handle("/v1/swift-codes/country/{countryISO2code}", limit(protect("", h.GetSwiftCodesByCountry))).Methods("GET")
+	r.Handle("/v1/swift-codes/country/{countryISO2code}/headquarters", limit(protect("", h.GetHeadquartersByCountry))).Methods("GET")
+	r.Handle("/v1/swift-codes/{swift-code}/branches", limit(protect("", h.ListBranches))).Methods("GET")
+	r.Handle("/v1/banks/{institutionCode}", limit(protect("", h.GetBank))).Methods("GET")
+	r.Handle("/v1/institutions", limit(protect("", h.SearchInstitutions))).Methods("GET")
+	r.Handle("/v1/institutions/{code}", limit(protect("", h.GetInstitution))).Methods("GET")
+	r.Handle("/v1/swift-codes:lookup", limit(protect("", h.LookupSwiftCodes))).Methods("POST")
+	r.Handle("/v2/swift-codes/{swift-code}", limit(protect("", h.GetSwiftCodeV2))).Methods("GET")
+	r.Handle("/v2/swift-codes/country/{countryISO2code}", limit(protect("", h.GetSwiftCodesByCountryV2))).Methods("GET")
+	r.Handle("/v1/swift-codes", limit(protect(auth.RoleEditor, h.CreateSwiftCode))).Methods("POST")
+	r.Handle("/v1/swift-codes/{swift-code}", limit(protect(auth.RoleEditor, h.UpdateSwiftCode))).Methods("PUT")
+	r.Handle("/v1/swift-codes/{swift-code}", limit(protect(auth.RoleEditor, h.DeleteSwiftCode))).Methods("DELETE")
+	r.Handle("/v1/imports:diff", limit(protect(auth.RoleEditor, h.DiffImport))).Methods("POST")
+	r.Handle("/v1/admin/api-keys", limit(protect(auth.RoleAdmin, keys.CreateAPIKey))).Methods("POST")
+	r.Handle("/v1/admin/api-keys", limit(protect(auth.RoleAdmin, keys.ListAPIKeys))).Methods("GET")
+	r.Handle("/v1/admin/api-keys/{id:[0-9a-f]+}", limit(protect(auth.RoleAdmin, keys.RevokeAPIKey))).Methods("DELETE")
+	r.Handle("/v1/admin/api-keys/{id:[0-9a-f]+}:rotate", limit(protect(auth.RoleAdmin, keys.RotateAPIKey))).Methods("POST")
+	r.Handle("/v1/events", limit(protect("", feed.StreamEvents))).Methods("GET")
+	r.Handle("/v1/webhooks", limit(protect(auth.RoleAdmin, feed.CreateWebhook))).Methods("POST")
+	r.Handle("/v1/webhooks", limit(protect(auth.RoleAdmin, feed.ListWebhooks))).Methods("GET")
+	r.Handle("/v1/webhooks/{id:[0-9a-f]+}", limit(protect(auth.RoleAdmin, feed.DeleteWebhook))).Methods("DELETE")
+	r.Handle("/v1/webhooks/{id:[0-9a-f]+}/dead-letters", limit(protect(auth.RoleAdmin, feed.ListDeadLetters))).Methods("GET")
+	r.Handle("/v1/webhooks/{id:[0-9a-f]+}/dead-letters/{deadLetterId:[0-9]+}", limit(protect(auth.RoleAdmin, feed.DeleteDeadLetter))).Methods("DELETE")
+	r.Handle("/v1/webhooks/{id:[0-9a-f]+}/dead-letters/{deadLetterId:[0-9]+}:redeliver", limit(protect(auth.RoleAdmin, feed.RedeliverDeadLetter))).Methods("POST")
+	r.Handle("/v1/openapi.json", openapi.Handler()).Methods("GET")
+	r.Handle("/metrics", m.Handler()).Methods("GET")
+	r.HandleFunc("/healthz", checker.Liveness).Methods("GET")
+	r.HandleFunc("/readyz", checker.Readiness).Methods("GET")
+	return r
+}
+
+// do serves the request and fails the test unless the response, and the
+// request if it was accepted, match the specification.
+func (c *contract) do(r *mux.Router, role auth.Role, method, url, contentType string, body []byte) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	req := httptest.NewRequest(method, url, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if role != "" {
+		req.Header.Set("X-API-Key", c.keys[role])
+	}
+
+	var match mux.RouteMatch
+	require.True(c.t, r.Match(req, &match), "%s %s is not routed", method, url)
+	tmpl, err := match.Route.GetPathTemplate()
+	require.NoError(c.t, err)
+	op := openapi.Operation{Method: method, Path: routeVar.ReplaceAllString(tmpl, "{$1}")}
+	c.covered[op] = true
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	// Requests the server accepts must be valid under the spec; rejected
+	// ones are sent on purpose.
+	if contentType != "" && rec.Code < 300 {
+		assert.NoError(c.t, c.validator.ValidateRequest(op.Method, op.Path, contentType, body), "request %s %s", method, url)
+	}
+	assert.NoError(c.t, c.validator.ValidateResponse(op.Method, op.Path, rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()),
+		"response %s %s: %d %s", method, url, rec.Code, rec.Body.String())
+	return rec
+}
+
+func TestContract(t *testing.T) {
+	c := newContract(t)
+	store := memoryKeyStore{}
+	r := c.router(store, nil)
+
+	json := func(role auth.Role, method, url, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		if body == "" {
+			return c.do(r, role, method, url, "", nil)
+		}
+		return c.do(r, role, method, url, "application/json", []byte(body))
+	}
+
+	t.Run("SWIFT codes", func(t *testing.T) {
+		hq := `{"swiftCode":"CONTPLPWXXX","bankName":"HQ","countryISO2":"PL","countryName":"Poland","address":"HQ Addr","isHeadquarter":true}`
+		branch := `{"swiftCode":"CONTPLPW001","bankName":"Branch","countryISO2":"PL","countryName":"Poland","address":"Branch Addr","isHeadquarter":false}`
+
+		assert.Equal(t, http.StatusOK, json(auth.RoleEditor, "POST", "/v1/swift-codes", hq).Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/swift-codes/CONTPLPWXXX", "").Code, "headquarter without branches")
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v2/swift-codes/CONTPLPWXXX", "").Code, "headquarter without branches")
+		assert.Equal(t, http.StatusOK, json(auth.RoleEditor, "POST", "/v1/swift-codes", branch).Code)
+		assert.Equal(t, http.StatusConflict, json(auth.RoleEditor, "POST", "/v1/swift-codes", branch).Code)
+		assert.Equal(t, http.StatusBadRequest, json(auth.RoleEditor, "POST", "/v1/swift-codes", `{"swiftCode":"SHORT"}`).Code)
+		assert.Equal(t, http.StatusUnauthorized, json("", "POST", "/v1/swift-codes", hq).Code)
+		assert.Equal(t, http.StatusForbidden, json(auth.RoleReader, "POST", "/v1/swift-codes", hq).Code)
+
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/swift-codes/CONTPLPWXXX", "").Code)
+		assert.Equal(t, http.StatusOK, json(auth.RoleReader, "GET", "/v1/swift-codes/CONTPLPW001", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/swift-codes/SHORT", "").Code)
+		assert.Equal(t, http.StatusNotFound, json("", "GET", "/v1/swift-codes/MISSINGGXXX", "").Code)
+
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/swift-codes/country/pl", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/swift-codes/country/POL", "").Code)
+		assert.Equal(t, http.StatusNotFound, json("", "GET", "/v1/swift-codes/country/XX", "").Code)
+
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v2/swift-codes/CONTPLPWXXX", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v2/swift-codes/CONTPLPW001", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v2/swift-codes/SHORT", "").Code)
+		assert.Equal(t, http.StatusNotFound, json("", "GET", "/v2/swift-codes/MISSINGGXXX", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/swift-codes/CONTPLPWXXX?fields=bankName,branches&branchLimit=1", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/swift-codes/CONTPLPWXXX?embed=none", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/swift-codes/CONTPLPWXXX?fields=nope", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v2/swift-codes/CONTPLPWXXX?fields=townName,_links,branches&branchLimit=1", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v2/swift-codes/CONTPLPWXXX?embed=none", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v2/swift-codes/country/pl", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v2/swift-codes/country/POL", "").Code)
+		assert.Equal(t, http.StatusNotFound, json("", "GET", "/v2/swift-codes/country/XX", "").Code)
+
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/swift-codes/country/pl/headquarters", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/swift-codes/country/POL/headquarters", "").Code)
+		assert.Equal(t, http.StatusNotFound, json("", "GET", "/v1/swift-codes/country/XX/headquarters", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/swift-codes/CONTPLPWXXX/branches?limit=1&offset=0&town=warszawa", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/swift-codes/CONTPLPWXXX/branches?offset=10", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/swift-codes/CONTPLPWXXX/branches?limit=0", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/swift-codes/CONTPLPW001/branches", "").Code)
+		assert.Equal(t, http.StatusNotFound, json("", "GET", "/v1/swift-codes/MISSINGGXXX/branches", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/banks/cont", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/banks/CON", "").Code)
+		assert.Equal(t, http.StatusNotFound, json("", "GET", "/v1/banks/NONE", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/institutions/cont", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/institutions/CON", "").Code)
+		assert.Equal(t, http.StatusNotFound, json("", "GET", "/v1/institutions/NONE", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/institutions?q=cont&country=pl&limit=5&offset=0", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/institutions?q=nothing", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/institutions?limit=0", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "POST", "/v1/swift-codes:lookup", `{"swiftCodes":["contplpw","CONTPLPW001","MISSINGGXXX","BAD"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, json("", "POST", "/v1/swift-codes:lookup", `{"swiftCodes":[]}`).Code)
+
+		renamed := `{"swiftCode":"CONTPLPW001","bankName":"Renamed","countryISO2":"PL","countryName":"Poland","address":"Branch Addr","isHeadquarter":false}`
+		assert.Equal(t, http.StatusOK, json(auth.RoleEditor, "PUT", "/v1/swift-codes/CONTPLPW001", renamed).Code)
+		assert.Equal(t, http.StatusBadRequest, json(auth.RoleEditor, "PUT", "/v1/swift-codes/CONTPLPW002", renamed).Code)
+		assert.Equal(t, http.StatusNotFound, json(auth.RoleEditor, "PUT", "/v1/swift-codes/MISSINGGXXX", `{"swiftCode":"MISSINGGXXX","bankName":"HQ","countryISO2":"PL","countryName":"Poland","address":"HQ Addr","isHeadquarter":true}`).Code)
+		assert.Equal(t, http.StatusUnauthorized, json("", "PUT", "/v1/swift-codes/CONTPLPW001", renamed).Code)
+		assert.Equal(t, http.StatusForbidden, json(auth.RoleReader, "PUT", "/v1/swift-codes/CONTPLPW001", renamed).Code)
+
+		assert.Equal(t, http.StatusConflict, json(auth.RoleEditor, "DELETE", "/v1/swift-codes/CONTPLPWXXX", "").Code)
+		assert.Equal(t, http.StatusOK, json(auth.RoleEditor, "DELETE", "/v1/swift-codes/CONTPLPW001", "").Code)
+		assert.Equal(t, http.StatusNotFound, json(auth.RoleEditor, "DELETE", "/v1/swift-codes/CONTPLPW001", "").Code)
+		assert.Equal(t, http.StatusBadRequest, json(auth.RoleEditor, "DELETE", "/v1/swift-codes/SHORT", "").Code)
+	})
+
+	t.Run("Import diff", func(t *testing.T) {
+		csv := []byte("COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+			"PL,CONTPLPWXXX,BIC11,HQ,HQ Addr,WARSZAWA,POLAND,Europe/Warsaw\n" +
+			"DE,CONTDEFFXXX,BIC11,BANK,Addr,FRANKFURT,GERMANY,Europe/Berlin\n")
+		assert.Equal(t, http.StatusOK, c.do(r, auth.RoleEditor, "POST", "/v1/imports:diff", "text/csv", csv).Code)
+
+		var form bytes.Buffer
+		mw := multipart.NewWriter(&form)
+		fw, err := mw.CreateFormFile("file", "codes.csv")
+		require.NoError(t, err)
+		_, _ = fw.Write(csv)
+		require.NoError(t, mw.Close())
+		assert.Equal(t, http.StatusOK, c.do(r, auth.RoleEditor, "POST", "/v1/imports:diff", mw.FormDataContentType(), form.Bytes()).Code)
+
+		assert.Equal(t, http.StatusBadRequest, c.do(r, auth.RoleEditor, "POST", "/v1/imports:diff", "multipart/form-data; boundary=x", []byte("--x--")).Code)
+	})
+
+	t.Run("API keys", func(t *testing.T) {
+		rec := json(auth.RoleAdmin, "POST", "/v1/admin/api-keys", `{"name":"batch","scopes":["reader"],"expiresAt":"2999-01-01T00:00:00Z"}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		id := regexp.MustCompile(`"id":"([0-9a-f]+)"`).FindStringSubmatch(rec.Body.String())[1]
+
+		assert.Equal(t, http.StatusBadRequest, json(auth.RoleAdmin, "POST", "/v1/admin/api-keys", `{"name":"bad","scopes":[]}`).Code)
+		assert.Equal(t, http.StatusForbidden, json(auth.RoleEditor, "GET", "/v1/admin/api-keys", "").Code)
+
+		assert.Equal(t, http.StatusOK, json(auth.RoleAdmin, "POST", "/v1/admin/api-keys/"+id+":rotate", "").Code)
+		assert.Equal(t, http.StatusOK, json(auth.RoleAdmin, "DELETE", "/v1/admin/api-keys/"+id, "").Code)
+		assert.Equal(t, http.StatusNotFound, json(auth.RoleAdmin, "DELETE", "/v1/admin/api-keys/"+id, "").Code)
+		assert.Equal(t, http.StatusNotFound, json(auth.RoleAdmin, "POST", "/v1/admin/api-keys/"+id+":rotate", "").Code)
+		assert.Equal(t, http.StatusOK, json(auth.RoleAdmin, "GET", "/v1/admin/api-keys", "").Code, "lists the revoked key")
+
+		req := httptest.NewRequest("GET", "/v1/admin/api-keys", nil)
+		req.Header.Set("X-API-Key", "swk_bogus")
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.NoError(t, c.validator.ValidateResponse("GET", "/v1/admin/api-keys", rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()))
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		rec := json("", "GET", "/v1/events?lastEventId="+strconv.FormatUint(c.bus.LastID()-1, 10), "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "event: swift_code.deleted\n")
+		assert.Contains(t, json("", "GET", "/v1/events?lastEventId=1", "").Body.String(), "event: reset\n")
+		assert.Equal(t, http.StatusBadRequest, json("", "GET", "/v1/events?lastEventId=latest", "").Code)
+
+		rec = json(auth.RoleAdmin, "POST", "/v1/webhooks", `{"url":"https://cache.example.com/hook","types":["swift_code.created"]}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		id := regexp.MustCompile(`"id":"([0-9a-f]+)"`).FindStringSubmatch(rec.Body.String())[1]
+
+		assert.Equal(t, http.StatusBadRequest, json(auth.RoleAdmin, "POST", "/v1/webhooks", `{"url":"ftp://cache.example.com"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, json(auth.RoleAdmin, "POST", "/v1/webhooks", `{"url":"https://cache.example.com","types":["swift_code.renamed"]}`).Code)
+		assert.Equal(t, http.StatusForbidden, json(auth.RoleEditor, "GET", "/v1/webhooks", "").Code)
+		assert.Equal(t, http.StatusOK, json(auth.RoleAdmin, "GET", "/v1/webhooks", "").Code)
+
+		for range 2 {
+			require.NoError(t, c.webhooks.AddDeadLetter(context.Background(), events.DeadLetter{
+				WebhookID: id,
+				Event:     events.Event{ID: 1, Type: events.SwiftCodeCreated, Time: time.Now(), Source: events.SourceAPI, SwiftCode: "CONTPLPWXXX"},
+				Attempts:  5,
+				LastError: "webhook responded 500 Internal Server Error",
+			}))
+		}
+		assert.Equal(t, http.StatusOK, json(auth.RoleAdmin, "GET", "/v1/webhooks/"+id+"/dead-letters", "").Code)
+		assert.Equal(t, http.StatusNotFound, json(auth.RoleAdmin, "GET", "/v1/webhooks/ffff/dead-letters", "").Code)
+		assert.Equal(t, http.StatusAccepted, json(auth.RoleAdmin, "POST", "/v1/webhooks/"+id+"/dead-letters/1:redeliver", "").Code)
+		assert.Equal(t, http.StatusNotFound, json(auth.RoleAdmin, "POST", "/v1/webhooks/"+id+"/dead-letters/1:redeliver", "").Code)
+		assert.Equal(t, http.StatusServiceUnavailable, json(auth.RoleAdmin, "POST", "/v1/webhooks/"+id+"/dead-letters/2:redeliver", "").Code)
+		assert.Equal(t, http.StatusNotFound, json(auth.RoleAdmin, "DELETE", "/v1/webhooks/"+id+"/dead-letters/2", "").Code)
+		assert.Equal(t, http.StatusOK, json(auth.RoleAdmin, "DELETE", "/v1/webhooks/"+id+"/dead-letters/3", "").Code, "kept under a new ID")
+
+		assert.Equal(t, http.StatusOK, json(auth.RoleAdmin, "DELETE", "/v1/webhooks/"+id, "").Code)
+		assert.Equal(t, http.StatusNotFound, json(auth.RoleAdmin, "DELETE", "/v1/webhooks/"+id, "").Code)
+	})
+
+	t.Run("Operations", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, json("", "GET", "/v1/openapi.json", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/metrics", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/healthz", "").Code)
+		assert.Equal(t, http.StatusOK, json("", "GET", "/readyz", "").Code)
+		c.ready.Store(false)
+		assert.Equal(t, http.StatusServiceUnavailable, json("", "GET", "/readyz", "").Code)
+		c.ready.Store(true)
+	})
+
+	t.Run("Rate limited", func(t *testing.T) {
+		limited := c.router(store, ratelimit.NewMemoryLimiter(ratelimit.Policy{Rate: 0.001, Burst: 1}))
+		assert.Equal(t, http.StatusNotFound, c.do(limited, "", "GET", "/v1/swift-codes/MISSINGGXXX", "", nil).Code)
+		rec := c.do(limited, "", "GET", "/v1/swift-codes/MISSINGGXXX", "", nil)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("Every route is documented and exercised", func(t *testing.T) {
+		documented := map[openapi.Operation]bool{}
+		for _, op := range c.validator.Operations() {
+			documented[op] = true
+			assert.True(t, c.covered[op], "%s %s is not exercised", op.Method, op.Path)
+		}
+		require.NoError(t, r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			tmpl, err := route.GetPathTemplate()
+			if err != nil {
+				return err
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				return err
+			}
+			for _, method := range methods {
+				op := openapi.Operation{Method: method, Path: routeVar.ReplaceAllString(tmpl, "{$1}")}
+				assert.True(t, documented[op], "%s %s is not documented", op.Method, op.Path)
+			}
+			return nil
+		}))
+	})
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/url"
+	"strconv"
+	"swift-api/pkg/events"
+	"time"
+)
+
+// EventsHandler streams the change feed and manages the webhooks it is
+// delivered to.
+type EventsHandler struct {
+	Bus        *events.Bus
+	Webhooks   events.Store
+	Dispatcher *events.Dispatcher
+	Heartbeat  time.Duration
+}
+
+type CreateWebhookRequest struct {
+	URL   string   `json:"url"`
+	Types []string `json:"types"`
+}
+
+type WebhookSecretResponse struct {
+	events.Webhook
+	Secret string `json:"secret"`
+}
+
+func NewEventsHandler(bus *events.Bus, webhooks events.Store, dispatcher *events.Dispatcher, heartbeat time.Duration) *EventsHandler {
+	return &EventsHandler{Bus: bus, Webhooks: webhooks, Dispatcher: dispatcher, Heartbeat: heartbeat}
+}
+
+// StreamEvents sends events as Server-Sent Events until the client goes
+// away. A client reconnecting with Last-Event-ID first gets the events it
+// missed, or a reset event when they are no longer kept.
+func (h *EventsHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	var sub *events.Subscription
+	var missed []events.Event
+	complete := true
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Last-Event-ID must be an event ID")
+			return
+		}
+		sub, missed, complete = h.Bus.Resume(id)
+	} else {
+		sub = h.Bus.Subscribe()
+	}
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if !complete {
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {}\n\n", sub.From); err != nil {
+			return
+		}
+	}
+	for _, e := range missed {
+		if writeEvent(w, e) != nil {
+			return
+		}
+	}
+	if rc.Flush() != nil {
+		return
+	}
+
+	heartbeat := h.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = events.DefaultConfig().Heartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			err = writeEvent(w, e)
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": keepalive\n\n")
+		}
+		if err != nil || rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+func (h *EventsHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		writeError(w, http.StatusBadRequest, "url must be an absolute http or https URL")
+		return
+	}
+	types := make([]events.Type, 0, len(req.Types))
+	for _, s := range req.Types {
+		t, ok := events.ParseType(s)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "unknown event type: "+s)
+			return
+		}
+		types = append(types, t)
+	}
+
+	hook, err := events.NewWebhook(req.URL, types)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to generate webhook secret")
+		return
+	}
+	if err := h.Webhooks.SaveWebhook(r.Context(), hook); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to store webhook")
+		return
+	}
+
+	stored, err := h.Webhooks.GetWebhook(r.Context(), hook.ID)
+	if err != nil || stored == nil {
+		writeError(w, http.StatusInternalServerError, "Error retrieving webhook")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, WebhookSecretResponse{Webhook: *stored, Secret: stored.Secret})
+}
+
+func (h *EventsHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.Webhooks.ListWebhooks(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error retrieving webhooks")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]events.Webhook{"webhooks": hooks})
+}
+
+func (h *EventsHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	deleted, err := h.Webhooks.DeleteWebhook(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error deleting webhook")
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	writeSuccess(w, "Webhook deleted successfully")
+}
+
+// webhook returns the webhook named by the route, or answers 404.
+func (h *EventsHandler) webhook(w http.ResponseWriter, r *http.Request) (*events.Webhook, bool) {
+	hook, err := h.Webhooks.GetWebhook(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error retrieving webhook")
+		return nil, false
+	}
+	if hook == nil {
+		writeError(w, http.StatusNotFound, "Webhook not found")
+		return nil, false
+	}
+	return hook, true
+}
+
+func (h *EventsHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.webhook(w, r)
+	if !ok {
+		return
+	}
+
+	letters, err := h.Webhooks.ListDeadLetters(r.Context(), hook.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error retrieving dead letters")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]events.DeadLetter{"deadLetters": letters})
+}
+
+// RedeliverDeadLetter queues a dead letter's event for its webhook again.
+// Should that delivery fail too, or the queue be full, it comes back as a
+// new dead letter.
+func (h *EventsHandler) RedeliverDeadLetter(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.webhook(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(mux.Vars(r)["deadLetterId"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Dead letter not found")
+		return
+	}
+
+	letter, err := h.Webhooks.GetDeadLetter(r.Context(), hook.ID, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error retrieving dead letter")
+		return
+	}
+	if letter == nil {
+		writeError(w, http.StatusNotFound, "Dead letter not found")
+		return
+	}
+	deleted, err := h.Webhooks.DeleteDeadLetter(r.Context(), hook.ID, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error deleting dead letter")
+		return
+	}
+	if !deleted {
+		// Another request redelivered it first.
+		writeError(w, http.StatusNotFound, "Dead letter not found")
+		return
+	}
+
+	if !h.Dispatcher.Redeliver(*hook, letter.Event) {
+		// Keep it for a later attempt; it gets a new ID.
+		if err := h.Webhooks.AddDeadLetter(r.Context(), *letter); err != nil {
+			writeError(w, http.StatusInternalServerError, "Error restoring dead letter")
+			return
+		}
+		w.Header().Set("Retry-After", "60")
+		writeError(w, http.StatusServiceUnavailable, "Webhook queue is full, try again later")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "Dead letter queued for redelivery"})
+}
+
+func (h *EventsHandler) DeleteDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["deadLetterId"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Dead letter not found")
+		return
+	}
+
+	deleted, err := h.Webhooks.DeleteDeadLetter(r.Context(), mux.Vars(r)["id"], id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error deleting dead letter")
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "Dead letter not found")
+		return
+	}
+
+	writeSuccess(w, "Dead letter deleted successfully")
+}
@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"swift-api/pkg/events"
+	"swift-api/pkg/handlers"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent is one event of a stream, or a comment when only comment is set.
+type sseEvent struct {
+	id, event, data, comment string
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return e
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			e.id = value
+		case "event":
+			e.event = value
+		case "data":
+			e.data = value
+		case "":
+			e.comment = value
+		}
+	}
+}
+
+func openStream(t *testing.T, url, lastEventID string) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return bufio.NewReader(resp.Body)
+}
+
+func TestStreamEvents(t *testing.T) {
+	bus := events.NewBus(2)
+	h := handlers.NewEventsHandler(bus, events.NewMemoryStore(), nil, 50*time.Millisecond)
+	srv := httptest.NewServer(http.HandlerFunc(h.StreamEvents))
+	defer srv.Close()
+
+	created := events.Event{Type: events.SwiftCodeCreated, Source: events.SourceAPI, SwiftCode: "SSEVPLPWXXX"}
+	deleted := events.Event{Type: events.SwiftCodeDeleted, Source: events.SourceAPI, SwiftCode: "SSEVPLPWXXX"}
+
+	t.Run("Live events and heartbeats", func(t *testing.T) {
+		stream := openStream(t, srv.URL, "")
+		assert.Equal(t, "keepalive", readEvent(t, stream).comment, "the first heartbeat shows the stream is subscribed")
+
+		bus.Publish(context.Background(), created)
+		e := readEvent(t, stream)
+		for e.comment != "" {
+			e = readEvent(t, stream)
+		}
+		assert.Equal(t, strconv.FormatUint(bus.LastID(), 10), e.id)
+		assert.Equal(t, "swift_code.created", e.event)
+		assert.Contains(t, e.data, `"swiftCode":"SSEVPLPWXXX"`)
+	})
+
+	t.Run("Resume after Last-Event-ID", func(t *testing.T) {
+		before := bus.LastID()
+		bus.Publish(context.Background(), deleted)
+
+		e := readEvent(t, openStream(t, srv.URL, strconv.FormatUint(before, 10)))
+		assert.Equal(t, strconv.FormatUint(before+1, 10), e.id)
+		assert.Equal(t, "swift_code.deleted", e.event)
+	})
+
+	t.Run("Reset when events were missed", func(t *testing.T) {
+		bus.Publish(context.Background(), created, deleted)
+
+		e := readEvent(t, openStream(t, srv.URL, "1"))
+		assert.Equal(t, "reset", e.event)
+		assert.Equal(t, strconv.FormatUint(bus.LastID(), 10), e.id)
+	})
+
+	t.Run("Invalid Last-Event-ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/events", nil)
+		req.Header.Set("Last-Event-ID", "-1")
+		rec := httptest.NewRecorder()
+		h.StreamEvents(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"swift-api/pkg/service"
+)
+
+var (
+	v1LookupFields = []string{"address", "bankName", "countryISO2", "countryName", "isHeadquarter", "swiftCode", "branches"}
+	v2LookupFields = []string{"swiftCode", "bankName", "address", "townName", "countryISO2", "countryName", "timezone",
+		"isHeadquarter", "headquarterSwiftCode", "branches", "_links"}
+)
+
+// lookupOptions shape a single-code lookup:
+//
+//	?fields=bankName,branches   only these top-level attributes
+//	?embed=branches|none        whether a headquarter embeds its branches
+//	?branchLimit=N              embed at most N branches, ordered by code
+type lookupOptions struct {
+	fields      []string
+	embed       bool
+	branchLimit int
+}
+
+func parseLookupOptions(query url.Values, known []string) (lookupOptions, error) {
+	opts := lookupOptions{embed: true}
+
+	if raw := query.Get("fields"); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			field = strings.TrimSpace(field)
+			if !slices.Contains(known, field) {
+				return opts, fmt.Errorf("unknown field %q, expected one of %s", field, strings.Join(known, ", "))
+			}
+			opts.fields = append(opts.fields, field)
+		}
+	}
+
+	switch query.Get("embed") {
+	case "", "branches":
+	case "none":
+		opts.embed = false
+	default:
+		return opts, fmt.Errorf("embed must be branches or none")
+	}
+
+	if raw := query.Get("branchLimit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return opts, fmt.Errorf("branchLimit must be a positive integer")
+		}
+		opts.branchLimit = n
+	}
+	return opts, nil
+}
+
+func (o lookupOptions) service() service.LookupOptions {
+	return service.LookupOptions{Branches: o.wantsBranches(), BranchLimit: o.branchLimit}
+}
+
+// wantsBranches reports whether the branch query is needed at all.
+func (o lookupOptions) wantsBranches() bool {
+	return o.embed && (o.fields == nil || slices.Contains(o.fields, "branches"))
+}
+
+// keeps reports whether a top-level attribute is part of the response.
+func (o lookupOptions) keeps(field string) bool {
+	if field == "branches" && !o.embed {
+		return false
+	}
+	return o.fields == nil || slices.Contains(o.fields, field)
+}
+
+// full reports whether the response is the unfiltered representation.
+func (o lookupOptions) full() bool {
+	return o.fields == nil && o.embed
+}
+
+// writeLookup encodes resp, dropping the attributes opts leaves out. The
+// unfiltered representation is encoded as is so its bytes do not change.
+func writeLookup(w http.ResponseWriter, resp any, opts lookupOptions) error {
+	if opts.full() {
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(resp)
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(b, &attrs); err != nil {
+		return err
+	}
+	for field := range attrs {
+		if !opts.keeps(field) {
+			delete(attrs, field)
+		}
+	}
+	writeJSON(w, http.StatusOK, attrs)
+	return nil
+}
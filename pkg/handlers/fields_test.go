@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"swift-api/pkg/handlers"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// branchCountingRepo records the branch queries that reach the repository.
+type branchCountingRepo struct {
+	repository.Repository
+	queries []repository.BranchQuery
+	joined  int
+}
+
+func (r *branchCountingRepo) GetSwiftCodeWithBranches(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	r.joined++
+	return r.Repository.GetSwiftCodeWithBranches(ctx, swiftCode)
+}
+
+func (r *branchCountingRepo) GetBranches(ctx context.Context, hq string, q repository.BranchQuery) ([]models.SwiftCode, error) {
+	r.queries = append(r.queries, q)
+	return r.Repository.GetBranches(ctx, hq, q)
+}
+
+func TestLookupOptions(t *testing.T) {
+	hqCode := "OPTSPLPWXXX"
+	memory := repository.NewMemoryRepository()
+	require.NoError(t, memory.InsertSwiftCodes(context.Background(), []models.SwiftCode{
+		{SwiftCode: hqCode, BankName: "OPTS BANK", TownName: "WARSZAWA", CountryISO2: "PL", CountryName: "POLAND", Timezone: "Europe/Warsaw", IsHeadquarter: true},
+		{SwiftCode: "OPTSPLPW001", BankName: "OPTS BANK", TownName: "KRAKOW", CountryISO2: "PL", CountryName: "POLAND", Timezone: "Europe/Warsaw", HeadquarterSWIFTCode: &hqCode},
+		{SwiftCode: "OPTSPLPW002", BankName: "OPTS BANK", TownName: "GDANSK", CountryISO2: "PL", CountryName: "POLAND", Timezone: "Europe/Warsaw", HeadquarterSWIFTCode: &hqCode},
+	}))
+
+	get := func(t *testing.T, handler func(*handlers.Handler) http.HandlerFunc, code, query string) (*httptest.ResponseRecorder, *branchCountingRepo) {
+		repo := &branchCountingRepo{Repository: memory}
+		req := httptest.NewRequest(http.MethodGet, "/v1/swift-codes/"+code+"?"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"swift-code": code})
+		rec := httptest.NewRecorder()
+		handler(&handlers.Handler{Repo: repo})(rec, req)
+		return rec, repo
+	}
+	v1 := func(h *handlers.Handler) http.HandlerFunc { return h.GetSwiftCode }
+	v2 := func(h *handlers.Handler) http.HandlerFunc { return h.GetSwiftCodeV2 }
+
+	t.Run("Fields select attributes", func(t *testing.T) {
+		rec, repo := get(t, v1, hqCode, "fields=bankName,swiftCode")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"bankName":"OPTS BANK","swiftCode":"OPTSPLPWXXX"}`, rec.Body.String())
+		assert.Empty(t, repo.queries, "branches were not requested")
+	})
+
+	t.Run("Embed none skips the branch query", func(t *testing.T) {
+		rec, repo := get(t, v1, hqCode, "embed=none")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "branches")
+		assert.Contains(t, rec.Body.String(), `"countryName":"POLAND"`)
+		assert.Empty(t, repo.queries)
+	})
+
+	t.Run("Branch limit is passed to the repository", func(t *testing.T) {
+		rec, repo := get(t, v1, hqCode, "branchLimit=1&fields=branches")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"branches":[{"address":"","bankName":"OPTS BANK","countryISO2":"PL","isHeadquarter":false,"swiftCode":"OPTSPLPW001"}]}`, rec.Body.String())
+		assert.Equal(t, []repository.BranchQuery{{Limit: 1}}, repo.queries)
+		assert.Zero(t, repo.joined)
+	})
+
+	t.Run("Default embeds every branch with one joined query", func(t *testing.T) {
+		for _, handler := range []func(*handlers.Handler) http.HandlerFunc{v1, v2} {
+			rec, repo := get(t, handler, hqCode, "")
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "OPTSPLPW002")
+			assert.Empty(t, repo.queries)
+			assert.Equal(t, 1, repo.joined)
+		}
+	})
+
+	t.Run("V2 supports the same options", func(t *testing.T) {
+		rec, repo := get(t, v2, hqCode, "fields=townName,_links&embed=none")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Len(t, body, 2)
+		assert.JSONEq(t, `"WARSZAWA"`, string(body["townName"]))
+		assert.Empty(t, repo.queries)
+
+		rec, _ = get(t, v2, hqCode, "branchLimit=1")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var hq handlers.HeadquarterV2Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &hq))
+		assert.Len(t, hq.Branches, 1)
+		assert.Len(t, hq.Links.Branches, 1)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		for _, query := range []string{"fields=townName", "fields=bankName,,swiftCode", "embed=all", "branchLimit=0", "branchLimit=x"} {
+			rec, repo := get(t, v1, hqCode, query)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+			assert.Nil(t, repo.queries, query)
+		}
+	})
+}
@@ -3,14 +3,19 @@ package handlers
 import (
 	"encoding/json"
 	"github.com/gorilla/mux"
+	"log/slog"
 	"net/http"
 	"strings"
-	"swift-api/pkg/models"
+	"swift-api/pkg/events"
 	"swift-api/pkg/repository"
+	"swift-api/pkg/service"
 )
 
+// Events, when set, is told of the writes made through the handler.
 type Handler struct {
-	Repo repository.Repository
+	Repo   repository.Repository
+	Logger *slog.Logger
+	Events events.Publisher
 }
 
 type BranchResponse struct {
@@ -56,104 +61,49 @@ type CreateSwiftCodeRequest struct {
 	SwiftCode     string `json:"swiftCode"`
 }
 
-func NewHandler(repo repository.Repository) *Handler {
-	return &Handler{Repo: repo}
+func NewHandler(repo repository.Repository, logger *slog.Logger) *Handler {
+	return &Handler{Repo: repo, Logger: logger}
 }
 
-func (h *Handler) GetSwiftCode(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	swiftCode := vars["swift-code"]
-
-	if swiftCode == "" {
-		writeError(w, http.StatusBadRequest, "SWIFT code is required")
-		return
+func (h *Handler) logger() *slog.Logger {
+	if h.Logger == nil {
+		return slog.Default()
 	}
+	return h.Logger
+}
 
-	if len(swiftCode) != 11 {
-		writeError(w, http.StatusBadRequest, "SWIFT code must be exactly 11 characters")
-		return
-	}
+// service runs the operations REST shares with the other APIs.
+func (h *Handler) service() *service.Service {
+	svc := service.New(h.Repo, h.logger())
+	svc.Events = h.Events
+	return svc
+}
 
-	code, err := h.Repo.GetSwiftCodeDetails(swiftCode)
+func (h *Handler) GetSwiftCode(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseLookupOptions(r.URL.Query(), v1LookupFields)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Error retrieving SWIFT code")
-		return
-	}
-	if code == nil {
-		writeError(w, http.StatusNotFound, "SWIFT code not found")
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
-	if code.IsHeadquarter {
-		branches, err := h.Repo.GetBranchesByHeadquarter(swiftCode)
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "Error retrieving branches")
-			return
-		}
-		var branchResponses []BranchInHQResponse
-		for _, b := range branches {
-			branchResponses = append(branchResponses, BranchInHQResponse{
-				Address:       strOrEmpty(b.Address),
-				BankName:      b.BankName,
-				CountryISO2:   b.CountryISO2,
-				IsHeadquarter: b.IsHeadquarter,
-				SwiftCode:     b.SwiftCode,
-			})
-		}
-
-		resp := HeadquarterResponse{
-			Address:       strOrEmpty(code.Address),
-			BankName:      code.BankName,
-			CountryISO2:   code.CountryISO2,
-			CountryName:   code.CountryName,
-			IsHeadquarter: true,
-			SwiftCode:     code.SwiftCode,
-			Branches:      branchResponses,
-		}
-
-		if err = json.NewEncoder(w).Encode(resp); err != nil {
-			writeError(w, http.StatusInternalServerError, "Error encoding response")
-		}
+	code, err := h.service().GetSwiftCode(r.Context(), mux.Vars(r)["swift-code"], opts.service())
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
+	resp := newV1Lookup(*code, code.Branches)
 
-	resp := BranchResponse{
-		Address:       strOrEmpty(code.Address),
-		BankName:      code.BankName,
-		CountryISO2:   code.CountryISO2,
-		CountryName:   code.CountryName,
-		IsHeadquarter: false,
-		SwiftCode:     code.SwiftCode,
-	}
-
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
+	if err := writeLookup(w, resp, opts); err != nil {
+		h.logger().ErrorContext(r.Context(), "Error encoding response", "error", err)
 		writeError(w, http.StatusInternalServerError, "Error encoding response")
 	}
 }
 
 func (h *Handler) GetSwiftCodesByCountry(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	iso2 := vars["countryISO2code"]
-
-	if iso2 == "" {
-		writeError(w, http.StatusBadRequest, "Country ISO2 code is required")
-		return
-	}
-
-	if len(iso2) != 2 {
-		writeError(w, http.StatusBadRequest, "Country ISO2 code must be exactly 2 characters")
-		return
-	}
-
-	codes, countryName, err := h.Repo.GetSwiftCodesByCountry(iso2)
+	iso2 := mux.Vars(r)["countryISO2code"]
+	codes, countryName, err := h.service().ListByCountry(r.Context(), iso2)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Error retrieving SWIFT codes")
-		return
-	}
-	if len(codes) == 0 {
-		writeError(w, http.StatusNotFound, "No SWIFT codes found for this country")
+		writeServiceError(w, err)
 		return
 	}
 
@@ -177,6 +127,7 @@ func (h *Handler) GetSwiftCodesByCountry(w http.ResponseWriter, r *http.Request)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger().ErrorContext(r.Context(), "Error encoding response", "error", err)
 		writeError(w, http.StatusInternalServerError, "Error encoding response")
 		return
 	}
@@ -184,126 +135,36 @@ func (h *Handler) GetSwiftCodesByCountry(w http.ResponseWriter, r *http.Request)
 
 func (h *Handler) CreateSwiftCode(w http.ResponseWriter, r *http.Request) {
 	var req CreateSwiftCodeRequest
-
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	req.CountryISO2 = strings.ToUpper(req.CountryISO2)
-	req.CountryName = strings.ToUpper(req.CountryName)
-
-	if err := req.Validate(); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.service().CreateSwiftCode(r.Context(), service.NewSwiftCode(req)); err != nil {
+		writeServiceError(w, err)
 		return
 	}
-
-	newCode := models.SwiftCode{
-		Address:       &req.Address,
-		BankName:      req.BankName,
-		CountryISO2:   req.CountryISO2,
-		CountryName:   req.CountryName,
-		IsHeadquarter: req.IsHeadquarter,
-		SwiftCode:     req.SwiftCode,
-	}
-
-	if !req.IsHeadquarter {
-		hqCode := req.SwiftCode[:8] + "XXX"
-		exists, err := h.Repo.HeadquarterExists(hqCode)
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "DB error")
-			return
-		}
-		if !exists {
-			placeholder := models.SwiftCode{
-				SwiftCode:            hqCode,
-				BankName:             "UNKNOWN",
-				TownName:             "UNKNOWN",
-				CountryISO2:          req.CountryISO2,
-				CountryName:          req.CountryName,
-				Timezone:             "Etc/UTC",
-				IsHeadquarter:        true,
-				Address:              nil,
-				HeadquarterSWIFTCode: nil,
-			}
-			err = h.Repo.InsertSwiftCodes([]models.SwiftCode{placeholder})
-			if err != nil {
-				writeError(w, http.StatusInternalServerError, "Failed to insert placeholder HQ")
-				return
-			}
-		}
-
-		hqCodePtr := hqCode
-		newCode.HeadquarterSWIFTCode = &hqCodePtr
-	}
-
-	exists, err := h.Repo.SwiftCodeExists(newCode.SwiftCode)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "DB error")
-		return
-	}
-
-	if exists {
-		isPlaceholder, err := h.Repo.IsPlaceholder(newCode.SwiftCode)
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "DB error")
-			return
-		}
-		if isPlaceholder {
-			err = h.Repo.UpdatePlaceholderSwiftCode(newCode)
-			if err != nil {
-				writeError(w, http.StatusInternalServerError, "Failed to update placeholder SWIFT code")
-				return
-			}
-		} else {
-			writeError(w, http.StatusConflict, "SWIFT code already exists")
-			return
-		}
-	} else {
-		if err := h.Repo.InsertSwiftCodes([]models.SwiftCode{newCode}); err != nil {
-			writeError(w, http.StatusInternalServerError, "Failed to insert SWIFT code")
-			return
-		}
-	}
-
 	writeSuccess(w, "SWIFT code added successfully")
 }
 
-func (h *Handler) DeleteSwiftCode(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	swiftCode := vars["swift-code"]
-
-	if swiftCode == "" {
-		writeError(w, http.StatusBadRequest, "SWIFT code is required")
+func (h *Handler) UpdateSwiftCode(w http.ResponseWriter, r *http.Request) {
+	var req CreateSwiftCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	if len(swiftCode) != 11 {
-		writeError(w, http.StatusBadRequest, "SWIFT code must be exactly 11 characters")
+	if err := h.service().UpdateSwiftCode(r.Context(), mux.Vars(r)["swift-code"], service.NewSwiftCode(req)); err != nil {
+		writeServiceError(w, err)
 		return
 	}
+	writeSuccess(w, "SWIFT code updated successfully")
+}
 
-	if strings.HasSuffix(swiftCode, "XXX") {
-		branches, err := h.Repo.GetBranchesByHeadquarter(swiftCode)
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "DB error")
-			return
-		}
-		if len(branches) > 0 {
-			writeError(w, http.StatusConflict, "Cannot delete headquarter with existing branches")
-			return
-		}
-	}
-
-	deleted, err := h.Repo.DeleteSwiftCode(swiftCode)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Error deleting SWIFT code")
-		return
-	}
-	if !deleted {
-		writeError(w, http.StatusNotFound, "SWIFT code not found")
+func (h *Handler) DeleteSwiftCode(w http.ResponseWriter, r *http.Request) {
+	if err := h.service().DeleteSwiftCode(r.Context(), mux.Vars(r)["swift-code"]); err != nil {
+		writeServiceError(w, err)
 		return
 	}
-
 	writeSuccess(w, "SWIFT code deleted successfully")
 }
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"database/sql"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
@@ -10,11 +11,17 @@ import (
 	"os"
 	"strings"
 	"swift-api/pkg/handlers"
+	"swift-api/pkg/models"
 	"swift-api/pkg/repository"
 	"testing"
 )
 
+// setupTestHandler runs against PostgreSQL when DB_URL is set and against
+// the in-memory repository otherwise.
 func setupTestHandler(t *testing.T) *handlers.Handler {
+	if os.Getenv("DB_URL") == "" {
+		return &handlers.Handler{Repo: repository.NewMemoryRepository()}
+	}
 	db, err := sql.Open("postgres", os.Getenv("DB_URL"))
 	if err != nil {
 		t.Fatalf("Failed to connect to test DB: %v", err)
@@ -102,6 +109,20 @@ func TestGetSwiftCode(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	})
 
+	t.Run("V1 bodies are byte-for-byte stable", func(t *testing.T) {
+		for code, want := range map[string]string{
+			"TSTHQ000XXX": `{"address":"HQ Addr","bankName":"HQ","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true,"swiftCode":"TSTHQ000XXX",` +
+				`"branches":[{"address":"Branch Addr","bankName":"Branch","countryISO2":"PL","isHeadquarter":false,"swiftCode":"TSTHQ000001"}]}` + "\n",
+			"TSTHQ000001": `{"address":"Branch Addr","bankName":"Branch","countryISO2":"PL","countryName":"POLAND","isHeadquarter":false,"swiftCode":"TSTHQ000001"}` + "\n",
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/v1/swift-codes/"+code, nil)
+			req = mux.SetURLVars(req, map[string]string{"swift-code": code})
+			rec := httptest.NewRecorder()
+			h.GetSwiftCode(rec, req)
+			assert.Equal(t, want, rec.Body.String())
+		}
+	})
+
 	t.Run("Get non-existent SWIFT code", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/v1/swift-codes/INVALIDDXXX", nil)
 		req = mux.SetURLVars(req, map[string]string{"swift-code": "INVALIDDXXX"})
@@ -230,3 +251,54 @@ func TestDeleteSwiftCode(t *testing.T) {
 	})
 
 }
+
+func TestDiffImport(t *testing.T) {
+	h := setupTestHandler(t)
+	createHQAndBranch(t, h)
+
+	t.Run("Diff raw CSV body", func(t *testing.T) {
+		csv := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+			"PL,TSTHQ000XXX,BIC11,HQ Renamed,HQ Addr,,POLAND,\n" +
+			"PL,NEWWPLPWXXX,BIC11,New Bank,New Addr,WARSAW,POLAND,Europe/Warsaw\n"
+		req := httptest.NewRequest(http.MethodPost, "/v1/imports:diff", strings.NewReader(csv))
+		req.Header.Set("Content-Type", "text/csv")
+		rec := httptest.NewRecorder()
+		h.DiffImport(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"summary":{"added":1,"removed":1,"changed":1}`)
+		assert.Contains(t, rec.Body.String(), `{"field":"bankName","old":"HQ","new":"HQ Renamed"}`)
+	})
+
+	t.Run("Placeholder headquarters left out", func(t *testing.T) {
+		h := setupTestHandler(t)
+		hq := "GONEPLPWXXX"
+		err := h.Repo.InsertSwiftCodes(context.Background(), []models.SwiftCode{
+			{CountryISO2: "ZZ", SwiftCode: "GONEPLPWXXX", BankName: "UNKNOWN", TownName: "UNKNOWN", CountryName: "UNKNOWN", Timezone: "Etc/UTC", IsHeadquarter: true},
+			{CountryISO2: "PL", SwiftCode: "GONEPLPW001", BankName: "Gone", TownName: "WARSAW", CountryName: "POLAND", Timezone: "Europe/Warsaw", HeadquarterSWIFTCode: &hq},
+		})
+		assert.NoError(t, err)
+
+		csv := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+			"PL,GONEPLPW001,BIC11,Gone,,WARSAW,POLAND,Europe/Warsaw\n" +
+			"PL,ORPHPLPW001,BIC11,Orphan,,WARSAW,POLAND,Europe/Warsaw\n"
+		req := httptest.NewRequest(http.MethodPost, "/v1/imports:diff", strings.NewReader(csv))
+		rec := httptest.NewRecorder()
+		h.DiffImport(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"summary":{"added":1,"removed":0,"changed":0}`)
+		assert.Contains(t, rec.Body.String(), "ORPHPLPW001")
+		assert.NotContains(t, rec.Body.String(), `"swiftCode":"ORPHPLPWXXX"`)
+		assert.NotContains(t, rec.Body.String(), `"swiftCode":"GONEPLPWXXX"`)
+	})
+
+	t.Run("Diff with empty body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/imports:diff", strings.NewReader(""))
+		rec := httptest.NewRecorder()
+		h.DiffImport(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Invalid import file")
+	})
+}
@@ -2,15 +2,43 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"swift-api/pkg/service"
 )
 
 func writeError(w http.ResponseWriter, status int, message string) {
+	body := map[string]string{
+		"message": message,
+	}
+	if id := w.Header().Get(requestIDHeader); id != "" {
+		body["requestId"] = id
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{
-		"message": message,
-	})
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeServiceError answers with the status for err's kind and its client
+// message.
+func writeServiceError(w http.ResponseWriter, err error) {
+	var e *service.Error
+	if !errors.As(err, &e) {
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(e.Kind, service.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(e.Kind, service.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(e.Kind, service.ErrExists), errors.Is(e.Kind, service.ErrConflict):
+		status = http.StatusConflict
+	}
+	writeError(w, status, e.Message)
 }
 
 func writeSuccess(w http.ResponseWriter, message string) {
@@ -20,3 +48,9 @@ func writeSuccess(w http.ResponseWriter, message string) {
 		"message": message,
 	})
 }
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
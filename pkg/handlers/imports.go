@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"swift-api/pkg/diff"
+	"swift-api/pkg/models"
+	"swift-api/pkg/parser"
+)
+
+const maxImportSize = 32 << 20
+
+func (h *Handler) DiffImport(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	file, err := importFile(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Import file is required")
+		return
+	}
+	defer file.Close()
+
+	hq, branches, err := parser.ParseReader(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid import file")
+		return
+	}
+
+	current, err := h.Repo.GetAllSwiftCodes(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error retrieving SWIFT codes")
+		return
+	}
+
+	// Placeholder headquarters are not data anyone imported, so they are
+	// compared on neither side.
+	stored := make([]models.SwiftCode, 0, len(current))
+	for _, c := range current {
+		if !parser.IsPlaceholder(c) {
+			stored = append(stored, c)
+		}
+	}
+
+	resp := diff.Compare(stored, append(hq, branches...))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger().ErrorContext(r.Context(), "Error encoding response", "error", err)
+		writeError(w, http.StatusInternalServerError, "Error encoding response")
+	}
+}
+
+// importFile accepts either a multipart upload in the "file" field or the
+// CSV sent as the raw request body.
+func importFile(r *http.Request) (io.ReadCloser, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		return file, nil
+	}
+	return r.Body, nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"math"
+	"net/http"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"swift-api/pkg/service"
+)
+
+type InstitutionHeadquarterResponse struct {
+	SwiftCode   string `json:"swiftCode"`
+	BankName    string `json:"bankName"`
+	TownName    string `json:"townName"`
+	BranchCount int    `json:"branchCount"`
+}
+
+type InstitutionCountryResponse struct {
+	CountryISO2  string                           `json:"countryISO2"`
+	CountryName  string                           `json:"countryName"`
+	Headquarters []InstitutionHeadquarterResponse `json:"headquarters"`
+}
+
+// InstitutionResponse lists every headquarter of an institution per
+// country. Name is the bank name most of its headquarters share.
+type InstitutionResponse struct {
+	InstitutionCode string                       `json:"institutionCode"`
+	Name            string                       `json:"name"`
+	Countries       []InstitutionCountryResponse `json:"countries"`
+}
+
+type InstitutionSearchResponse struct {
+	Total        int                   `json:"total"`
+	Limit        int                   `json:"limit"`
+	Offset       int                   `json:"offset"`
+	Institutions []InstitutionResponse `json:"institutions"`
+}
+
+// newInstitution expects headquarters ordered by country, as the repository
+// returns them.
+func newInstitution(institution models.Institution) InstitutionResponse {
+	resp := InstitutionResponse{InstitutionCode: institution.Code, Name: institution.Name(), Countries: []InstitutionCountryResponse{}}
+	for _, hq := range institution.Headquarters {
+		if n := len(resp.Countries); n == 0 || resp.Countries[n-1].CountryISO2 != hq.CountryISO2 {
+			resp.Countries = append(resp.Countries, InstitutionCountryResponse{CountryISO2: hq.CountryISO2, CountryName: hq.CountryName})
+		}
+		country := &resp.Countries[len(resp.Countries)-1]
+		country.Headquarters = append(country.Headquarters, InstitutionHeadquarterResponse{
+			SwiftCode:   hq.SwiftCode,
+			BankName:    hq.BankName,
+			TownName:    hq.TownName,
+			BranchCount: hq.BranchCount,
+		})
+	}
+	return resp
+}
+
+func (h *Handler) GetInstitution(w http.ResponseWriter, r *http.Request) {
+	institution, err := h.service().GetInstitution(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newInstitution(*institution))
+}
+
+func (h *Handler) SearchInstitutions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := intParam(query, "limit", service.DefaultInstitutionLimit, 1, service.MaxInstitutionLimit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := intParam(query, "offset", 0, 0, math.MaxInt32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	institutions, total, err := h.service().SearchInstitutions(r.Context(), repository.InstitutionQuery{
+		Text:        query.Get("q"),
+		CountryISO2: query.Get("country"),
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := InstitutionSearchResponse{Total: total, Limit: limit, Offset: offset, Institutions: []InstitutionResponse{}}
+	for _, institution := range institutions {
+		resp.Institutions = append(resp.Institutions, newInstitution(institution))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
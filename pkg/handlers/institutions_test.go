@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"swift-api/pkg/handlers"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"testing"
+)
+
+func setupInstitutionsHandler(t *testing.T) *handlers.Handler {
+	hqCode := "GLOBPLPWXXX"
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.InsertSwiftCodes(context.Background(), []models.SwiftCode{
+		{SwiftCode: hqCode, BankName: "GLOBAL BANK", TownName: "WARSZAWA", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+		{SwiftCode: "GLOBPLPW001", BankName: "GLOBAL BANK", TownName: "KRAKOW", CountryISO2: "PL", CountryName: "POLAND", HeadquarterSWIFTCode: &hqCode},
+		{SwiftCode: "GLOBDEFFXXX", BankName: "GLOBAL BANK", TownName: "FRANKFURT", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+		{SwiftCode: "GLOBDEMMXXX", BankName: "GLOBAL BANK AG", TownName: "MUENCHEN", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+		{SwiftCode: "LOCLDEFFXXX", BankName: "LOCAL SPARKASSE", TownName: "FRANKFURT", CountryISO2: "DE", CountryName: "GERMANY", IsHeadquarter: true},
+	}))
+	require.NoError(t, repo.RefreshInstitutions(context.Background(), nil))
+	return &handlers.Handler{Repo: repo}
+}
+
+func TestGetInstitution(t *testing.T) {
+	h := setupInstitutionsHandler(t)
+
+	t.Run("Headquarters are listed per country", func(t *testing.T) {
+		rec := getWithQuery(h.GetInstitution, "/v1/institutions/glob", map[string]string{"code": "glob"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp handlers.InstitutionResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "GLOB", resp.InstitutionCode)
+		assert.Equal(t, "GLOBAL BANK", resp.Name)
+		require.Len(t, resp.Countries, 2)
+		assert.Equal(t, "DE", resp.Countries[0].CountryISO2)
+		assert.Equal(t, []handlers.InstitutionHeadquarterResponse{
+			{SwiftCode: "GLOBDEFFXXX", BankName: "GLOBAL BANK", TownName: "FRANKFURT"},
+			{SwiftCode: "GLOBDEMMXXX", BankName: "GLOBAL BANK AG", TownName: "MUENCHEN"},
+		}, resp.Countries[0].Headquarters)
+		assert.Equal(t, "POLAND", resp.Countries[1].CountryName)
+		assert.Equal(t, 1, resp.Countries[1].Headquarters[0].BranchCount)
+	})
+
+	t.Run("Invalid or unknown institution", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, getWithQuery(h.GetInstitution, "/", map[string]string{"code": "GLO"}).Code)
+		assert.Equal(t, http.StatusNotFound, getWithQuery(h.GetInstitution, "/", map[string]string{"code": "NONE"}).Code)
+	})
+
+	t.Run("Writes refresh the institution", func(t *testing.T) {
+		body := `{"swiftCode":"NEWBPLPWXXX","bankName":"NEW BANK","countryISO2":"PL","countryName":"Poland","address":"A","isHeadquarter":true}`
+		rec := httptest.NewRecorder()
+		h.CreateSwiftCode(rec, httptest.NewRequest(http.MethodPost, "/v1/swift-codes", bytes.NewBufferString(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, http.StatusOK, getWithQuery(h.GetInstitution, "/", map[string]string{"code": "NEWB"}).Code)
+
+		rec = getWithQuery(h.DeleteSwiftCode, "/", map[string]string{"swift-code": "NEWBPLPWXXX"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, http.StatusNotFound, getWithQuery(h.GetInstitution, "/", map[string]string{"code": "NEWB"}).Code)
+	})
+}
+
+func TestSearchInstitutions(t *testing.T) {
+	h := setupInstitutionsHandler(t)
+
+	search := func(t *testing.T, query string) handlers.InstitutionSearchResponse {
+		t.Helper()
+		rec := getWithQuery(h.SearchInstitutions, "/v1/institutions"+query, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp handlers.InstitutionSearchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+	codes := func(resp handlers.InstitutionSearchResponse) []string {
+		out := []string{}
+		for _, i := range resp.Institutions {
+			out = append(out, i.InstitutionCode)
+		}
+		return out
+	}
+
+	t.Run("Name, code and country filters", func(t *testing.T) {
+		resp := search(t, "")
+		assert.Equal(t, []string{"GLOB", "LOCL"}, codes(resp))
+		assert.Equal(t, 2, resp.Total)
+		assert.Equal(t, 20, resp.Limit)
+
+		assert.Equal(t, []string{"LOCL"}, codes(search(t, "?q=sparkasse")))
+		assert.Equal(t, []string{"GLOB"}, codes(search(t, "?q=glo")))
+		assert.Equal(t, []string{"GLOB"}, codes(search(t, "?country=pl")))
+		assert.Equal(t, []string{}, codes(search(t, "?q=sparkasse&country=PL")))
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		resp := search(t, "?limit=1&offset=1")
+		assert.Equal(t, []string{"LOCL"}, codes(resp))
+		assert.Equal(t, 2, resp.Total)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		for _, query := range []string{"?limit=0", "?limit=101", "?offset=-1", "?country=POL"} {
+			assert.Equal(t, http.StatusBadRequest, getWithQuery(h.SearchInstitutions, "/v1/institutions"+query, nil).Code, query)
+		}
+	})
+}
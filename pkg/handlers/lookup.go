@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"swift-api/pkg/models"
+)
+
+type BatchLookupRequest struct {
+	SwiftCodes []string `json:"swiftCodes"`
+}
+
+// BatchLookupResult answers one requested code, in request order. Details is
+// what GET /v1/swift-codes/{swift-code} returns for it.
+type BatchLookupResult struct {
+	Query     string `json:"query"`
+	SwiftCode string `json:"swiftCode,omitempty"`
+	Found     bool   `json:"found"`
+	Error     string `json:"error,omitempty"`
+	Details   any    `json:"details,omitempty"`
+}
+
+type BatchLookupResponse struct {
+	Found    int                 `json:"found"`
+	NotFound int                 `json:"notFound"`
+	Results  []BatchLookupResult `json:"results"`
+}
+
+func newBranchInHQ(b models.SwiftCode) BranchInHQResponse {
+	return BranchInHQResponse{
+		Address:       strOrEmpty(b.Address),
+		BankName:      b.BankName,
+		CountryISO2:   b.CountryISO2,
+		IsHeadquarter: b.IsHeadquarter,
+		SwiftCode:     b.SwiftCode,
+	}
+}
+
+func newHeadquarter(code models.SwiftCode, branches []models.SwiftCode) HeadquarterResponse {
+	var branchResponses []BranchInHQResponse
+	for _, b := range branches {
+		branchResponses = append(branchResponses, newBranchInHQ(b))
+	}
+	return HeadquarterResponse{
+		Address:       strOrEmpty(code.Address),
+		BankName:      code.BankName,
+		CountryISO2:   code.CountryISO2,
+		CountryName:   code.CountryName,
+		IsHeadquarter: true,
+		SwiftCode:     code.SwiftCode,
+		Branches:      branchResponses,
+	}
+}
+
+// newV1Lookup builds the v1 representation of code: a HeadquarterResponse
+// embedding branches, or a BranchResponse.
+func newV1Lookup(code models.SwiftCode, branches []models.SwiftCode) any {
+	if !code.IsHeadquarter {
+		return BranchResponse{
+			Address:       strOrEmpty(code.Address),
+			BankName:      code.BankName,
+			CountryISO2:   code.CountryISO2,
+			CountryName:   code.CountryName,
+			IsHeadquarter: false,
+			SwiftCode:     code.SwiftCode,
+		}
+	}
+	return newHeadquarter(code, branches)
+}
+
+// LookupSwiftCodes resolves many codes with one query for the codes and one
+// for the branches of the headquarters among them.
+func (h *Handler) LookupSwiftCodes(w http.ResponseWriter, r *http.Request) {
+	var req BatchLookupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	results, err := h.service().LookupSwiftCodes(r.Context(), req.SwiftCodes)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := BatchLookupResponse{Results: make([]BatchLookupResult, len(results))}
+	for i, result := range results {
+		resp.Results[i] = BatchLookupResult{Query: result.Query, SwiftCode: result.SwiftCode, Error: result.Error}
+		if result.Code == nil {
+			resp.NotFound++
+			continue
+		}
+		resp.Found++
+		resp.Results[i].Found = true
+		resp.Results[i].Details = newV1Lookup(*result.Code, result.Code.Branches)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,127 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"swift-api/pkg/handlers"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryCountingRepo counts the repository calls a handler makes.
+type queryCountingRepo struct {
+	repository.Repository
+	calls map[string]int
+}
+
+func (r *queryCountingRepo) GetSwiftCodeDetails(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	r.calls["GetSwiftCodeDetails"]++
+	return r.Repository.GetSwiftCodeDetails(ctx, swiftCode)
+}
+
+func (r *queryCountingRepo) GetSwiftCodesByCodes(ctx context.Context, swiftCodes []string) ([]models.SwiftCode, error) {
+	r.calls["GetSwiftCodesByCodes"]++
+	return r.Repository.GetSwiftCodesByCodes(ctx, swiftCodes)
+}
+
+func (r *queryCountingRepo) GetBranches(ctx context.Context, hq string, q repository.BranchQuery) ([]models.SwiftCode, error) {
+	r.calls["GetBranches"]++
+	return r.Repository.GetBranches(ctx, hq, q)
+}
+
+func (r *queryCountingRepo) GetBranchesByHeadquarters(ctx context.Context, hqs []string) ([]models.SwiftCode, error) {
+	r.calls["GetBranchesByHeadquarters"]++
+	return r.Repository.GetBranchesByHeadquarters(ctx, hqs)
+}
+
+func TestLookupSwiftCodes(t *testing.T) {
+	memory := repository.NewMemoryRepository()
+	var codes []models.SwiftCode
+	for i := 0; i < 50; i++ {
+		hq := fmt.Sprintf("BT%02dPLPWXXX", i)
+		codes = append(codes,
+			models.SwiftCode{SwiftCode: hq, BankName: "BATCH BANK", TownName: "WARSZAWA", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+			models.SwiftCode{SwiftCode: fmt.Sprintf("BT%02dPLPW001", i), BankName: "BATCH BANK", TownName: "KRAKOW", CountryISO2: "PL", CountryName: "POLAND", HeadquarterSWIFTCode: &hq},
+		)
+	}
+	require.NoError(t, memory.InsertSwiftCodes(context.Background(), codes))
+
+	lookup := func(t *testing.T, body string) (*httptest.ResponseRecorder, *queryCountingRepo) {
+		t.Helper()
+		repo := &queryCountingRepo{Repository: memory, calls: map[string]int{}}
+		rec := httptest.NewRecorder()
+		(&handlers.Handler{Repo: repo}).LookupSwiftCodes(rec, httptest.NewRequest(http.MethodPost, "/v1/swift-codes:lookup", strings.NewReader(body)))
+		return rec, repo
+	}
+
+	t.Run("Many codes take two queries", func(t *testing.T) {
+		var req handlers.BatchLookupRequest
+		for i := 0; i < 50; i++ {
+			req.SwiftCodes = append(req.SwiftCodes, fmt.Sprintf("BT%02dPLPWXXX", i), fmt.Sprintf("BT%02dPLPW001", i))
+		}
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rec, repo := lookup(t, string(body))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, map[string]int{"GetSwiftCodesByCodes": 1, "GetBranchesByHeadquarters": 1}, repo.calls)
+
+		var resp handlers.BatchLookupResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 100, resp.Found)
+		assert.Zero(t, resp.NotFound)
+	})
+
+	t.Run("Results follow the request and match single lookups", func(t *testing.T) {
+		rec, _ := lookup(t, `{"swiftCodes":["bt01plpw","MISSINGGXXX","BT01PLPW001","BT0","BT01PLPW001"]}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			Found, NotFound int
+			Results         []struct {
+				Query, SwiftCode, Error string
+				Found                   bool
+				Details                 json.RawMessage
+			}
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 3, resp.Found)
+		assert.Equal(t, 2, resp.NotFound)
+		require.Len(t, resp.Results, 5)
+
+		assert.Equal(t, "bt01plpw", resp.Results[0].Query)
+		assert.Equal(t, "BT01PLPWXXX", resp.Results[0].SwiftCode)
+		assert.True(t, resp.Results[0].Found)
+		assert.False(t, resp.Results[1].Found)
+		assert.Nil(t, resp.Results[1].Details)
+		assert.Contains(t, resp.Results[3].Error, "8 or 11 characters")
+		assert.Empty(t, resp.Results[3].SwiftCode)
+
+		single := getWithQuery((&handlers.Handler{Repo: memory}).GetSwiftCode, "/v1/swift-codes/BT01PLPWXXX", map[string]string{"swift-code": "BT01PLPWXXX"})
+		assert.JSONEq(t, single.Body.String(), string(resp.Results[0].Details))
+		single = getWithQuery((&handlers.Handler{Repo: memory}).GetSwiftCode, "/v1/swift-codes/BT01PLPW001", map[string]string{"swift-code": "BT01PLPW001"})
+		assert.JSONEq(t, single.Body.String(), string(resp.Results[4].Details))
+	})
+
+	t.Run("Only invalid codes skip the database", func(t *testing.T) {
+		rec, repo := lookup(t, `{"swiftCodes":["BAD","NOT-A-BIC"]}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, repo.calls)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		tooMany := `{"swiftCodes":[` + strings.TrimSuffix(strings.Repeat(`"BT01PLPWXXX",`, 1001), ",") + `]}`
+		for _, body := range []string{`{`, `{"swiftCodes":[]}`, `{}`, tooMany} {
+			rec, _ := lookup(t, body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
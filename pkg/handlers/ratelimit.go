@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/ratelimit"
+	"time"
+)
+
+type clientIPKey struct{}
+
+// ClientAddress records the address each request came from, taken from
+// X-Forwarded-For when the sender is one of proxies. The rate limiters key
+// anonymous callers by it.
+func ClientAddress(proxies ratelimit.Proxies) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := proxies.ClientIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For"))
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+		})
+	}
+}
+
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return ratelimit.Proxies(nil).ClientIP(r.RemoteAddr, nil)
+}
+
+// RateLimit throttles requests per client. Authenticated callers are keyed
+// by API key or token subject, everyone else by client IP. A nil limiter
+// disables the middleware.
+func RateLimit(limiter ratelimit.Limiter) mux.MiddlewareFunc {
+	return limit(limiter, clientKey, true)
+}
+
+// LimitByAddress throttles requests by client IP before they are
+// authenticated, so guessing credentials is limited too. It only sets the
+// RateLimit headers on the requests it rejects; the read or write limiter
+// further in reports the budget of the rest.
+func LimitByAddress(limiter ratelimit.Limiter) mux.MiddlewareFunc {
+	return limit(limiter, func(r *http.Request) string { return "ip:" + clientIP(r) }, false)
+}
+
+func limit(limiter ratelimit.Limiter, key func(*http.Request) string, headers bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := limiter.Allow(r.Context(), key(r))
+			if err != nil {
+				slog.WarnContext(r.Context(), "Rate limiter unavailable, allowing request", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if headers || !res.Allowed {
+				w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+				w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+				w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			}
+
+			if !res.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientKey(r *http.Request) string {
+	if p, ok := auth.FromContext(r.Context()); ok {
+		if p.KeyID != "" {
+			return "key:" + p.KeyID
+		}
+		return "sub:" + p.Subject
+	}
+	return "ip:" + clientIP(r)
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"swift-api/pkg/auth"
+	"swift-api/pkg/handlers"
+	"swift-api/pkg/ratelimit"
+	"testing"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter := ratelimit.NewMemoryLimiter(ratelimit.Policy{Rate: 0.001, Burst: 1})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	authn := stubAuthenticator{"editor-key": {Subject: "e", KeyID: "abc", Roles: []auth.Role{auth.RoleEditor}}}
+	limited := handlers.Authenticate(authn)(handlers.RateLimit(limiter)(ok))
+
+	send := func(remoteAddr, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/swift-codes/TSTHQ000XXX", nil)
+		req.RemoteAddr = remoteAddr
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		limited.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("First request passes with headers", func(t *testing.T) {
+		rec := send("10.0.0.1:5000", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	})
+
+	t.Run("Same IP is throttled", func(t *testing.T) {
+		rec := send("10.0.0.1:5001", "")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+		assert.Contains(t, rec.Body.String(), "Rate limit exceeded")
+	})
+
+	t.Run("API key has its own bucket", func(t *testing.T) {
+		rec := send("10.0.0.1:5002", "editor-key")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = send("10.0.0.2:5000", "editor-key")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("Failed authentication is throttled by IP", func(t *testing.T) {
+		perIP := ratelimit.NewMemoryLimiter(ratelimit.Policy{Rate: 0.001, Burst: 2})
+		h := handlers.RateLimit(perIP)(handlers.Authenticate(authn)(ok))
+		guess := func() int {
+			req := httptest.NewRequest(http.MethodGet, "/v1/swift-codes/TSTHQ000XXX", nil)
+			req.RemoteAddr = "10.0.0.3:5000"
+			req.Header.Set("X-API-Key", "wrong-key")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, guess())
+		assert.Equal(t, http.StatusUnauthorized, guess())
+		assert.Equal(t, http.StatusTooManyRequests, guess())
+	})
+
+	t.Run("Address limiter sets headers only when it rejects", func(t *testing.T) {
+		perIP := ratelimit.NewMemoryLimiter(ratelimit.Policy{Rate: 0.001, Burst: 1})
+		h := handlers.LimitByAddress(perIP)(ok)
+		send := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/v1/swift-codes/TSTHQ000XXX", nil)
+			req.RemoteAddr = "10.0.0.4:5000"
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec := send()
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+
+		rec = send()
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("Clients behind a trusted proxy have their own buckets", func(t *testing.T) {
+		proxies, err := ratelimit.ParseProxies([]string{"10.1.0.0/16"})
+		require.NoError(t, err)
+		perIP := ratelimit.NewMemoryLimiter(ratelimit.Policy{Rate: 0.001, Burst: 1})
+		h := handlers.ClientAddress(proxies)(handlers.LimitByAddress(perIP)(ok))
+		send := func(remoteAddr, forwardedFor string) int {
+			req := httptest.NewRequest(http.MethodGet, "/v1/swift-codes/TSTHQ000XXX", nil)
+			req.RemoteAddr = remoteAddr
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		assert.Equal(t, http.StatusOK, send("10.1.0.1:5000", "198.51.100.1"))
+		assert.Equal(t, http.StatusOK, send("10.1.0.1:5000", "198.51.100.2"))
+		assert.Equal(t, http.StatusTooManyRequests, send("10.1.0.2:5000", "198.51.100.1"))
+
+		// Anyone else is keyed by their own address whatever they forward.
+		assert.Equal(t, http.StatusOK, send("10.0.0.5:5000", "198.51.100.3"))
+		assert.Equal(t, http.StatusTooManyRequests, send("10.0.0.5:5000", "198.51.100.4"))
+	})
+
+	t.Run("Nil limiter disables throttling", func(t *testing.T) {
+		h := handlers.RateLimit(nil)(ok)
+		for i := 0; i < 3; i++ {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"swift-api/pkg/handlers"
+	"swift-api/pkg/logging"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	logger, err := logging.New(&logs, "info", "json")
+	assert.NoError(t, err)
+
+	var seen string
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+		handlers.RequireRole("editor")(nil).ServeHTTP(w, r)
+	})
+	h := handlers.RequestID()(handlers.AccessLog(logger)(inner))
+
+	t.Run("Incoming ID is propagated", func(t *testing.T) {
+		logs.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/v1/swift-codes/TSTHQ000XXX", nil)
+		req.Header.Set("X-Request-ID", "abc-123")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, "abc-123", seen)
+		assert.Equal(t, "abc-123", rec.Header().Get("X-Request-ID"))
+		assert.Contains(t, rec.Body.String(), `"requestId":"abc-123"`)
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+		assert.Equal(t, "abc-123", line["request_id"])
+		assert.Equal(t, float64(http.StatusUnauthorized), line["status"])
+		assert.Equal(t, slog.LevelInfo.String(), line["level"])
+	})
+
+	t.Run("Missing or invalid ID is generated", func(t *testing.T) {
+		for _, incoming := range []string{"", "has space", string(bytes.Repeat([]byte("a"), 200))} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Request-ID", incoming)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Len(t, rec.Header().Get("X-Request-ID"), 32)
+			assert.Equal(t, seen, rec.Header().Get("X-Request-ID"))
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"strings"
+	"swift-api/pkg/models"
+)
+
+const v2Prefix = "/v2/swift-codes"
+
+type Link struct {
+	Href string `json:"href"`
+}
+
+// SwiftCodeLinks relates a code to its headquarter, branches and country in
+// the HAL style.
+type SwiftCodeLinks struct {
+	Self        Link   `json:"self"`
+	Headquarter *Link  `json:"headquarter,omitempty"`
+	Branches    []Link `json:"branches,omitempty"`
+	Country     Link   `json:"country"`
+}
+
+// SwiftCodeV2Response carries every stored attribute of a code. Unlike v1,
+// the address is null when unknown.
+type SwiftCodeV2Response struct {
+	SwiftCode            string         `json:"swiftCode"`
+	BankName             string         `json:"bankName"`
+	Address              *string        `json:"address"`
+	TownName             string         `json:"townName"`
+	CountryISO2          string         `json:"countryISO2"`
+	CountryName          string         `json:"countryName"`
+	Timezone             string         `json:"timezone"`
+	IsHeadquarter        bool           `json:"isHeadquarter"`
+	HeadquarterSwiftCode *string        `json:"headquarterSwiftCode"`
+	Links                SwiftCodeLinks `json:"_links"`
+}
+
+// HeadquarterV2Response always has a branches array, empty when the
+// headquarter has none.
+type HeadquarterV2Response struct {
+	SwiftCodeV2Response
+	Branches []SwiftCodeV2Response `json:"branches"`
+}
+
+type CountryV2Response struct {
+	CountryISO2 string                `json:"countryISO2"`
+	CountryName string                `json:"countryName"`
+	SwiftCodes  []SwiftCodeV2Response `json:"swiftCodes"`
+	Links       struct {
+		Self Link `json:"self"`
+	} `json:"_links"`
+}
+
+func swiftCodeLink(swiftCode string) Link { return Link{Href: v2Prefix + "/" + swiftCode} }
+func countryLink(iso2 string) Link        { return Link{Href: v2Prefix + "/country/" + iso2} }
+
+func newSwiftCodeV2(code models.SwiftCode) SwiftCodeV2Response {
+	resp := SwiftCodeV2Response{
+		SwiftCode:     code.SwiftCode,
+		BankName:      code.BankName,
+		Address:       code.Address,
+		TownName:      code.TownName,
+		CountryISO2:   code.CountryISO2,
+		CountryName:   code.CountryName,
+		Timezone:      code.Timezone,
+		IsHeadquarter: code.IsHeadquarter,
+		Links: SwiftCodeLinks{
+			Self:    swiftCodeLink(code.SwiftCode),
+			Country: countryLink(code.CountryISO2),
+		},
+	}
+	if hq := code.HeadquarterSWIFTCode; hq != nil && *hq != code.SwiftCode {
+		resp.HeadquarterSwiftCode = hq
+		link := swiftCodeLink(*hq)
+		resp.Links.Headquarter = &link
+	}
+	return resp
+}
+
+func (h *Handler) GetSwiftCodeV2(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseLookupOptions(r.URL.Query(), v2LookupFields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	code, err := h.service().GetSwiftCode(r.Context(), mux.Vars(r)["swift-code"], opts.service())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	var resp any = newSwiftCodeV2(*code)
+	if code.IsHeadquarter {
+		hq := HeadquarterV2Response{
+			SwiftCodeV2Response: newSwiftCodeV2(*code),
+			Branches:            []SwiftCodeV2Response{},
+		}
+		for _, b := range code.Branches {
+			hq.Branches = append(hq.Branches, newSwiftCodeV2(b))
+			hq.Links.Branches = append(hq.Links.Branches, swiftCodeLink(b.SwiftCode))
+		}
+		resp = hq
+	}
+
+	if err := writeLookup(w, resp, opts); err != nil {
+		h.logger().ErrorContext(r.Context(), "Error encoding response", "error", err)
+		writeError(w, http.StatusInternalServerError, "Error encoding response")
+	}
+}
+
+func (h *Handler) GetSwiftCodesByCountryV2(w http.ResponseWriter, r *http.Request) {
+	iso2 := strings.ToUpper(mux.Vars(r)["countryISO2code"])
+	codes, countryName, err := h.service().ListByCountry(r.Context(), iso2)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := CountryV2Response{
+		CountryISO2: iso2,
+		CountryName: countryName,
+		SwiftCodes:  make([]SwiftCodeV2Response, 0, len(codes)),
+	}
+	resp.Links.Self = countryLink(iso2)
+	for _, code := range codes {
+		resp.SwiftCodes = append(resp.SwiftCodes, newSwiftCodeV2(code))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,108 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"swift-api/pkg/handlers"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"testing"
+)
+
+func setupV2Handler(t *testing.T) *handlers.Handler {
+	hqCode := "V2BKPLPWXXX"
+	address := "ul. Testowa 1"
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.InsertSwiftCodes(context.Background(), []models.SwiftCode{
+		{SwiftCode: hqCode, BankName: "V2 BANK", Address: &address, TownName: "WARSZAWA", CountryISO2: "PL", CountryName: "POLAND", Timezone: "Europe/Warsaw", IsHeadquarter: true},
+		{SwiftCode: "V2BKPLPW001", BankName: "V2 BANK", TownName: "KRAKOW", CountryISO2: "PL", CountryName: "POLAND", Timezone: "Europe/Warsaw", HeadquarterSWIFTCode: &hqCode},
+		{SwiftCode: "LONEDEFFXXX", BankName: "LONE", TownName: "FRANKFURT", CountryISO2: "DE", CountryName: "GERMANY", Timezone: "Europe/Berlin", IsHeadquarter: true},
+	}))
+	return &handlers.Handler{Repo: repo}
+}
+
+func getV2(h http.HandlerFunc, vars map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/v2/swift-codes", nil)
+	req = mux.SetURLVars(req, vars)
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec
+}
+
+func TestGetSwiftCodeV2(t *testing.T) {
+	h := setupV2Handler(t)
+
+	t.Run("Headquarter links to its branches and country", func(t *testing.T) {
+		rec := getV2(h.GetSwiftCodeV2, map[string]string{"swift-code": "V2BKPLPWXXX"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp handlers.HeadquarterV2Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "WARSZAWA", resp.TownName)
+		assert.Equal(t, "Europe/Warsaw", resp.Timezone)
+		assert.Equal(t, "ul. Testowa 1", *resp.Address)
+		assert.Nil(t, resp.HeadquarterSwiftCode)
+		assert.Equal(t, "/v2/swift-codes/V2BKPLPWXXX", resp.Links.Self.Href)
+		assert.Equal(t, "/v2/swift-codes/country/PL", resp.Links.Country.Href)
+		assert.Nil(t, resp.Links.Headquarter)
+		assert.Equal(t, []handlers.Link{{Href: "/v2/swift-codes/V2BKPLPW001"}}, resp.Links.Branches)
+
+		require.Len(t, resp.Branches, 1)
+		assert.Equal(t, "KRAKOW", resp.Branches[0].TownName)
+		assert.Equal(t, "/v2/swift-codes/V2BKPLPWXXX", resp.Branches[0].Links.Headquarter.Href)
+	})
+
+	t.Run("Branch links to its headquarter", func(t *testing.T) {
+		rec := getV2(h.GetSwiftCodeV2, map[string]string{"swift-code": "V2BKPLPW001"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `"branches"`)
+		assert.Contains(t, rec.Body.String(), `"address":null`)
+
+		var resp handlers.SwiftCodeV2Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "V2BKPLPWXXX", *resp.HeadquarterSwiftCode)
+		assert.Equal(t, "/v2/swift-codes/V2BKPLPWXXX", resp.Links.Headquarter.Href)
+	})
+
+	t.Run("Headquarter without branches has an empty array", func(t *testing.T) {
+		rec := getV2(h.GetSwiftCodeV2, map[string]string{"swift-code": "LONEDEFFXXX"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"branches":[]`)
+	})
+
+	t.Run("Errors match v1", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, getV2(h.GetSwiftCodeV2, map[string]string{"swift-code": "SHORT"}).Code)
+		assert.Equal(t, http.StatusNotFound, getV2(h.GetSwiftCodeV2, map[string]string{"swift-code": "MISSINGGXXX"}).Code)
+	})
+}
+
+func TestGetSwiftCodesByCountryV2(t *testing.T) {
+	h := setupV2Handler(t)
+
+	t.Run("Codes carry all attributes and links", func(t *testing.T) {
+		rec := getV2(h.GetSwiftCodesByCountryV2, map[string]string{"countryISO2code": "pl"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp handlers.CountryV2Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "PL", resp.CountryISO2)
+		assert.Equal(t, "POLAND", resp.CountryName)
+		assert.Equal(t, "/v2/swift-codes/country/PL", resp.Links.Self.Href)
+		require.Len(t, resp.SwiftCodes, 2)
+		for _, code := range resp.SwiftCodes {
+			assert.NotEmpty(t, code.TownName)
+			assert.NotEmpty(t, code.Timezone)
+			assert.Equal(t, "/v2/swift-codes/"+code.SwiftCode, code.Links.Self.Href)
+		}
+	})
+
+	t.Run("Errors match v1", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, getV2(h.GetSwiftCodesByCountryV2, map[string]string{"countryISO2code": "POL"}).Code)
+		assert.Equal(t, http.StatusNotFound, getV2(h.GetSwiftCodesByCountryV2, map[string]string{"countryISO2code": "XX"}).Code)
+	})
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker(t *testing.T) {
+	gate := &Gate{}
+	c := NewChecker(20 * time.Millisecond)
+	c.Add("database", func(context.Context) error { return nil })
+	c.Add("import", gate.Check)
+
+	readiness := func() (int, Report) {
+		rec := httptest.NewRecorder()
+		c.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var report Report
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+		return rec.Code, report
+	}
+
+	t.Run("Liveness always ok", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c.Liveness(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+	})
+
+	t.Run("Not ready while import runs", func(t *testing.T) {
+		code, report := readiness()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "fail", report.Status)
+		assert.Equal(t, "ok", report.Checks["database"].Status)
+		assert.Equal(t, "in progress", report.Checks["import"].Error)
+	})
+
+	t.Run("Ready after import", func(t *testing.T) {
+		gate.Done(nil)
+		code, report := readiness()
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", report.Status)
+	})
+
+	t.Run("Slow check times out", func(t *testing.T) {
+		c.Add("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		code, report := readiness()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+	})
+}
+
+func TestGateFailure(t *testing.T) {
+	gate := &Gate{}
+	gate.Done(errors.New("bad file"))
+	assert.EqualError(t, gate.Check(context.Background()), "bad file")
+}
@@ -0,0 +1,57 @@
+// Package httpx holds net/http helpers shared by the middleware packages.
+package httpx
+
+import "net/http"
+
+// StatusRecorder remembers the status code and body size of the response
+// written through it. It implements http.Flusher and unwraps for
+// http.ResponseController, so streaming handlers work behind it.
+type StatusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w}
+}
+
+// Status is the status code sent, 200 when the handler wrote nothing.
+func (s *StatusRecorder) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
+
+// Bytes is the size of the body written so far.
+func (s *StatusRecorder) Bytes() int {
+	return s.bytes
+}
+
+func (s *StatusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *StatusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+func (s *StatusRecorder) Flush() {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	_ = http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+func (s *StatusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
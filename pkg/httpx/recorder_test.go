@@ -0,0 +1,36 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusRecorder(t *testing.T) {
+	t.Run("Defaults to 200", func(t *testing.T) {
+		rec := NewStatusRecorder(httptest.NewRecorder())
+		assert.Equal(t, http.StatusOK, rec.Status())
+	})
+
+	t.Run("Keeps the first status and counts bytes", func(t *testing.T) {
+		rec := NewStatusRecorder(httptest.NewRecorder())
+		rec.WriteHeader(http.StatusNotFound)
+		rec.WriteHeader(http.StatusInternalServerError)
+		_, _ = rec.Write([]byte("missing"))
+		assert.Equal(t, http.StatusNotFound, rec.Status())
+		assert.Equal(t, 7, rec.Bytes())
+	})
+
+	t.Run("Flushes the wrapped writer", func(t *testing.T) {
+		inner := httptest.NewRecorder()
+		rec := NewStatusRecorder(inner)
+		var w http.ResponseWriter = rec
+		flusher, ok := w.(http.Flusher)
+		assert.True(t, ok)
+		flusher.Flush()
+		assert.True(t, inner.Flushed)
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	})
+}
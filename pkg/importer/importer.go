@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"swift-api/pkg/events"
+	"swift-api/pkg/models"
+	"swift-api/pkg/parser"
+	"swift-api/pkg/repository"
+	"swift-api/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type Stats struct {
+	Parsed       int
+	Skipped      int
+	Placeholders int
+	Headquarters int
+	Branches     int
+	Promoted     int
+}
+
+type options struct {
+	events events.Publisher
+}
+
+type Option func(*options)
+
+// WithEvents publishes a swift_code.created event for every code the import
+// adds and a swift_code.placeholder_promoted event for every placeholder it
+// promotes. Other codes already present are not announced.
+func WithEvents(p events.Publisher) Option {
+	return func(o *options) { o.events = p }
+}
+
+// Import loads the bank directory at path into repo: headquarters first, then
+// placeholder headquarters for orphan branches, then branches, and finally
+// rebuilds the institution view. Existing codes are left untouched, except
+// for placeholder headquarters the file has the real data of: those are
+// promoted once the inserts have committed.
+func Import(ctx context.Context, repo repository.Repository, path string, opts ...Option) (stats Stats, err error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "import")
+	span.SetAttributes(attribute.String("import.path", path))
+	defer func() {
+		span.SetAttributes(
+			attribute.Int("import.parsed", stats.Parsed),
+			attribute.Int("import.skipped", stats.Skipped),
+			attribute.Int("import.placeholders", stats.Placeholders),
+			attribute.Int("import.promoted", stats.Promoted),
+		)
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	_, parseSpan := tracing.Tracer().Start(ctx, "import.parse")
+	file, err := os.Open(path)
+	if err != nil {
+		tracing.RecordError(parseSpan, err)
+		parseSpan.End()
+		return stats, err
+	}
+	defer file.Close()
+
+	hq, branches, parsed, err := parser.ParseReaderStats(file)
+	tracing.RecordError(parseSpan, err)
+	parseSpan.End()
+	if err != nil {
+		return stats, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	stats.Parsed = parsed.Parsed
+	stats.Skipped = parsed.Skipped
+
+	_, fillSpan := tracing.Tracer().Start(ctx, "import.fill_placeholders")
+	withPlaceholders := parser.FillMissingHeadquarters(hq, branches)
+	fillSpan.End()
+	stats.Placeholders = len(withPlaceholders) - len(hq)
+	stats.Headquarters = len(hq)
+	stats.Branches = len(branches)
+
+	if err := insertPhase(ctx, repo, o, &stats, "import.insert_headquarters", withPlaceholders); err != nil {
+		return stats, fmt.Errorf("error inserting headquarters: %w", err)
+	}
+	if err := insertPhase(ctx, repo, o, &stats, "import.insert_branches", branches); err != nil {
+		return stats, fmt.Errorf("error inserting branches: %w", err)
+	}
+
+	refreshCtx, refreshSpan := tracing.Tracer().Start(ctx, "import.refresh_institutions")
+	err = repo.RefreshInstitutions(refreshCtx, nil)
+	tracing.RecordError(refreshSpan, err)
+	refreshSpan.End()
+	if err != nil {
+		return stats, fmt.Errorf("error refreshing institutions: %w", err)
+	}
+
+	return stats, nil
+}
+
+// insertPhase inserts the codes that are not stored yet, then promotes the
+// stored placeholders codes has the real data of. Promotions run one row at a
+// time; a failed import leaves the rest placeholders for the next one.
+func insertPhase(ctx context.Context, repo repository.Repository, o options, stats *Stats, name string, codes []models.SwiftCode) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, name)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	span.SetAttributes(tracing.RowsAttribute(len(codes)))
+
+	existing, err := existingCodes(ctx, repo, codes)
+	if err != nil {
+		return err
+	}
+	if err = repo.InsertSwiftCodes(ctx, codes); err != nil {
+		return err
+	}
+
+	var announced []events.Event
+	var promoted []models.SwiftCode
+	for i := range codes {
+		current, ok := existing[codes[i].SwiftCode]
+		switch {
+		case !ok:
+			announced = append(announced, event(events.SwiftCodeCreated, &codes[i]))
+		case parser.IsPlaceholder(current) && !parser.IsPlaceholder(codes[i]):
+			promoted = append(promoted, codes[i])
+		}
+	}
+	for i := range promoted {
+		if err = repo.UpdatePlaceholderSwiftCode(ctx, promoted[i]); err != nil {
+			return err
+		}
+		stats.Promoted++
+		announced = append(announced, event(events.SwiftCodePlaceholderPromoted, &promoted[i]))
+	}
+
+	if o.events != nil {
+		o.events.Publish(ctx, announced...)
+	}
+	return nil
+}
+
+func event(t events.Type, code *models.SwiftCode) events.Event {
+	return events.Event{Type: t, Source: events.SourceImport, SwiftCode: code.SwiftCode, Data: code}
+}
+
+// existingCodes returns the stored rows of codes, by SWIFT code; the insert
+// leaves those untouched.
+func existingCodes(ctx context.Context, repo repository.Repository, codes []models.SwiftCode) (map[string]models.SwiftCode, error) {
+	lookup := make([]string, len(codes))
+	for i, c := range codes {
+		lookup[i] = c.SwiftCode
+	}
+	found, err := repo.GetSwiftCodesByCodes(ctx, lookup)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up existing codes: %w", err)
+	}
+	existing := make(map[string]models.SwiftCode, len(found))
+	for _, c := range found {
+		existing[c.SwiftCode] = c
+	}
+	return existing, nil
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"context"
+	"path/filepath"
+	"swift-api/pkg/events"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingRepo struct {
+	repository.Repository
+	batches   [][]models.SwiftCode
+	refreshed bool
+}
+
+func (r *recordingRepo) InsertSwiftCodes(_ context.Context, codes []models.SwiftCode) error {
+	r.batches = append(r.batches, codes)
+	return nil
+}
+
+func (r *recordingRepo) GetSwiftCodesByCodes(context.Context, []string) ([]models.SwiftCode, error) {
+	return nil, nil
+}
+
+func (r *recordingRepo) RefreshInstitutions(_ context.Context, institutionCodes []string) error {
+	r.refreshed = len(r.batches) == 2 && institutionCodes == nil
+	return nil
+}
+
+func TestImport(t *testing.T) {
+	t.Run("Headquarters are inserted before branches", func(t *testing.T) {
+		repo := &recordingRepo{}
+		stats, err := Import(context.Background(), repo, filepath.Join("..", "parser", "testdata", "test_swift_codes_advanced.csv"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Stats{Parsed: 6, Headquarters: 3, Branches: 3}, stats)
+		assert.Len(t, repo.batches, 2)
+		for _, c := range repo.batches[0] {
+			assert.True(t, c.IsHeadquarter)
+		}
+		for _, c := range repo.batches[1] {
+			assert.False(t, c.IsHeadquarter)
+		}
+		assert.True(t, repo.refreshed, "every institution is rebuilt after the inserts")
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := Import(context.Background(), &recordingRepo{}, "does-not-exist.csv")
+		assert.Error(t, err)
+	})
+}
+
+type publishedEvents []events.Event
+
+func (p *publishedEvents) Publish(_ context.Context, evs ...events.Event) {
+	*p = append(*p, evs...)
+}
+
+func TestImportEvents(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join("..", "parser", "testdata", "test_swift_codes_advanced.csv")
+	repo := repository.NewMemoryRepository()
+
+	var published publishedEvents
+	_, err := Import(ctx, repo, path, WithEvents(&published))
+	require.NoError(t, err)
+	require.Len(t, published, 6)
+	for i, e := range published {
+		assert.Equal(t, events.SwiftCodeCreated, e.Type)
+		assert.Equal(t, events.SourceImport, e.Source)
+		assert.Equal(t, e.SwiftCode, e.Data.SwiftCode)
+		assert.Equal(t, i < 3, e.Data.IsHeadquarter, "headquarters are announced first")
+	}
+
+	published = nil
+	_, err = Import(ctx, repo, path, WithEvents(&published))
+	require.NoError(t, err)
+	assert.Empty(t, published, "codes already present are not announced again")
+}
+
+func TestImportPromotesPlaceholders(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.InsertSwiftCodes(ctx, []models.SwiftCode{
+		{
+			SwiftCode: "GERMDEFFXXX", BankName: "UNKNOWN", TownName: "UNKNOWN",
+			CountryISO2: "DE", CountryName: "GERMANY", Timezone: "Etc/UTC", IsHeadquarter: true,
+		},
+		{
+			SwiftCode: "TESTPLHQXXX", BankName: "Edited HQ Bank", TownName: "WARSAW",
+			CountryISO2: "PL", CountryName: "POLAND", Timezone: "Europe/Warsaw", IsHeadquarter: true,
+		},
+	}))
+
+	var published publishedEvents
+	stats, err := Import(ctx, repo, filepath.Join("..", "parser", "testdata", "test_swift_codes_advanced.csv"), WithEvents(&published))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Promoted)
+
+	var got []string
+	for _, e := range published {
+		got = append(got, string(e.Type)+" "+e.SwiftCode)
+	}
+	assert.Equal(t, []string{
+		"swift_code.created BANKFRPPXXX",
+		"swift_code.placeholder_promoted GERMDEFFXXX",
+		"swift_code.created TESTPLHQ001",
+		"swift_code.created TESTPLHQ002",
+		"swift_code.created GERMDEFF001",
+	}, got)
+
+	promoted, err := repo.GetSwiftCodeDetails(ctx, "GERMDEFFXXX")
+	require.NoError(t, err)
+	assert.Equal(t, "German HQ", promoted.BankName)
+	kept, err := repo.GetSwiftCodeDetails(ctx, "TESTPLHQXXX")
+	require.NoError(t, err)
+	assert.Equal(t, "Edited HQ Bank", kept.BankName, "codes that are not placeholders are left as stored")
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Request ID is attached from context", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&buf, "info", "json")
+		assert.NoError(t, err)
+
+		ctx := WithRequestID(context.Background(), "req-123")
+		logger.With("component", "test").InfoContext(ctx, "hello")
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "req-123", line["request_id"])
+		assert.Equal(t, "test", line["component"])
+	})
+
+	t.Run("Level filters records", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&buf, "warn", "text")
+		assert.NoError(t, err)
+
+		logger.Info("dropped")
+		logger.Warn("kept")
+		assert.NotContains(t, buf.String(), "dropped")
+		assert.Contains(t, buf.String(), "kept")
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		_, err := New(&bytes.Buffer{}, "loud", "json")
+		assert.Error(t, err)
+		_, err = New(&bytes.Buffer{}, "info", "xml")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"swift-api/pkg/cache"
+	"swift-api/pkg/httpx"
+	"swift-api/pkg/importer"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Metrics struct {
+	registry     *prometheus.Registry
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	grpcRequests *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	importRows   *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "swift_http_requests_total",
+			Help: "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "swift_http_request_duration_seconds",
+			Help:    "HTTP request latency by method and route template.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "swift_grpc_requests_total",
+			Help: "gRPC calls by full method and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "swift_grpc_request_duration_seconds",
+			Help:    "gRPC call latency by full method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		importRows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "swift_import_rows_total",
+			Help: "Rows processed by the CSV import, by result (parsed, skipped, placeholder).",
+		}, []string{"result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.grpcRequests,
+		m.grpcDuration,
+		m.importRows,
+	)
+	return m
+}
+
+// Register adds extra collectors, such as cache statistics, to the registry
+// served by Handler.
+func (m *Metrics) Register(c ...prometheus.Collector) {
+	m.registry.MustRegister(c...)
+}
+
+// RegisterDB exports the connection pool statistics of db under the given
+// name.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterCache exports hit, miss, eviction and size statistics of a cache
+// under the given name. stats is read on every scrape.
+func (m *Metrics) RegisterCache(name string, stats func() cache.Stats) {
+	labels := prometheus.Labels{"cache": name}
+	counter := func(result string, value func(cache.Stats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "swift_cache_requests_total",
+			Help:        "Cache lookups by result (hit, miss).",
+			ConstLabels: prometheus.Labels{"cache": name, "result": result},
+		}, func() float64 { return float64(value(stats())) })
+	}
+
+	m.registry.MustRegister(
+		counter("hit", func(s cache.Stats) uint64 { return s.Hits }),
+		counter("miss", func(s cache.Stats) uint64 { return s.Misses }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "swift_cache_evictions_total",
+			Help:        "Entries evicted to stay within the cache size.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(stats().Evictions) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "swift_cache_entries",
+			Help:        "Entries currently held in the cache.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(stats().Entries) }),
+	)
+}
+
+func (m *Metrics) RecordImport(stats importer.Stats) {
+	m.importRows.WithLabelValues("parsed").Add(float64(stats.Parsed))
+	m.importRows.WithLabelValues("skipped").Add(float64(stats.Skipped))
+	m.importRows.WithLabelValues("placeholder").Add(float64(stats.Placeholders))
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records request counts and latencies labelled with the mux route
+// template, so /v1/swift-codes/{swift-code} is one series rather than one per
+// BIC.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := httpx.NewStatusRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		m.requests.WithLabelValues(r.Method, route, strconv.Itoa(rec.Status())).Inc()
+		m.duration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"swift-api/pkg/cache"
+	"swift-api/pkg/importer"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New()
+
+	r := mux.NewRouter()
+	r.Use(m.Middleware)
+	r.HandleFunc("/v1/swift-codes/{swift-code}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+
+	for _, code := range []string{"AAAAPLPWXXX", "BBBBPLPWXXX"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/swift-codes/"+code, nil))
+	}
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/swift.v1.SwiftCodes/GetSwiftCode"}
+	_, _ = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "SWIFT code not found")
+	})
+	m.RecordImport(importer.Stats{Parsed: 10, Skipped: 2, Placeholders: 1})
+	m.RegisterCache("lookups", func() cache.Stats {
+		return cache.Stats{Hits: 7, Misses: 3, Evictions: 1, Entries: 42}
+	})
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	out := string(body)
+
+	t.Run("Requests use route template", func(t *testing.T) {
+		assert.Contains(t, out, `swift_http_requests_total{code="404",method="GET",route="/v1/swift-codes/{swift-code}"} 2`)
+		assert.NotContains(t, out, "AAAAPLPWXXX")
+		assert.Contains(t, out, `swift_http_request_duration_seconds_count{method="GET",route="/v1/swift-codes/{swift-code}"} 2`)
+	})
+
+	t.Run("gRPC calls use the full method", func(t *testing.T) {
+		assert.Contains(t, out, `swift_grpc_requests_total{code="NotFound",method="/swift.v1.SwiftCodes/GetSwiftCode"} 1`)
+		assert.Contains(t, out, `swift_grpc_request_duration_seconds_count{method="/swift.v1.SwiftCodes/GetSwiftCode"} 1`)
+	})
+
+	t.Run("Import counters", func(t *testing.T) {
+		assert.Contains(t, out, `swift_import_rows_total{result="parsed"} 10`)
+		assert.Contains(t, out, `swift_import_rows_total{result="skipped"} 2`)
+		assert.Contains(t, out, `swift_import_rows_total{result="placeholder"} 1`)
+	})
+
+	t.Run("Cache statistics", func(t *testing.T) {
+		assert.Contains(t, out, `swift_cache_requests_total{cache="lookups",result="hit"} 7`)
+		assert.Contains(t, out, `swift_cache_requests_total{cache="lookups",result="miss"} 3`)
+		assert.Contains(t, out, `swift_cache_evictions_total{cache="lookups"} 1`)
+		assert.Contains(t, out, `swift_cache_entries{cache="lookups"} 42`)
+	})
+}
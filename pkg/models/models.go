@@ -12,3 +12,33 @@ type SwiftCode struct {
 	HeadquarterSWIFTCode *string     `json:"headquarterSwiftCode,omitempty"`
 	Branches             []SwiftCode `json:"branches,omitempty"`
 }
+
+// Institution groups the headquarters whose SWIFT codes share the first four
+// characters, the institution code, ordered by country and code.
+type Institution struct {
+	Code         string                   `json:"institutionCode"`
+	Headquarters []InstitutionHeadquarter `json:"headquarters"`
+}
+
+// Name is the bank name most of the headquarters share; on a tie, the one
+// that comes first.
+func (i Institution) Name() string {
+	var name string
+	names := make(map[string]int)
+	for _, hq := range i.Headquarters {
+		names[hq.BankName]++
+		if names[hq.BankName] > names[name] {
+			name = hq.BankName
+		}
+	}
+	return name
+}
+
+type InstitutionHeadquarter struct {
+	SwiftCode   string `json:"swiftCode"`
+	BankName    string `json:"bankName"`
+	TownName    string `json:"townName"`
+	CountryISO2 string `json:"countryISO2"`
+	CountryName string `json:"countryName"`
+	BranchCount int    `json:"branchCount"`
+}
@@ -0,0 +1,121 @@
+// Renders the OpenAPI document as a list of operations grouped by tag.
+(function () {
+  "use strict";
+
+  const main = document.getElementById("operations");
+  const methods = ["get", "post", "put", "patch", "delete"];
+
+  function el(tag, attrs, ...children) {
+    const node = document.createElement(tag);
+    Object.entries(attrs || {}).forEach(([k, v]) => node.setAttribute(k, v));
+    children.flat().forEach((c) => node.append(c));
+    return node;
+  }
+
+  function lookup(spec, ref) {
+    return ref.slice(2).split("/")
+      .map((t) => t.replace(/~1/g, "/").replace(/~0/g, "~"))
+      .reduce((node, t) => node[t], spec);
+  }
+
+  function resolve(spec, obj) {
+    while (obj && obj.$ref) obj = lookup(spec, obj.$ref);
+    return obj;
+  }
+
+  // flatten merges a schema with the one its $ref points at, since OpenAPI
+  // 3.1 allows keywords next to $ref.
+  function flatten(spec, schema) {
+    if (!schema || !schema.$ref) return schema || {};
+    const base = flatten(spec, lookup(spec, schema.$ref));
+    const out = Object.assign({}, base, schema);
+    out.properties = Object.assign({}, base.properties, schema.properties);
+    delete out.$ref;
+    return out;
+  }
+
+  // example builds a sample value from a schema, following $refs.
+  function example(spec, schema, depth) {
+    schema = flatten(spec, schema);
+    if (depth > 6) return null;
+    if (schema.example !== undefined) return schema.example;
+    if (schema.const !== undefined) return schema.const;
+    if (schema.enum) return schema.enum[0];
+    if (schema.oneOf) return example(spec, schema.oneOf[0], depth + 1);
+    let out = {};
+    if (schema.allOf) schema.allOf.forEach((s) => Object.assign(out, example(spec, s, depth + 1)));
+    const type = Array.isArray(schema.type) ? schema.type[0] : schema.type;
+    switch (type) {
+      case "array": return [example(spec, schema.items, depth + 1)];
+      case "string": return schema.format === "date-time" ? "2024-01-01T00:00:00Z" : "string";
+      case "integer": case "number": return 0;
+      case "boolean": return true;
+    }
+    Object.entries(schema.properties || {}).forEach(([k, v]) => { out[k] = example(spec, v, depth + 1); });
+    return out;
+  }
+
+  function security(spec, op) {
+    const reqs = op.security || spec.security || [];
+    if (reqs.length === 0) return "public";
+    if (reqs.some((r) => Object.keys(r).length === 0)) return "optional auth";
+    return "auth: " + reqs.map((r) => Object.keys(r).join("+")).join(" | ");
+  }
+
+  function content(spec, body) {
+    const parts = [];
+    Object.entries((body && body.content) || {}).forEach(([type, media]) => {
+      const sample = type === "application/json" ? JSON.stringify(example(spec, media.schema, 0), null, 2) : "";
+      parts.push(el("p", {}, el("code", {}, type)));
+      if (sample) parts.push(el("pre", {}, sample));
+    });
+    return parts;
+  }
+
+  function operation(spec, path, method, op) {
+    const rows = Object.entries(op.responses || {}).map(([status, resp]) => {
+      resp = resolve(spec, resp);
+      return el("tr", {}, el("td", {}, status), el("td", {}, resp.description || "", content(spec, resp)));
+    });
+    const params = (op.parameters || []).concat(spec.paths[path].parameters || []).map((p) => {
+      p = resolve(spec, p);
+      return el("tr", {}, el("td", {}, el("code", {}, p.name)), el("td", {}, p.in), el("td", {}, p.description || ""));
+    });
+    const body = el("div", { class: "body" });
+    if (op.description) body.append(el("p", {}, op.description));
+    if (params.length) body.append(el("h4", {}, "Parameters"), el("table", {}, params));
+    if (op.requestBody) body.append(el("h4", {}, "Request body"), content(spec, resolve(spec, op.requestBody)));
+    body.append(el("h4", {}, "Responses"), el("table", {}, el("tr", {}, el("th", {}, "Status"), el("th", {}, "Description")), rows));
+
+    return el("details", {},
+      el("summary", {},
+        el("span", { class: "method " + method }, method.toUpperCase()),
+        el("code", {}, path),
+        el("span", {}, op.summary || ""),
+        el("span", { class: "lock" }, security(spec, op))),
+      body);
+  }
+
+  function render(spec) {
+    document.title = spec.info.title;
+    document.getElementById("title").textContent = spec.info.title;
+    document.getElementById("version").textContent = "v" + spec.info.version;
+
+    const groups = new Map((spec.tags || []).map((t) => [t.name, []]));
+    Object.entries(spec.paths).forEach(([path, item]) => {
+      methods.filter((m) => item[m]).forEach((m) => {
+        const tag = (item[m].tags || ["Other"])[0];
+        if (!groups.has(tag)) groups.set(tag, []);
+        groups.get(tag).push(operation(spec, path, m, item[m]));
+      });
+    });
+
+    main.replaceChildren();
+    groups.forEach((ops, tag) => { if (ops.length) main.append(el("h2", {}, tag), ops); });
+  }
+
+  fetch(main.dataset.spec)
+    .then((r) => { if (!r.ok) throw new Error(r.status + " " + r.statusText); return r.json(); })
+    .then(render)
+    .catch((err) => { main.textContent = "Could not load the API description: " + err.message; });
+})();
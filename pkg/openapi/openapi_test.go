@@ -0,0 +1,141 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"swift-api/pkg/openapi"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON(t *testing.T) {
+	spec, err := openapi.JSON()
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(spec, &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+
+	t.Run("Keeps the order of openapi.yaml", func(t *testing.T) {
+		first := strings.Index(string(spec), `"/v1/swift-codes/{swift-code}"`)
+		last := strings.Index(string(spec), `"/metrics"`)
+		assert.Positive(t, first)
+		assert.Greater(t, last, first)
+	})
+
+	t.Run("Every reference resolves", func(t *testing.T) {
+		var refs []string
+		var walk func(any)
+		walk = func(node any) {
+			switch n := node.(type) {
+			case map[string]any:
+				if ref, ok := n["$ref"].(string); ok {
+					refs = append(refs, ref)
+				}
+				for _, v := range n {
+					walk(v)
+				}
+			case []any:
+				for _, v := range n {
+					walk(v)
+				}
+			}
+		}
+		walk(doc)
+		require.NotEmpty(t, refs)
+
+		for _, ref := range refs {
+			var node any = doc
+			for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+				obj, ok := node.(map[string]any)
+				require.True(t, ok, ref)
+				node, ok = obj[token]
+				require.True(t, ok, ref)
+			}
+		}
+	})
+}
+
+func TestHandlers(t *testing.T) {
+	t.Run("Spec is served as JSON", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		openapi.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.True(t, json.Valid(rec.Body.Bytes()))
+	})
+
+	docs := http.StripPrefix("/docs/", openapi.DocsHandler("/v1/openapi.json"))
+
+	t.Run("Docs page points at the spec", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		docs.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, rec.Body.String(), `data-spec="/v1/openapi.json"`)
+		assert.NotContains(t, rec.Body.String(), "{{SPEC_URL}}")
+	})
+
+	t.Run("Docs assets are embedded", func(t *testing.T) {
+		for _, asset := range []string{"docs.js", "docs.css"} {
+			rec := httptest.NewRecorder()
+			docs.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/"+asset, nil))
+			assert.Equal(t, http.StatusOK, rec.Code, asset)
+		}
+
+		rec := httptest.NewRecorder()
+		docs.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/missing.js", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestValidator(t *testing.T) {
+	v, err := openapi.NewValidator()
+	require.NoError(t, err)
+
+	assert.Contains(t, v.Operations(), openapi.Operation{Method: "GET", Path: "/v1/swift-codes/{swift-code}"})
+	assert.Contains(t, v.Operations(), openapi.Operation{Method: "POST", Path: "/v1/imports:diff"})
+
+	const path = "/v1/swift-codes/{swift-code}"
+	branch := `{"address":"A","bankName":"B","countryISO2":"PL","countryName":"POLAND","isHeadquarter":false,"swiftCode":"AAAAPLPW123"}`
+
+	t.Run("Matching response", func(t *testing.T) {
+		assert.NoError(t, v.ValidateResponse("GET", path, 200, "application/json", []byte(branch)))
+		assert.NoError(t, v.ValidateResponse("GET", path, 404, "application/json; charset=utf-8", []byte(`{"message":"not found","requestId":"abc"}`)))
+	})
+
+	t.Run("Undocumented field", func(t *testing.T) {
+		body := strings.Replace(branch, `"address"`, `"townName":"T","address"`, 1)
+		assert.Error(t, v.ValidateResponse("GET", path, 200, "application/json", []byte(body)))
+	})
+
+	t.Run("Undocumented field in an extended schema", func(t *testing.T) {
+		v2 := `{"swiftCode":"AAAAPLPW123","bankName":"B","address":null,"townName":"T","countryISO2":"PL","countryName":"POLAND",` +
+			`"timezone":"Europe/Warsaw","isHeadquarter":false,"headquarterSwiftCode":"AAAAPLPWXXX",` +
+			`"_links":{"self":{"href":"/v2/swift-codes/AAAAPLPW123"},"headquarter":{"href":"/v2/swift-codes/AAAAPLPWXXX"},"country":{"href":"/v2/swift-codes/country/PL"}}}`
+		assert.NoError(t, v.ValidateResponse("GET", "/v2/swift-codes/{swift-code}", 200, "application/json", []byte(v2)))
+
+		extra := strings.Replace(v2, `"bankName"`, `"extra":1,"bankName"`, 1)
+		assert.Error(t, v.ValidateResponse("GET", "/v2/swift-codes/{swift-code}", 200, "application/json", []byte(extra)))
+	})
+
+	t.Run("Undocumented status, operation or content type", func(t *testing.T) {
+		assert.Error(t, v.ValidateResponse("GET", path, 418, "application/json", []byte(`{"message":"x"}`)))
+		assert.Error(t, v.ValidateResponse("PUT", path, 200, "application/json", []byte(branch)))
+		assert.Error(t, v.ValidateResponse("GET", path, 200, "text/html", []byte(branch)))
+	})
+
+	t.Run("Request bodies", func(t *testing.T) {
+		valid := `{"address":"A","bankName":"B","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true,"swiftCode":"AAAAPLPWXXX"}`
+		assert.NoError(t, v.ValidateRequest("POST", "/v1/swift-codes", "application/json", []byte(valid)))
+		assert.Error(t, v.ValidateRequest("POST", "/v1/swift-codes", "application/json", []byte(`{"swiftCode":"SHORT"}`)))
+		assert.NoError(t, v.ValidateRequest("POST", "/v1/imports:diff", "text/csv", []byte("a,b\n")))
+		assert.Error(t, v.ValidateRequest("GET", path, "application/json", []byte(`{}`)))
+	})
+}
@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const specURL = "openapi.json"
+
+// Operation is a documented method and path template, e.g. GET
+// /v1/swift-codes/{swift-code}.
+type Operation struct {
+	Method string
+	Path   string
+}
+
+// Validator checks request and response bodies against the schemas of the
+// specification. Only JSON bodies are validated; other media types only need
+// to be documented.
+type Validator struct {
+	spec     map[string]any
+	compiler *jsonschema.Compiler
+
+	mu      sync.Mutex
+	schemas map[string]*jsonschema.Schema
+}
+
+func NewValidator() (*Validator, error) {
+	spec, err := JSON()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(spec))
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(specURL, doc); err != nil {
+		return nil, err
+	}
+	return &Validator{
+		spec:     doc.(map[string]any),
+		compiler: compiler,
+		schemas:  make(map[string]*jsonschema.Schema),
+	}, nil
+}
+
+// Operations lists every documented operation, sorted by path and method.
+func (v *Validator) Operations() []Operation {
+	var ops []Operation
+	paths, _ := v.spec["paths"].(map[string]any)
+	for path, item := range paths {
+		for method := range item.(map[string]any) {
+			switch method {
+			case "get", "put", "post", "delete", "patch", "head", "options":
+				ops = append(ops, Operation{Method: strings.ToUpper(method), Path: path})
+			}
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+	return ops
+}
+
+// ValidateRequest checks a request body sent to the operation.
+func (v *Validator) ValidateRequest(method, path, contentType string, body []byte) error {
+	ptr := []string{"paths", path, strings.ToLower(method)}
+	op, err := v.lookup(ptr)
+	if err != nil {
+		return err
+	}
+	if _, ok := op.(map[string]any)["requestBody"]; !ok {
+		if len(body) > 0 {
+			return fmt.Errorf("%s %s: no request body is documented", method, path)
+		}
+		return nil
+	}
+	return v.validateContent(append(ptr, "requestBody"), contentType, body)
+}
+
+// ValidateResponse checks a response returned by the operation. Statuses
+// that are not documented are errors.
+func (v *Validator) ValidateResponse(method, path string, status int, contentType string, body []byte) error {
+	ptr := []string{"paths", path, strings.ToLower(method), "responses", strconv.Itoa(status)}
+	if _, err := v.lookup(ptr); err != nil {
+		return err
+	}
+	return v.validateContent(ptr, contentType, body)
+}
+
+func (v *Validator) validateContent(ptr []string, contentType string, body []byte) error {
+	ptr, err := v.resolve(ptr)
+	if err != nil {
+		return err
+	}
+	node, _ := v.lookup(ptr)
+	if _, ok := node.(map[string]any)["content"]; !ok {
+		if len(body) > 0 {
+			return fmt.Errorf("%s: no content is documented", pointer(ptr))
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%s: content type %q: %w", pointer(ptr), contentType, err)
+	}
+	ptr = append(ptr, "content", mediaType)
+	if _, err := v.lookup(ptr); err != nil {
+		return err
+	}
+	if mediaType != "application/json" {
+		return nil
+	}
+
+	schema, err := v.schema(append(ptr, "schema"))
+	if err != nil {
+		return err
+	}
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: invalid JSON: %w", pointer(ptr), err)
+	}
+	return schema.Validate(inst)
+}
+
+// resolve follows a $ref at ptr, e.g. into components/responses.
+func (v *Validator) resolve(ptr []string) ([]string, error) {
+	node, err := v.lookup(ptr)
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := node.(map[string]any)["$ref"].(string)
+	if !ok {
+		return ptr, nil
+	}
+	ptr = nil
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		ptr = append(ptr, strings.NewReplacer("~1", "/", "~0", "~").Replace(token))
+	}
+	return v.resolve(ptr)
+}
+
+func (v *Validator) lookup(ptr []string) (any, error) {
+	var node any = v.spec
+	for i, token := range ptr {
+		obj, ok := node.(map[string]any)
+		if ok {
+			node, ok = obj[token]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s is not documented", pointer(ptr[:i+1]))
+		}
+	}
+	return node, nil
+}
+
+func (v *Validator) schema(ptr []string) (*jsonschema.Schema, error) {
+	loc := specURL + "#" + pointer(ptr)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok := v.schemas[loc]; ok {
+		return s, nil
+	}
+	s, err := v.compiler.Compile(loc)
+	if err != nil {
+		return nil, err
+	}
+	v.schemas[loc] = s
+	return s, nil
+}
+
+// pointer renders a JSON pointer usable as a URL fragment.
+func pointer(tokens []string) string {
+	escape := strings.NewReplacer("~", "~0", "/", "~1", "{", "%7B", "}", "%7D")
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(escape.Replace(token))
+	}
+	return b.String()
+}
@@ -1,10 +1,15 @@
 package parser
 
 import (
-	"log"
 	"swift-api/pkg/models"
 )
 
+// IsPlaceholder reports whether code is a headquarter made up by
+// FillMissingHeadquarters rather than read from a file.
+func IsPlaceholder(code models.SwiftCode) bool {
+	return code.IsHeadquarter && code.BankName == "UNKNOWN" && code.Timezone == "Etc/UTC"
+}
+
 func FillMissingHeadquarters(hq, branches []models.SwiftCode) []models.SwiftCode {
 	hqMap := make(map[string]struct{})
 	for _, h := range hq {
@@ -19,7 +24,7 @@ func FillMissingHeadquarters(hq, branches []models.SwiftCode) []models.SwiftCode
 		}
 		hqCode := *b.HeadquarterSWIFTCode
 		if _, exists := hqMap[hqCode]; !exists {
-			log.Printf("Adding placeholder HQ: %s", hqCode)
+			logger.Info("Adding placeholder HQ", "swift_code", hqCode)
 			placeholderHQs = append(placeholderHQs, models.SwiftCode{
 				CountryISO2:          "ZZ",
 				SwiftCode:            hqCode,
@@ -2,25 +2,75 @@ package parser
 
 import (
 	"encoding/csv"
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"strings"
 	"swift-api/pkg/models"
 )
 
+var logger = slog.Default()
+
+// SetLogger replaces the logger used for parse diagnostics.
+func SetLogger(l *slog.Logger) {
+	logger = l
+}
+
 func ParseCSV(filePath string) ([]models.SwiftCode, []models.SwiftCode, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		log.Println("Error opening file:", err)
+		logger.Error("Error opening file", "path", filePath, "error", err)
 		return nil, nil, err
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	_, err = reader.Read()
+	return ParseReader(file)
+}
+
+type Stats struct {
+	Parsed  int
+	Skipped int
+	// Errors says why each skipped record was skipped.
+	Errors []RowError
+}
+
+// RowError is a record left out of the result, by its line in the file.
+type RowError struct {
+	Line   int
+	Reason string
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// skip counts the current record as skipped for reason.
+func (s *Stats) skip(reader *csv.Reader, record []string, reason string) {
+	line, _ := reader.FieldPos(0)
+	logger.Warn("Skipping invalid record", "line", line, "reason", reason, "record", record)
+	s.Skipped++
+	s.Errors = append(s.Errors, RowError{Line: line, Reason: reason})
+}
+
+func ParseReader(r io.Reader) ([]models.SwiftCode, []models.SwiftCode, error) {
+	hq, branches, _, err := ParseReaderStats(r)
+	return hq, branches, err
+}
+
+// ParseReaderStats is ParseReader that also reports how many records were
+// parsed and how many were skipped as invalid, and why. Malformed CSV fails
+// the whole parse.
+func ParseReaderStats(r io.Reader) ([]models.SwiftCode, []models.SwiftCode, Stats, error) {
+	var stats Stats
+
+	reader := csv.NewReader(r)
+	// Records with too few fields are skipped one by one below.
+	reader.FieldsPerRecord = -1
+	_, err := reader.Read()
 	if err != nil {
-		log.Println("Error reading file header:", err)
-		return nil, nil, err
+		logger.Error("Error reading file header", "error", err)
+		return nil, nil, stats, err
 	}
 
 	var headquarters []models.SwiftCode
@@ -28,16 +78,25 @@ func ParseCSV(filePath string) ([]models.SwiftCode, []models.SwiftCode, error) {
 
 	for {
 		record, err := reader.Read()
-		if err != nil {
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			logger.Error("Error reading record", "error", err)
+			return nil, nil, stats, err
+		}
 
 		if len(record) < 8 {
-			log.Println("Skipping invalid record:", record)
+			stats.skip(reader, record, fmt.Sprintf("expected at least 8 fields, got %d", len(record)))
 			continue
 		}
-
 		swiftCode := record[1]
+		if len(swiftCode) != 11 {
+			stats.skip(reader, record, fmt.Sprintf("swift code %q is not 11 characters long", swiftCode))
+			continue
+		}
+		stats.Parsed++
+
 		isHeadquarter := strings.HasSuffix(swiftCode, "XXX")
 		var headquarterSWIFTCode *string
 		if !isHeadquarter {
@@ -70,5 +129,5 @@ func ParseCSV(filePath string) ([]models.SwiftCode, []models.SwiftCode, error) {
 		}
 	}
 
-	return headquarters, branches, nil
+	return headquarters, branches, stats, nil
 }
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"encoding/csv"
+	"os"
 	"path/filepath"
+	"strings"
 	"swift-api/pkg/models"
 	"testing"
 
@@ -103,12 +106,68 @@ func TestParseCSV(t *testing.T) {
 		assert.Len(t, hq, 1)
 		assert.Len(t, branches, 0)
 
-		assert.Equal(t, "EXTRAPLXXX", hq[0].SwiftCode)
+		assert.Equal(t, "EXTRPLPWXXX", hq[0].SwiftCode)
 		assert.Equal(t, "Extra Bank", hq[0].BankName)
 		assert.Equal(t, "POLAND", hq[0].CountryName)
 	})
 }
 
+func TestParseReaderStats(t *testing.T) {
+	t.Run("Counts parsed records", func(t *testing.T) {
+		file, err := os.Open(filepath.Join("testdata", "test_swift_codes_advanced.csv"))
+		assert.NoError(t, err)
+		defer file.Close()
+
+		hq, branches, stats, err := ParseReaderStats(file)
+		assert.NoError(t, err)
+		assert.Equal(t, len(hq)+len(branches), stats.Parsed)
+		assert.Equal(t, 0, stats.Skipped)
+	})
+
+	t.Run("Counts skipped records", func(t *testing.T) {
+		csv := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME\n" +
+			"PL,TESTPLHQXXX,BIC11,Test Bank,HQ Address,WARSAW,POLAND\n" +
+			"PL,TESTPLHQ001,BIC11,Test Branch,Branch Address,WARSAW,POLAND\n"
+		hq, branches, stats, err := ParseReaderStats(strings.NewReader(csv))
+		assert.NoError(t, err)
+		assert.Empty(t, hq)
+		assert.Empty(t, branches)
+		assert.Equal(t, Stats{Parsed: 0, Skipped: 2, Errors: []RowError{
+			{Line: 2, Reason: "expected at least 8 fields, got 7"},
+			{Line: 3, Reason: "expected at least 8 fields, got 7"},
+		}}, stats)
+	})
+
+	t.Run("Skips codes that are not 11 characters long", func(t *testing.T) {
+		input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+			"PL,SHORT,BIC11,Short Bank,Addr,WARSAW,POLAND,Europe/Warsaw\n" +
+			"PL,TESTPLPW1,BIC11,Nine,Addr,WARSAW,POLAND,Europe/Warsaw\n" +
+			"PL,TESTPLPW12,BIC11,Ten,Addr,WARSAW,POLAND,Europe/Warsaw\n" +
+			"PL,TESTPLPWXXXX,BIC11,Twelve,Addr,WARSAW,POLAND,Europe/Warsaw\n" +
+			"PL,TESTPLPW001,BIC11,Test Branch,Addr,WARSAW,POLAND,Europe/Warsaw\n"
+		hq, branches, stats, err := ParseReaderStats(strings.NewReader(input))
+		assert.NoError(t, err)
+		assert.Empty(t, hq)
+		assert.Len(t, branches, 1)
+		assert.Equal(t, Stats{Parsed: 1, Skipped: 4, Errors: []RowError{
+			{Line: 2, Reason: `swift code "SHORT" is not 11 characters long`},
+			{Line: 3, Reason: `swift code "TESTPLPW1" is not 11 characters long`},
+			{Line: 4, Reason: `swift code "TESTPLPW12" is not 11 characters long`},
+			{Line: 5, Reason: `swift code "TESTPLPWXXXX" is not 11 characters long`},
+		}}, stats)
+	})
+
+	t.Run("Returns malformed CSV errors", func(t *testing.T) {
+		input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n" +
+			"PL,TESTPLPWXXX,BIC11,Test Bank,Addr,WARSAW,POLAND,Europe/Warsaw\n" +
+			"PL,TESTPLPW001,BIC11,\"Unterminated,Addr,WARSAW,POLAND,Europe/Warsaw\n"
+		_, _, _, err := ParseReaderStats(strings.NewReader(input))
+
+		var parseErr *csv.ParseError
+		assert.ErrorAs(t, err, &parseErr)
+	})
+}
+
 func TestFillMissingHeadquarters(t *testing.T) {
 	t.Run("Insert placeholder if HQ missing", func(t *testing.T) {
 		branch1 := models.SwiftCode{
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Proxies are the networks of reverse proxies trusted to report the client
+// address in X-Forwarded-For. Requests from anywhere else are keyed by their
+// own address, so clients cannot pick their bucket by sending the header.
+type Proxies []*net.IPNet
+
+// ParseProxies reads CIDR ranges or single addresses.
+func ParseProxies(specs []string) (Proxies, error) {
+	var proxies Proxies
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if !strings.Contains(spec, "/") {
+			ip := net.ParseIP(spec)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", spec)
+			}
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 128
+			}
+			spec = fmt.Sprintf("%s/%d", spec, bits)
+		}
+		_, network, err := net.ParseCIDR(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", spec)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+func (p Proxies) trusted(ip net.IP) bool {
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address a request came from. When remoteAddr is a
+// trusted proxy, forwardedFor (the X-Forwarded-For values, in order) is read
+// from the right and the first address not itself a proxy is the client.
+func (p Proxies) ClientIP(remoteAddr string, forwardedFor []string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !p.trusted(ip) {
+		return host
+	}
+
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		if !p.trusted(hop) {
+			return hop.String()
+		}
+	}
+	return host
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether a request identified by key may proceed. The
+// in-memory implementation never fails; shared backends may return an error,
+// in which case callers should let the request through.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+type Policy struct {
+	Rate  float64
+	Burst int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type MemoryLimiter struct {
+	policy Policy
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter(policy Policy) *MemoryLimiter {
+	return &MemoryLimiter{
+		policy:  policy,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	burst := float64(l.policy.Burst)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.policy.Rate)
+	b.last = now
+
+	result := Result{Limit: l.policy.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.durationFor(1 - b.tokens)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = l.durationFor(burst - b.tokens)
+
+	return result, nil
+}
+
+func (l *MemoryLimiter) durationFor(tokens float64) time.Duration {
+	return time.Duration(tokens / l.policy.Rate * float64(time.Second))
+}
+
+// sweep drops buckets that have been idle long enough to refill completely,
+// since they are indistinguishable from a fresh bucket. It runs at most once
+// per refill period.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	full := l.durationFor(float64(l.policy.Burst))
+	if now.Sub(l.lastSweep) < full {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewMemoryLimiter(Policy{Rate: 1, Burst: 3})
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	t.Run("Burst is allowed", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			res, err := l.Allow(ctx, "client-a")
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 2-i, res.Remaining)
+		}
+	})
+
+	t.Run("Exhausted bucket is rejected", func(t *testing.T) {
+		res, err := l.Allow(ctx, "client-a")
+		assert.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 3, res.Limit)
+		assert.Equal(t, 0, res.Remaining)
+		assert.Equal(t, time.Second, res.RetryAfter)
+		assert.Equal(t, 3*time.Second, res.Reset)
+	})
+
+	t.Run("Keys are independent", func(t *testing.T) {
+		res, _ := l.Allow(ctx, "client-b")
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("Tokens refill over time", func(t *testing.T) {
+		now = now.Add(1500 * time.Millisecond)
+		res, _ := l.Allow(ctx, "client-a")
+		assert.True(t, res.Allowed)
+		res, _ = l.Allow(ctx, "client-a")
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	})
+
+	t.Run("Idle buckets are swept", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		_, _ = l.Allow(ctx, "client-c")
+		assert.Len(t, l.buckets, 1)
+	})
+}
+
+func TestProxies(t *testing.T) {
+	proxies, err := ParseProxies([]string{"10.0.0.0/8", " 192.168.1.1 ", "::1"})
+	require.NoError(t, err)
+	require.Len(t, proxies, 3)
+
+	for _, tc := range []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		want         string
+	}{
+		{"Direct client", "203.0.113.7:4000", nil, "203.0.113.7"},
+		{"Untrusted sender's header is ignored", "203.0.113.7:4000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"Trusted proxy", "10.1.2.3:4000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"Chained proxies", "10.1.2.3:4000", []string{"198.51.100.9, 198.51.100.1", "192.168.1.1"}, "198.51.100.1"},
+		{"IPv6 proxy", "[::1]:4000", []string{"2001:db8::1"}, "2001:db8::1"},
+		{"Proxy without header", "10.1.2.3:4000", nil, "10.1.2.3"},
+		{"Garbage stops the walk", "10.1.2.3:4000", []string{"198.51.100.1, unknown"}, "10.1.2.3"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, proxies.ClientIP(tc.remoteAddr, tc.forwardedFor))
+		})
+	}
+
+	t.Run("No proxies trusts no header", func(t *testing.T) {
+		assert.Equal(t, "10.1.2.3", Proxies(nil).ClientIP("10.1.2.3:4000", []string{"198.51.100.1"}))
+	})
+
+	t.Run("Invalid entries are rejected", func(t *testing.T) {
+		_, err := ParseProxies([]string{"10.0.0.0/33"})
+		assert.Error(t, err)
+		_, err = ParseProxies([]string{"proxy.internal"})
+		assert.Error(t, err)
+	})
+}
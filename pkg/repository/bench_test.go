@@ -0,0 +1,177 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"swift-api/internal/database"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+)
+
+const (
+	benchHeadquarters = 200
+	benchBranches     = 10
+)
+
+// countingRepo counts the calls the benchmarks make, each of which is one
+// query and so one round trip to a database server.
+type countingRepo struct {
+	repository.Repository
+	calls atomic.Int64
+}
+
+// counted wraps repo for one benchmark and reports its calls per iteration
+// as queries/op.
+func counted(b *testing.B, repo repository.Repository) repository.Repository {
+	c := &countingRepo{Repository: repo}
+	b.Cleanup(func() { b.ReportMetric(float64(c.calls.Load())/float64(b.N), "queries/op") })
+	return c
+}
+
+func (r *countingRepo) GetSwiftCodeDetails(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	r.calls.Add(1)
+	return r.Repository.GetSwiftCodeDetails(ctx, swiftCode)
+}
+
+func (r *countingRepo) GetBranchesByHeadquarter(ctx context.Context, hq string) ([]models.SwiftCode, error) {
+	r.calls.Add(1)
+	return r.Repository.GetBranchesByHeadquarter(ctx, hq)
+}
+
+func (r *countingRepo) GetSwiftCodeWithBranches(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	r.calls.Add(1)
+	return r.Repository.GetSwiftCodeWithBranches(ctx, swiftCode)
+}
+
+func (r *countingRepo) GetSwiftCodesByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	r.calls.Add(1)
+	return r.Repository.GetSwiftCodesByCountry(ctx, iso2)
+}
+
+func (r *countingRepo) GetHeadquartersByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	r.calls.Add(1)
+	return r.Repository.GetHeadquartersByCountry(ctx, iso2)
+}
+
+func benchCodes() []models.SwiftCode {
+	var codes []models.SwiftCode
+	for i := 0; i < benchHeadquarters; i++ {
+		hqCode := fmt.Sprintf("B%03dPLPWXXX", i)
+		codes = append(codes, models.SwiftCode{SwiftCode: hqCode, BankName: "BENCH BANK", TownName: "WARSZAWA",
+			CountryISO2: "PL", CountryName: "POLAND", Timezone: "Europe/Warsaw", IsHeadquarter: true})
+		for j := 0; j < benchBranches; j++ {
+			codes = append(codes, models.SwiftCode{SwiftCode: fmt.Sprintf("B%03dPLPW%03d", i, j), BankName: "BENCH BANK", TownName: "KRAKOW",
+				CountryISO2: "PL", CountryName: "POLAND", Timezone: "Europe/Warsaw", HeadquarterSWIFTCode: &hqCode})
+		}
+	}
+	return codes
+}
+
+// benchRepositories runs fn against SQLite and, when DB_URL is set, Postgres,
+// each seeded with benchHeadquarters headquarters of benchBranches branches.
+func benchRepositories(b *testing.B, fn func(b *testing.B, repo repository.Repository)) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cfg := database.DefaultConfig()
+	cfg.URL = "sqlite:" + filepath.Join(b.TempDir(), "swift.db")
+	db, err := database.ConnectDB(ctx, cfg, logger)
+	require.NoError(b, err)
+	b.Cleanup(func() { db.Close() })
+	require.NoError(b, database.Migrate(ctx, db, database.SQLite, logger))
+	sqlite := repository.NewSQLiteRepository(db, repository.WithLogger(logger))
+	require.NoError(b, sqlite.InsertSwiftCodes(ctx, benchCodes()))
+
+	b.Run("sqlite", func(b *testing.B) { fn(b, sqlite) })
+
+	b.Run("postgres", func(b *testing.B) {
+		if os.Getenv("DB_URL") == "" {
+			b.Skip("DB_URL is not set")
+		}
+		db, err := sql.Open("postgres", os.Getenv("DB_URL"))
+		require.NoError(b, err)
+		clear := func() {
+			_, err := db.Exec("DELETE FROM banks")
+			require.NoError(b, err)
+			_, err = db.Exec("DELETE FROM swift_codes")
+			require.NoError(b, err)
+		}
+		clear()
+		b.Cleanup(func() {
+			clear()
+			db.Close()
+		})
+
+		repo := repository.NewRepository(db, repository.WithLogger(logger))
+		require.NoError(b, repo.InsertSwiftCodes(ctx, benchCodes()))
+		fn(b, repo)
+	})
+}
+
+// BenchmarkHeadquarterLookup compares reading a headquarter and then its
+// branches with reading both in one joined query.
+func BenchmarkHeadquarterLookup(b *testing.B) {
+	ctx := context.Background()
+	benchRepositories(b, func(b *testing.B, repo repository.Repository) {
+		b.Run("two_queries", func(b *testing.B) {
+			repo := counted(b, repo)
+			for b.Loop() {
+				code, err := repo.GetSwiftCodeDetails(ctx, "B100PLPWXXX")
+				require.NoError(b, err)
+				code.Branches, err = repo.GetBranchesByHeadquarter(ctx, code.SwiftCode)
+				require.NoError(b, err)
+				require.Len(b, code.Branches, benchBranches)
+			}
+		})
+		b.Run("joined", func(b *testing.B) {
+			repo := counted(b, repo)
+			for b.Loop() {
+				code, err := repo.GetSwiftCodeWithBranches(ctx, "B100PLPWXXX")
+				require.NoError(b, err)
+				require.Len(b, code.Branches, benchBranches)
+			}
+		})
+	})
+}
+
+// BenchmarkCountryHeadquarters compares listing a country and reading the
+// branches of each headquarter with one joined query for the whole country.
+func BenchmarkCountryHeadquarters(b *testing.B) {
+	ctx := context.Background()
+	benchRepositories(b, func(b *testing.B, repo repository.Repository) {
+		b.Run("n_plus_one", func(b *testing.B) {
+			repo := counted(b, repo)
+			for b.Loop() {
+				codes, _, err := repo.GetSwiftCodesByCountry(ctx, "PL")
+				require.NoError(b, err)
+				var headquarters []models.SwiftCode
+				for _, code := range codes {
+					if !code.IsHeadquarter {
+						continue
+					}
+					code.Branches, err = repo.GetBranchesByHeadquarter(ctx, code.SwiftCode)
+					require.NoError(b, err)
+					headquarters = append(headquarters, code)
+				}
+				require.Len(b, headquarters, benchHeadquarters)
+			}
+		})
+		b.Run("joined", func(b *testing.B) {
+			repo := counted(b, repo)
+			for b.Loop() {
+				headquarters, _, err := repo.GetHeadquartersByCountry(ctx, "PL")
+				require.NoError(b, err)
+				require.Len(b, headquarters, benchHeadquarters)
+			}
+		})
+	})
+}
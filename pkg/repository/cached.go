@@ -0,0 +1,609 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"swift-api/pkg/cache"
+	"swift-api/pkg/models"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachedRepository is a read-through cache in front of another Repository.
+// Lookups by code, by headquarter and by country are cached in a
+// size-bounded LRU with a TTL; every mutation made through it invalidates
+// the affected entries, which are then not filled again for settleTime.
+// Reads under a context pinned to the primary bypass the cache.
+//
+// After Preload the cache holds the complete dataset, so a miss means the
+// row does not exist and reads never reach the inner repository.
+type CachedRepository struct {
+	inner  Repository
+	lru    *cache.LRU[string, any]
+	logger *slog.Logger
+
+	// mu orders cache fills against invalidations: a fill started before an
+	// invalidation (older gen) is dropped instead of re-adding stale data.
+	mu       sync.Mutex
+	gen      uint64
+	complete atomic.Bool
+	// invalidated holds when each key was last invalidated, for settleTime.
+	invalidated map[string]time.Time
+
+	// reloadMu serialises invalidate-and-reload of a complete cache, so a
+	// slower reload can never overwrite the result of a later mutation.
+	reloadMu sync.Mutex
+}
+
+// settleTime is how long after an invalidation a key is not filled by
+// read-through reads. Those may come from a replica that has not applied the
+// write yet, and caching their result would serve it for the whole TTL.
+const settleTime = 5 * time.Second
+
+type countryEntry struct {
+	codes []models.SwiftCode
+	name  string
+}
+
+func NewCachedRepository(inner Repository, size int, ttl time.Duration, logger *slog.Logger) *CachedRepository {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &CachedRepository{
+		inner:       inner,
+		lru:         cache.NewLRU[string, any](size, ttl),
+		logger:      logger,
+		invalidated: make(map[string]time.Time),
+	}
+}
+
+func codeKey(swiftCode string) string  { return "code:" + swiftCode }
+func branchesKey(hqCode string) string { return "branches:" + hqCode }
+func countryKey(iso2 string) string    { return "country:" + strings.ToUpper(iso2) }
+
+func (c *CachedRepository) Stats() cache.Stats {
+	return c.lru.Stats()
+}
+
+// Complete reports whether the cache currently holds the whole dataset.
+func (c *CachedRepository) Complete() bool {
+	return c.complete.Load()
+}
+
+func (c *CachedRepository) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// fill caches a value loaded by a read unless the cache was invalidated
+// since gen was taken or key was invalidated less than settleTime ago.
+func (c *CachedRepository) fill(gen uint64, key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if at, ok := c.invalidated[key]; ok && time.Since(at) < settleTime {
+		return
+	}
+	if gen == c.gen {
+		c.store(key, value)
+	}
+}
+
+// store must be called with mu held.
+func (c *CachedRepository) store(key string, value any) {
+	var evicted bool
+	if c.complete.Load() {
+		evicted = c.lru.AddWithTTL(key, value, 0)
+	} else {
+		evicted = c.lru.Add(key, value)
+	}
+	if evicted && c.complete.Swap(false) {
+		c.logger.Warn("Cache is too small for the dataset, falling back to read-through", "size", c.lru.Capacity())
+	}
+}
+
+func (c *CachedRepository) InsertSwiftCodes(ctx context.Context, swiftCodes []models.SwiftCode) error {
+	err := c.inner.InsertSwiftCodes(ctx, swiftCodes)
+	keys := make([]string, 0, 3*len(swiftCodes))
+	for _, code := range swiftCodes {
+		keys = append(keys, affectedKeys(&code)...)
+	}
+	c.invalidate(ctx, keys)
+	return err
+}
+
+func (c *CachedRepository) GetSwiftCodeDetails(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	if PinnedToPrimary(ctx) {
+		return c.inner.GetSwiftCodeDetails(ctx, swiftCode)
+	}
+	return c.details(ctx, swiftCode)
+}
+
+func (c *CachedRepository) details(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	key := codeKey(swiftCode)
+	if v, ok := c.lru.Get(key); ok {
+		return copyCode(v.(*models.SwiftCode)), nil
+	}
+	if c.complete.Load() {
+		return nil, nil
+	}
+
+	gen := c.generation()
+	code, err := c.inner.GetSwiftCodeDetails(ctx, swiftCode)
+	if err != nil {
+		return nil, err
+	}
+	c.fill(gen, key, copyCode(code))
+	return code, nil
+}
+
+// GetSwiftCodesByCodes serves the cached codes and asks the inner repository
+// for the rest in one call, caching what it finds and what it does not.
+func (c *CachedRepository) GetSwiftCodesByCodes(ctx context.Context, swiftCodes []string) ([]models.SwiftCode, error) {
+	if PinnedToPrimary(ctx) {
+		return c.inner.GetSwiftCodesByCodes(ctx, swiftCodes)
+	}
+
+	var found []models.SwiftCode
+	var misses []string
+	seen := make(map[string]bool, len(swiftCodes))
+	for _, swiftCode := range swiftCodes {
+		if seen[swiftCode] {
+			continue
+		}
+		seen[swiftCode] = true
+		if v, ok := c.lru.Get(codeKey(swiftCode)); ok {
+			if code := copyCode(v.(*models.SwiftCode)); code != nil {
+				found = append(found, *code)
+			}
+		} else if !c.complete.Load() {
+			misses = append(misses, swiftCode)
+		}
+	}
+
+	if len(misses) > 0 {
+		gen := c.generation()
+		loaded, err := c.inner.GetSwiftCodesByCodes(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		byCode := make(map[string]*models.SwiftCode, len(loaded))
+		for i := range loaded {
+			byCode[loaded[i].SwiftCode] = &loaded[i]
+		}
+		for _, swiftCode := range misses {
+			c.fill(gen, codeKey(swiftCode), copyCode(byCode[swiftCode]))
+		}
+		found = append(found, loaded...)
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].SwiftCode < found[j].SwiftCode })
+	return found, nil
+}
+
+// GetSwiftCodeWithBranches is served from the code and branches entries when
+// both are cached. Otherwise it takes one joined query and fills both.
+func (c *CachedRepository) GetSwiftCodeWithBranches(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	if PinnedToPrimary(ctx) {
+		return c.inner.GetSwiftCodeWithBranches(ctx, swiftCode)
+	}
+
+	if v, ok := c.lru.Get(codeKey(swiftCode)); ok {
+		code := copyCode(v.(*models.SwiftCode))
+		if code == nil || !code.IsHeadquarter {
+			return code, nil
+		}
+		if branches, ok := c.cachedBranches(ctx, swiftCode); ok {
+			code.Branches = branches
+			return code, nil
+		}
+	} else if c.complete.Load() {
+		return nil, nil
+	}
+
+	gen := c.generation()
+	code, err := c.inner.GetSwiftCodeWithBranches(ctx, swiftCode)
+	if err != nil {
+		return nil, err
+	}
+	if code == nil {
+		c.fill(gen, codeKey(swiftCode), (*models.SwiftCode)(nil))
+		return nil, nil
+	}
+	bare := copyCode(code)
+	bare.Branches = nil
+	c.fill(gen, codeKey(swiftCode), bare)
+	if code.IsHeadquarter {
+		c.fill(gen, branchesKey(swiftCode), code.Branches)
+	}
+	return code, nil
+}
+
+// GetBranchesByHeadquarters works like GetSwiftCodesByCodes, one branch list
+// per headquarter.
+func (c *CachedRepository) GetBranchesByHeadquarters(ctx context.Context, headquarterSWIFTCodes []string) ([]models.SwiftCode, error) {
+	if PinnedToPrimary(ctx) {
+		return c.inner.GetBranchesByHeadquarters(ctx, headquarterSWIFTCodes)
+	}
+
+	var branches []models.SwiftCode
+	var misses []string
+	seen := make(map[string]bool, len(headquarterSWIFTCodes))
+	for _, hq := range headquarterSWIFTCodes {
+		if seen[hq] {
+			continue
+		}
+		seen[hq] = true
+		if cached, ok := c.cachedBranches(ctx, hq); ok {
+			branches = append(branches, cached...)
+		} else {
+			misses = append(misses, hq)
+		}
+	}
+
+	if len(misses) > 0 {
+		gen := c.generation()
+		loaded, err := c.inner.GetBranchesByHeadquarters(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		byHQ := make(map[string][]models.SwiftCode, len(misses))
+		for _, b := range loaded {
+			byHQ[*b.HeadquarterSWIFTCode] = append(byHQ[*b.HeadquarterSWIFTCode], b)
+		}
+		for _, hq := range misses {
+			c.fill(gen, branchesKey(hq), byHQ[hq])
+		}
+		branches = append(branches, loaded...)
+	}
+
+	sort.Slice(branches, func(i, j int) bool { return branches[i].SwiftCode < branches[j].SwiftCode })
+	return branches, nil
+}
+
+func (c *CachedRepository) GetBranchesByHeadquarter(ctx context.Context, headquarterSWIFTCode string) ([]models.SwiftCode, error) {
+	if PinnedToPrimary(ctx) {
+		return c.inner.GetBranchesByHeadquarter(ctx, headquarterSWIFTCode)
+	}
+
+	key := branchesKey(headquarterSWIFTCode)
+	if v, ok := c.lru.Get(key); ok {
+		return v.([]models.SwiftCode), nil
+	}
+	if c.complete.Load() {
+		return nil, nil
+	}
+
+	gen := c.generation()
+	branches, err := c.inner.GetBranchesByHeadquarter(ctx, headquarterSWIFTCode)
+	if err != nil {
+		return nil, err
+	}
+	c.fill(gen, key, branches)
+	return branches, nil
+}
+
+// GetBranches and CountBranches are answered from the cached branch list of
+// the headquarter when there is one. Otherwise filtered queries go to the
+// inner repository, so a partial list is never cached.
+func (c *CachedRepository) GetBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) ([]models.SwiftCode, error) {
+	if q == (BranchQuery{}) {
+		return c.GetBranchesByHeadquarter(ctx, headquarterSWIFTCode)
+	}
+	if branches, ok := c.cachedBranches(ctx, headquarterSWIFTCode); ok {
+		return filterBranches(branches, q, true), nil
+	}
+	return c.inner.GetBranches(ctx, headquarterSWIFTCode, q)
+}
+
+func (c *CachedRepository) CountBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) (int, error) {
+	if branches, ok := c.cachedBranches(ctx, headquarterSWIFTCode); ok {
+		return len(filterBranches(branches, q, false)), nil
+	}
+	return c.inner.CountBranches(ctx, headquarterSWIFTCode, q)
+}
+
+// cachedBranches returns the full branch list of a headquarter if the cache
+// knows it; a complete cache knows that a missing list is empty.
+func (c *CachedRepository) cachedBranches(ctx context.Context, headquarterSWIFTCode string) ([]models.SwiftCode, bool) {
+	if PinnedToPrimary(ctx) {
+		return nil, false
+	}
+	if v, ok := c.lru.Get(branchesKey(headquarterSWIFTCode)); ok {
+		return v.([]models.SwiftCode), true
+	}
+	return nil, c.complete.Load()
+}
+
+func (c *CachedRepository) GetSwiftCodesByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	if PinnedToPrimary(ctx) {
+		return c.inner.GetSwiftCodesByCountry(ctx, iso2)
+	}
+
+	key := countryKey(iso2)
+	if v, ok := c.lru.Get(key); ok {
+		e := v.(countryEntry)
+		return e.codes, e.name, nil
+	}
+	if c.complete.Load() {
+		return nil, "", nil
+	}
+
+	gen := c.generation()
+	codes, name, err := c.inner.GetSwiftCodesByCountry(ctx, iso2)
+	if err != nil {
+		return nil, "", err
+	}
+	c.fill(gen, key, countryEntry{codes: codes, name: name})
+	return codes, name, nil
+}
+
+// GetHeadquartersByCountry is composed from the country and branches entries
+// when all of them are cached, and passed through otherwise.
+func (c *CachedRepository) GetHeadquartersByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	if !PinnedToPrimary(ctx) {
+		if v, ok := c.lru.Get(countryKey(iso2)); ok {
+			if headquarters, ok := c.cachedHeadquarters(ctx, v.(countryEntry)); ok {
+				return headquarters, v.(countryEntry).name, nil
+			}
+		} else if c.complete.Load() {
+			return nil, "", nil
+		}
+	}
+	return c.inner.GetHeadquartersByCountry(ctx, iso2)
+}
+
+func (c *CachedRepository) cachedHeadquarters(ctx context.Context, e countryEntry) ([]models.SwiftCode, bool) {
+	var headquarters []models.SwiftCode
+	for _, code := range e.codes {
+		if !code.IsHeadquarter {
+			continue
+		}
+		branches, ok := c.cachedBranches(ctx, code.SwiftCode)
+		if !ok {
+			return nil, false
+		}
+		code.Branches = branches
+		headquarters = append(headquarters, code)
+	}
+	return headquarters, true
+}
+
+// GetAllSwiftCodes is only used for diffs and preloading, which need the
+// database's view, so it is never cached.
+func (c *CachedRepository) GetAllSwiftCodes(ctx context.Context) ([]models.SwiftCode, error) {
+	return c.inner.GetAllSwiftCodes(ctx)
+}
+
+// GetSwiftCodesByInstitution spans countries and headquarters, which no
+// cache key covers, so it is not cached.
+func (c *CachedRepository) GetSwiftCodesByInstitution(ctx context.Context, institutionCode string) ([]models.SwiftCode, error) {
+	return c.inner.GetSwiftCodesByInstitution(ctx, institutionCode)
+}
+
+// The institution view is rebuilt outside the write path, so it is read
+// through to the inner repository.
+func (c *CachedRepository) RefreshInstitutions(ctx context.Context, institutionCodes []string) error {
+	return c.inner.RefreshInstitutions(ctx, institutionCodes)
+}
+
+func (c *CachedRepository) GetInstitution(ctx context.Context, institutionCode string) (*models.Institution, error) {
+	return c.inner.GetInstitution(ctx, institutionCode)
+}
+
+func (c *CachedRepository) SearchInstitutions(ctx context.Context, q InstitutionQuery) ([]models.Institution, int, error) {
+	return c.inner.SearchInstitutions(ctx, q)
+}
+
+func (c *CachedRepository) HeadquarterExists(ctx context.Context, swiftCode string) (bool, error) {
+	if PinnedToPrimary(ctx) {
+		return c.inner.HeadquarterExists(ctx, swiftCode)
+	}
+	code, err := c.details(ctx, swiftCode)
+	return code != nil && code.IsHeadquarter, err
+}
+
+func (c *CachedRepository) SwiftCodeExists(ctx context.Context, swiftCode string) (bool, error) {
+	if PinnedToPrimary(ctx) {
+		return c.inner.SwiftCodeExists(ctx, swiftCode)
+	}
+	code, err := c.details(ctx, swiftCode)
+	return code != nil, err
+}
+
+func (c *CachedRepository) IsPlaceholder(ctx context.Context, swiftCode string) (bool, error) {
+	if PinnedToPrimary(ctx) {
+		return c.inner.IsPlaceholder(ctx, swiftCode)
+	}
+	code, err := c.details(ctx, swiftCode)
+	return isPlaceholder(code), err
+}
+
+func (c *CachedRepository) UpdatePlaceholderSwiftCode(ctx context.Context, code models.SwiftCode) error {
+	keys := affectedKeys(&code)
+	keys = append(keys, c.previousKeys(ctx, code.SwiftCode)...)
+	err := c.inner.UpdatePlaceholderSwiftCode(ctx, code)
+	c.invalidate(ctx, keys)
+	return err
+}
+
+func (c *CachedRepository) UpdateSwiftCode(ctx context.Context, code models.SwiftCode) (bool, error) {
+	keys := affectedKeys(&code)
+	keys = append(keys, c.previousKeys(ctx, code.SwiftCode)...)
+	updated, err := c.inner.UpdateSwiftCode(ctx, code)
+	c.invalidate(ctx, keys)
+	return updated, err
+}
+
+func (c *CachedRepository) DeleteSwiftCode(ctx context.Context, swiftCode string) (bool, error) {
+	keys := append([]string{codeKey(swiftCode), branchesKey(swiftCode)}, c.previousKeys(ctx, swiftCode)...)
+	deleted, err := c.inner.DeleteSwiftCode(ctx, swiftCode)
+	c.invalidate(ctx, keys)
+	return deleted, err
+}
+
+// previousKeys finds the entries a change to swiftCode can affect through
+// its current country and headquarter.
+func (c *CachedRepository) previousKeys(ctx context.Context, swiftCode string) []string {
+	if v, ok := c.lru.Get(codeKey(swiftCode)); ok {
+		return affectedKeys(v.(*models.SwiftCode))
+	}
+	if c.complete.Load() {
+		return nil
+	}
+	prev, err := c.inner.GetSwiftCodeDetails(PinPrimary(ctx), swiftCode)
+	if err != nil || prev == nil {
+		return nil
+	}
+	return affectedKeys(prev)
+}
+
+func affectedKeys(code *models.SwiftCode) []string {
+	if code == nil {
+		return nil
+	}
+	keys := []string{codeKey(code.SwiftCode), branchesKey(code.SwiftCode), countryKey(code.CountryISO2)}
+	if code.HeadquarterSWIFTCode != nil {
+		keys = append(keys, branchesKey(*code.HeadquarterSWIFTCode))
+	}
+	return keys
+}
+
+// invalidate drops keys. A complete cache reloads them from the primary
+// instead so it stays complete; if that fails it degrades to read-through.
+func (c *CachedRepository) invalidate(ctx context.Context, keys []string) {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	c.mu.Lock()
+	c.gen++
+	now := time.Now()
+	for key, at := range c.invalidated {
+		if now.Sub(at) >= settleTime {
+			delete(c.invalidated, key)
+		}
+	}
+	for _, key := range keys {
+		c.lru.Remove(key)
+		c.invalidated[key] = now
+	}
+	c.mu.Unlock()
+
+	if !c.complete.Load() {
+		return
+	}
+	if err := c.reload(PinPrimary(ctx), keys); err != nil && c.complete.Swap(false) {
+		c.logger.WarnContext(ctx, "Could not refresh preloaded cache, falling back to read-through", "error", err)
+	}
+}
+
+func (c *CachedRepository) reload(ctx context.Context, keys []string) error {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		kind, id, _ := strings.Cut(key, ":")
+		var value any
+		switch kind {
+		case "code":
+			code, err := c.inner.GetSwiftCodeDetails(ctx, id)
+			if err != nil {
+				return err
+			}
+			if code == nil {
+				continue
+			}
+			value = code
+		case "branches":
+			branches, err := c.inner.GetBranchesByHeadquarter(ctx, id)
+			if err != nil {
+				return err
+			}
+			if len(branches) == 0 {
+				continue
+			}
+			value = branches
+		case "country":
+			codes, name, err := c.inner.GetSwiftCodesByCountry(ctx, id)
+			if err != nil {
+				return err
+			}
+			if len(codes) == 0 {
+				continue
+			}
+			value = countryEntry{codes: codes, name: name}
+		}
+
+		c.mu.Lock()
+		c.store(key, value)
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// Preload replaces the cache contents with the complete dataset. Entries
+// loaded this way do not expire; call Preload again to pick up changes made
+// by other processes.
+func (c *CachedRepository) Preload(ctx context.Context) error {
+	codes, err := c.inner.GetAllSwiftCodes(PinPrimary(ctx))
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string]any, len(codes))
+	branches := make(map[string][]models.SwiftCode)
+	countries := make(map[string]countryEntry)
+	for i := range codes {
+		code := codes[i]
+		entries[codeKey(code.SwiftCode)] = &code
+		if code.HeadquarterSWIFTCode != nil {
+			hq := *code.HeadquarterSWIFTCode
+			branches[hq] = append(branches[hq], code)
+		}
+		iso := strings.ToUpper(code.CountryISO2)
+		country := countries[iso]
+		country.codes = append(country.codes, code)
+		country.name = code.CountryName
+		countries[iso] = country
+	}
+	for hq, b := range branches {
+		sort.Slice(b, func(i, j int) bool { return b[i].SwiftCode < b[j].SwiftCode })
+		entries[branchesKey(hq)] = b
+	}
+	for iso, country := range countries {
+		entries[countryKey(iso)] = country
+	}
+
+	if capacity := c.lru.Capacity(); capacity > 0 && len(entries) > capacity {
+		return fmt.Errorf("cache size %d is too small to preload %d entries", capacity, len(entries))
+	}
+
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.lru.Purge()
+	for key, value := range entries {
+		c.lru.AddWithTTL(key, value, 0)
+	}
+	c.complete.Store(true)
+
+	c.logger.InfoContext(ctx, "Preloaded cache", "swift_codes", len(codes), "entries", len(entries))
+	return nil
+}
+
+func copyCode(code *models.SwiftCode) *models.SwiftCode {
+	if code == nil {
+		return nil
+	}
+	cp := *code
+	return &cp
+}
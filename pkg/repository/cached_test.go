@@ -0,0 +1,446 @@
+package repository_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+)
+
+// mapRepo is a minimal map-backed Repository that counts reads.
+type mapRepo struct {
+	repository.Repository
+	mu    sync.Mutex
+	codes map[string]models.SwiftCode
+	reads int
+}
+
+func newMapRepo(codes ...models.SwiftCode) *mapRepo {
+	r := &mapRepo{codes: map[string]models.SwiftCode{}}
+	for _, c := range codes {
+		r.codes[c.SwiftCode] = c
+	}
+	return r
+}
+
+func (r *mapRepo) readCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reads
+}
+
+func (r *mapRepo) filter(match func(models.SwiftCode) bool) []models.SwiftCode {
+	var out []models.SwiftCode
+	for _, c := range r.codes {
+		if match(c) {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SwiftCode < out[j].SwiftCode })
+	return out
+}
+
+func (r *mapRepo) InsertSwiftCodes(_ context.Context, codes []models.SwiftCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range codes {
+		if _, ok := r.codes[c.SwiftCode]; !ok {
+			r.codes[c.SwiftCode] = c
+		}
+	}
+	return nil
+}
+
+func (r *mapRepo) GetSwiftCodeDetails(_ context.Context, swiftCode string) (*models.SwiftCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	c, ok := r.codes[swiftCode]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (r *mapRepo) GetBranchesByHeadquarter(_ context.Context, hq string) ([]models.SwiftCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	return r.filter(func(c models.SwiftCode) bool {
+		return c.HeadquarterSWIFTCode != nil && *c.HeadquarterSWIFTCode == hq
+	}), nil
+}
+
+func (r *mapRepo) GetBranches(ctx context.Context, hq string, q repository.BranchQuery) ([]models.SwiftCode, error) {
+	branches, err := r.GetBranchesByHeadquarter(ctx, hq)
+	if q.Limit > 0 && len(branches) > q.Limit {
+		branches = branches[:q.Limit]
+	}
+	return branches, err
+}
+
+func (r *mapRepo) GetSwiftCodesByCodes(_ context.Context, swiftCodes []string) ([]models.SwiftCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	return r.filter(func(c models.SwiftCode) bool {
+		for _, code := range swiftCodes {
+			if c.SwiftCode == code {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (r *mapRepo) GetBranchesByHeadquarters(_ context.Context, hqs []string) ([]models.SwiftCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	return r.filter(func(c models.SwiftCode) bool {
+		for _, hq := range hqs {
+			if c.HeadquarterSWIFTCode != nil && *c.HeadquarterSWIFTCode == hq {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (r *mapRepo) GetSwiftCodeWithBranches(_ context.Context, swiftCode string) (*models.SwiftCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	c, ok := r.codes[swiftCode]
+	if !ok {
+		return nil, nil
+	}
+	if c.IsHeadquarter {
+		c.Branches = r.filter(func(b models.SwiftCode) bool {
+			return b.HeadquarterSWIFTCode != nil && *b.HeadquarterSWIFTCode == swiftCode
+		})
+	}
+	return &c, nil
+}
+
+func (r *mapRepo) GetHeadquartersByCountry(_ context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	headquarters := r.filter(func(c models.SwiftCode) bool { return c.CountryISO2 == strings.ToUpper(iso2) && c.IsHeadquarter })
+	if len(headquarters) == 0 {
+		return nil, "", nil
+	}
+	for i := range headquarters {
+		hq := headquarters[i].SwiftCode
+		headquarters[i].Branches = r.filter(func(b models.SwiftCode) bool {
+			return b.HeadquarterSWIFTCode != nil && *b.HeadquarterSWIFTCode == hq
+		})
+	}
+	return headquarters, headquarters[0].CountryName, nil
+}
+
+func (r *mapRepo) GetSwiftCodesByCountry(_ context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	codes := r.filter(func(c models.SwiftCode) bool { return c.CountryISO2 == strings.ToUpper(iso2) })
+	if len(codes) == 0 {
+		return nil, "", nil
+	}
+	return codes, codes[0].CountryName, nil
+}
+
+func (r *mapRepo) GetAllSwiftCodes(context.Context) ([]models.SwiftCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.filter(func(models.SwiftCode) bool { return true }), nil
+}
+
+func (r *mapRepo) SwiftCodeExists(_ context.Context, swiftCode string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	_, ok := r.codes[swiftCode]
+	return ok, nil
+}
+
+func (r *mapRepo) UpdateSwiftCode(_ context.Context, code models.SwiftCode) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.codes[code.SwiftCode]
+	if ok {
+		r.codes[code.SwiftCode] = code
+	}
+	return ok, nil
+}
+
+func (r *mapRepo) DeleteSwiftCode(_ context.Context, swiftCode string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.codes[swiftCode]
+	delete(r.codes, swiftCode)
+	return ok, nil
+}
+
+func hq(code, iso2 string) models.SwiftCode {
+	return models.SwiftCode{SwiftCode: code, BankName: "BANK", TownName: "TOWN", CountryISO2: iso2, CountryName: "COUNTRY " + iso2, Timezone: "Europe/Warsaw", IsHeadquarter: true}
+}
+
+func branch(code, hqCode, iso2 string) models.SwiftCode {
+	b := hq(code, iso2)
+	b.IsHeadquarter = false
+	b.HeadquarterSWIFTCode = &hqCode
+	return b
+}
+
+func TestCachedRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Serves repeated lookups from cache", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"), branch("AAAAPLPW123", "AAAAPLPWXXX", "PL"))
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+
+		for i := 0; i < 3; i++ {
+			code, err := cached.GetSwiftCodeDetails(ctx, "AAAAPLPWXXX")
+			require.NoError(t, err)
+			assert.Equal(t, "AAAAPLPWXXX", code.SwiftCode)
+			branches, err := cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+			require.NoError(t, err)
+			assert.Len(t, branches, 1)
+		}
+
+		assert.Equal(t, 2, inner.readCount())
+		stats := cached.Stats()
+		assert.Equal(t, uint64(4), stats.Hits)
+		assert.Equal(t, uint64(2), stats.Misses)
+	})
+
+	t.Run("Existence checks reuse cached details", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"))
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+
+		exists, _ := cached.SwiftCodeExists(ctx, "AAAAPLPWXXX")
+		assert.True(t, exists)
+		isHQ, _ := cached.HeadquarterExists(ctx, "AAAAPLPWXXX")
+		assert.True(t, isHQ)
+		missing, _ := cached.SwiftCodeExists(ctx, "BBBBPLPWXXX")
+		assert.False(t, missing)
+		missing, _ = cached.SwiftCodeExists(ctx, "BBBBPLPWXXX")
+		assert.False(t, missing)
+
+		assert.Equal(t, 2, inner.readCount())
+	})
+
+	t.Run("Mutations invalidate affected entries", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"))
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+
+		code, _ := cached.GetSwiftCodeDetails(ctx, "AAAAPLPW123")
+		assert.Nil(t, code)
+		branches, _ := cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+		assert.Empty(t, branches)
+		codes, _, _ := cached.GetSwiftCodesByCountry(ctx, "pl")
+		assert.Len(t, codes, 1)
+
+		require.NoError(t, cached.InsertSwiftCodes(ctx, []models.SwiftCode{branch("AAAAPLPW123", "AAAAPLPWXXX", "PL")}))
+
+		code, _ = cached.GetSwiftCodeDetails(ctx, "AAAAPLPW123")
+		assert.NotNil(t, code)
+		branches, _ = cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+		assert.Len(t, branches, 1)
+		codes, _, _ = cached.GetSwiftCodesByCountry(ctx, "PL")
+		assert.Len(t, codes, 2)
+
+		renamed := branch("AAAAPLPW123", "AAAAPLPWXXX", "PL")
+		renamed.BankName = "RENAMED"
+		_, err := cached.UpdateSwiftCode(ctx, renamed)
+		require.NoError(t, err)
+
+		code, _ = cached.GetSwiftCodeDetails(ctx, "AAAAPLPW123")
+		assert.Equal(t, "RENAMED", code.BankName)
+		branches, _ = cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+		assert.Equal(t, "RENAMED", branches[0].BankName)
+
+		_, err = cached.DeleteSwiftCode(ctx, "AAAAPLPW123")
+		require.NoError(t, err)
+
+		branches, _ = cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+		assert.Empty(t, branches)
+		codes, _, _ = cached.GetSwiftCodesByCountry(ctx, "PL")
+		assert.Len(t, codes, 1)
+	})
+
+	t.Run("Reads right after a write are not cached", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"))
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+		require.NoError(t, cached.InsertSwiftCodes(ctx, []models.SwiftCode{branch("AAAAPLPW123", "AAAAPLPWXXX", "PL")}))
+
+		for i := 0; i < 2; i++ {
+			code, err := cached.GetSwiftCodeDetails(ctx, "AAAAPLPW123")
+			require.NoError(t, err)
+			assert.NotNil(t, code)
+		}
+		assert.Equal(t, 2, inner.readCount(), "a replica may not have the write yet")
+
+		cached.GetSwiftCodeDetails(ctx, "AAAAPLPWXXX")
+		cached.GetSwiftCodeDetails(ctx, "AAAAPLPWXXX")
+		assert.Equal(t, 3, inner.readCount(), "keys the write did not touch are cached")
+	})
+
+	t.Run("Limited branch queries slice the cached list", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"), branch("AAAAPLPW002", "AAAAPLPWXXX", "PL"), branch("AAAAPLPW001", "AAAAPLPWXXX", "PL"))
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+
+		branches, err := cached.GetBranches(ctx, "AAAAPLPWXXX", repository.BranchQuery{Limit: 1})
+		require.NoError(t, err)
+		assert.Len(t, branches, 1)
+		assert.Equal(t, 1, inner.readCount(), "a partial list is not cached")
+
+		_, err = cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+		require.NoError(t, err)
+		branches, err = cached.GetBranches(ctx, "AAAAPLPWXXX", repository.BranchQuery{Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, branches, 1)
+		assert.Equal(t, "AAAAPLPW001", branches[0].SwiftCode)
+		assert.Equal(t, 2, inner.readCount())
+	})
+
+	t.Run("Pages and counts come from a cached branch list", func(t *testing.T) {
+		b1 := branch("AAAAPLPW001", "AAAAPLPWXXX", "PL")
+		b2 := branch("AAAAPLPW002", "AAAAPLPWXXX", "PL")
+		b2.TownName = "KRAKOW"
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"), b1, b2)
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+
+		_, err := cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+		require.NoError(t, err)
+
+		page, err := cached.GetBranches(ctx, "AAAAPLPWXXX", repository.BranchQuery{Town: "krakow"})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, "AAAAPLPW002", page[0].SwiftCode)
+		count, err := cached.CountBranches(ctx, "AAAAPLPWXXX", repository.BranchQuery{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, 1, inner.readCount())
+	})
+
+	t.Run("Batch lookups only read the misses through", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"), branch("AAAAPLPW001", "AAAAPLPWXXX", "PL"), hq("BBBBDEFFXXX", "DE"))
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+
+		_, err := cached.GetSwiftCodeDetails(ctx, "AAAAPLPWXXX")
+		require.NoError(t, err)
+		codes, err := cached.GetSwiftCodesByCodes(ctx, []string{"BBBBDEFFXXX", "AAAAPLPWXXX", "ZZZZZZZZXXX", "AAAAPLPWXXX"})
+		require.NoError(t, err)
+		require.Len(t, codes, 2)
+		assert.Equal(t, "AAAAPLPWXXX", codes[0].SwiftCode)
+		assert.Equal(t, "BBBBDEFFXXX", codes[1].SwiftCode)
+		assert.Equal(t, 2, inner.readCount())
+
+		codes, err = cached.GetSwiftCodesByCodes(ctx, []string{"BBBBDEFFXXX", "ZZZZZZZZXXX"})
+		require.NoError(t, err)
+		assert.Len(t, codes, 1)
+		assert.Equal(t, 2, inner.readCount(), "found and missing codes are both cached")
+
+		branches, err := cached.GetBranchesByHeadquarters(ctx, []string{"AAAAPLPWXXX", "BBBBDEFFXXX"})
+		require.NoError(t, err)
+		require.Len(t, branches, 1)
+		branches, err = cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+		require.NoError(t, err)
+		assert.Len(t, branches, 1)
+		assert.Equal(t, 3, inner.readCount())
+	})
+
+	t.Run("Joined lookups share the code and branch entries", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"), branch("AAAAPLPW001", "AAAAPLPWXXX", "PL"), hq("BBBBPLPWXXX", "PL"))
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+
+		code, err := cached.GetSwiftCodeWithBranches(ctx, "AAAAPLPWXXX")
+		require.NoError(t, err)
+		require.Len(t, code.Branches, 1)
+		assert.Equal(t, 1, inner.readCount())
+
+		details, err := cached.GetSwiftCodeDetails(ctx, "AAAAPLPWXXX")
+		require.NoError(t, err)
+		assert.Empty(t, details.Branches)
+		branches, err := cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+		require.NoError(t, err)
+		assert.Len(t, branches, 1)
+		code, err = cached.GetSwiftCodeWithBranches(ctx, "AAAAPLPWXXX")
+		require.NoError(t, err)
+		assert.Len(t, code.Branches, 1)
+		missing, err := cached.GetSwiftCodeWithBranches(ctx, "ZZZZZZZZXXX")
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+		assert.Equal(t, 2, inner.readCount())
+
+		headquarters, name, err := cached.GetHeadquartersByCountry(ctx, "PL")
+		require.NoError(t, err)
+		assert.Len(t, headquarters, 2)
+		assert.Equal(t, "COUNTRY PL", name)
+		assert.Equal(t, 3, inner.readCount(), "passes through without a cached country")
+
+		_, _, err = cached.GetSwiftCodesByCountry(ctx, "PL")
+		require.NoError(t, err)
+		_, err = cached.GetBranchesByHeadquarter(ctx, "BBBBPLPWXXX")
+		require.NoError(t, err)
+		reads := inner.readCount()
+		headquarters, _, err = cached.GetHeadquartersByCountry(ctx, "PL")
+		require.NoError(t, err)
+		require.Len(t, headquarters, 2)
+		assert.Len(t, headquarters[0].Branches, 1)
+		assert.Equal(t, reads, inner.readCount(), "composed from cached entries")
+	})
+
+	t.Run("Pinned reads bypass the cache", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"))
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+
+		cached.GetSwiftCodeDetails(ctx, "AAAAPLPWXXX")
+		cached.GetSwiftCodeDetails(repository.PinPrimary(ctx), "AAAAPLPWXXX")
+		assert.Equal(t, 2, inner.readCount())
+	})
+
+	t.Run("Preloaded cache never reads through", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"), branch("AAAAPLPW123", "AAAAPLPWXXX", "PL"), hq("BBBBDEFFXXX", "DE"))
+		cached := repository.NewCachedRepository(inner, 100, 0, nil)
+		require.NoError(t, cached.Preload(ctx))
+		assert.True(t, cached.Complete())
+
+		code, _ := cached.GetSwiftCodeDetails(ctx, "AAAAPLPW123")
+		assert.NotNil(t, code)
+		missing, _ := cached.GetSwiftCodeDetails(ctx, "ZZZZZZZZXXX")
+		assert.Nil(t, missing)
+		branches, _ := cached.GetBranchesByHeadquarter(ctx, "AAAAPLPWXXX")
+		assert.Len(t, branches, 1)
+		codes, name, _ := cached.GetSwiftCodesByCountry(ctx, "DE")
+		assert.Len(t, codes, 1)
+		assert.Equal(t, "COUNTRY DE", name)
+		assert.Equal(t, 0, inner.readCount())
+
+		require.NoError(t, cached.InsertSwiftCodes(ctx, []models.SwiftCode{branch("BBBBDEFF123", "BBBBDEFFXXX", "DE")}))
+		assert.True(t, cached.Complete())
+
+		before := inner.readCount()
+		branches, _ = cached.GetBranchesByHeadquarter(ctx, "BBBBDEFFXXX")
+		assert.Len(t, branches, 1)
+		codes, _, _ = cached.GetSwiftCodesByCountry(ctx, "DE")
+		assert.Len(t, codes, 2)
+		assert.Equal(t, before, inner.readCount())
+	})
+
+	t.Run("Preload refuses a cache that is too small", func(t *testing.T) {
+		inner := newMapRepo(hq("AAAAPLPWXXX", "PL"), hq("BBBBDEFFXXX", "DE"))
+		cached := repository.NewCachedRepository(inner, 2, 0, nil)
+		assert.Error(t, cached.Preload(ctx))
+		assert.False(t, cached.Complete())
+	})
+}
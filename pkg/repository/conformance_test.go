@@ -0,0 +1,528 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+)
+
+// testConformance checks the behaviour every Repository backend must share.
+// newRepo must return an empty repository.
+func testConformance(t *testing.T, newRepo func(t *testing.T) repository.Repository) {
+	ctx := context.Background()
+
+	codesOf := func(codes []models.SwiftCode) []string {
+		out := make([]string, 0, len(codes))
+		for _, c := range codes {
+			out = append(out, c.SwiftCode)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	seed := func(t *testing.T, repo repository.Repository) {
+		t.Helper()
+		pl := hq("CONFPLPWXXX", "PL")
+		pl.CountryName = "POLAND"
+		pl.Address = strPtr("HQ St 1")
+		b1 := branch("CONFPLPW001", "CONFPLPWXXX", "PL")
+		b1.CountryName = "POLAND"
+		b2 := branch("CONFPLPW002", "CONFPLPWXXX", "PL")
+		b2.CountryName = "POLAND"
+		us := hq("CONFUS33XXX", "US")
+		us.CountryName = "UNITED STATES"
+		us.Timezone = "America/New_York"
+		require.NoError(t, repo.InsertSwiftCodes(ctx, []models.SwiftCode{pl, b1, b2, us}))
+	}
+
+	t.Run("Details round trip", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		code, err := repo.GetSwiftCodeDetails(ctx, "CONFPLPWXXX")
+		require.NoError(t, err)
+		require.NotNil(t, code)
+		assert.Equal(t, "BANK", code.BankName)
+		assert.Equal(t, "TOWN", code.TownName)
+		assert.Equal(t, "POLAND", code.CountryName)
+		assert.Equal(t, "Europe/Warsaw", code.Timezone)
+		assert.True(t, code.IsHeadquarter)
+		require.NotNil(t, code.Address)
+		assert.Equal(t, "HQ St 1", *code.Address)
+		assert.Nil(t, code.HeadquarterSWIFTCode)
+
+		branch, err := repo.GetSwiftCodeDetails(ctx, "CONFPLPW001")
+		require.NoError(t, err)
+		require.NotNil(t, branch)
+		assert.Nil(t, branch.Address)
+		require.NotNil(t, branch.HeadquarterSWIFTCode)
+		assert.Equal(t, "CONFPLPWXXX", *branch.HeadquarterSWIFTCode)
+
+		missing, err := repo.GetSwiftCodeDetails(ctx, "MISSINGGXXX")
+		assert.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("Conflicting insert keeps the existing row", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		dup := hq("CONFPLPWXXX", "PL")
+		dup.BankName = "DUPLICATE"
+		require.NoError(t, repo.InsertSwiftCodes(ctx, []models.SwiftCode{dup}))
+
+		code, err := repo.GetSwiftCodeDetails(ctx, "CONFPLPWXXX")
+		require.NoError(t, err)
+		assert.Equal(t, "BANK", code.BankName)
+	})
+
+	t.Run("Branch without headquarter rolls back the batch", func(t *testing.T) {
+		repo := newRepo(t)
+
+		err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
+			hq("ORPHPLPWXXX", "PL"),
+			branch("ORPHPLPW001", "NOSUCHHQXXX", "PL"),
+		})
+		assert.Error(t, err)
+
+		exists, err := repo.SwiftCodeExists(ctx, "ORPHPLPWXXX")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("Values longer than the column are rejected", func(t *testing.T) {
+		repo := newRepo(t)
+		assert.Error(t, repo.InsertSwiftCodes(ctx, []models.SwiftCode{hq("TOOLONGPLPWXXX", "PL")}))
+		assert.Error(t, repo.InsertSwiftCodes(ctx, []models.SwiftCode{hq("LONGPLPWXXX", "POL")}))
+	})
+
+	t.Run("Branches by headquarter", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		branches, err := repo.GetBranchesByHeadquarter(ctx, "CONFPLPWXXX")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CONFPLPW001", "CONFPLPW002"}, codesOf(branches))
+
+		branches, err = repo.GetBranchesByHeadquarter(ctx, "CONFUS33XXX")
+		require.NoError(t, err)
+		assert.Empty(t, branches)
+	})
+
+	t.Run("Limited branches are the first by code", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		branches, err := repo.GetBranches(ctx, "CONFPLPWXXX", repository.BranchQuery{Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, branches, 1)
+		assert.Equal(t, "CONFPLPW001", branches[0].SwiftCode)
+
+		branches, err = repo.GetBranches(ctx, "CONFPLPWXXX", repository.BranchQuery{})
+		require.NoError(t, err)
+		assert.Len(t, branches, 2)
+	})
+
+	t.Run("Branch pages filter by town and count", func(t *testing.T) {
+		repo := newRepo(t)
+		codes := []models.SwiftCode{hq("PAGEPLPWXXX", "PL")}
+		for i, town := range []string{"WARSZAWA", "KRAKOW", "Warszawa", "GDANSK", "WARSZAWA"} {
+			b := branch(fmt.Sprintf("PAGEPLPW%03d", i+1), "PAGEPLPWXXX", "PL")
+			b.TownName = town
+			codes = append(codes, b)
+		}
+		require.NoError(t, repo.InsertSwiftCodes(ctx, codes))
+
+		page, err := repo.GetBranches(ctx, "PAGEPLPWXXX", repository.BranchQuery{Limit: 2, Offset: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PAGEPLPW002", "PAGEPLPW003"}, codesOf(page))
+
+		page, err = repo.GetBranches(ctx, "PAGEPLPWXXX", repository.BranchQuery{Offset: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PAGEPLPW004", "PAGEPLPW005"}, codesOf(page))
+
+		page, err = repo.GetBranches(ctx, "PAGEPLPWXXX", repository.BranchQuery{Town: "warszawa", Limit: 2, Offset: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PAGEPLPW003", "PAGEPLPW005"}, codesOf(page))
+
+		page, err = repo.GetBranches(ctx, "PAGEPLPWXXX", repository.BranchQuery{Offset: 10})
+		require.NoError(t, err)
+		assert.Empty(t, page)
+
+		count, err := repo.CountBranches(ctx, "PAGEPLPWXXX", repository.BranchQuery{Town: "Warszawa", Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count, "limit and offset do not apply to counts")
+		count, err = repo.CountBranches(ctx, "PAGEPLPWXXX", repository.BranchQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+		count, err = repo.CountBranches(ctx, "MISSINGGXXX", repository.BranchQuery{})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Codes by institution span countries", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+		require.NoError(t, repo.InsertSwiftCodes(ctx, []models.SwiftCode{hq("CONFDEFFXXX", "DE"), hq("OTHRDEFFXXX", "DE")}))
+
+		codes, err := repo.GetSwiftCodesByInstitution(ctx, "conf")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CONFDEFFXXX", "CONFPLPW001", "CONFPLPW002", "CONFPLPWXXX", "CONFUS33XXX"}, codesOf(codes))
+
+		codes, err = repo.GetSwiftCodesByInstitution(ctx, "NONE")
+		require.NoError(t, err)
+		assert.Empty(t, codes)
+	})
+
+	t.Run("Institutions are a snapshot of real headquarters", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+		de := hq("CONFDEFFXXX", "DE")
+		de.BankName = "CONF BANK AG"
+		de.CountryName = "GERMANY"
+		placeholder := hq("CONFFRPPXXX", "FR")
+		placeholder.BankName, placeholder.Timezone = "UNKNOWN", "Etc/UTC"
+		require.NoError(t, repo.InsertSwiftCodes(ctx, []models.SwiftCode{de, placeholder}))
+
+		institution, err := repo.GetInstitution(ctx, "CONF")
+		require.NoError(t, err)
+		assert.Nil(t, institution, "not refreshed yet")
+
+		require.NoError(t, repo.RefreshInstitutions(ctx, nil))
+		institution, err = repo.GetInstitution(ctx, "conf")
+		require.NoError(t, err)
+		require.NotNil(t, institution)
+		assert.Equal(t, "CONF", institution.Code)
+		assert.Equal(t, []models.InstitutionHeadquarter{
+			{SwiftCode: "CONFDEFFXXX", BankName: "CONF BANK AG", TownName: "TOWN", CountryISO2: "DE", CountryName: "GERMANY"},
+			{SwiftCode: "CONFPLPWXXX", BankName: "BANK", TownName: "TOWN", CountryISO2: "PL", CountryName: "POLAND", BranchCount: 2},
+			{SwiftCode: "CONFUS33XXX", BankName: "BANK", TownName: "TOWN", CountryISO2: "US", CountryName: "UNITED STATES"},
+		}, institution.Headquarters)
+
+		institution, err = repo.GetInstitution(ctx, "NONE")
+		require.NoError(t, err)
+		assert.Nil(t, institution)
+	})
+
+	t.Run("Refreshing one institution leaves the others", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+		require.NoError(t, repo.RefreshInstitutions(ctx, nil))
+
+		require.NoError(t, repo.InsertSwiftCodes(ctx, []models.SwiftCode{hq("OTHRDEFFXXX", "DE"), branch("CONFPLPW003", "CONFPLPWXXX", "PL")}))
+		require.NoError(t, repo.RefreshInstitutions(ctx, []string{"conf"}))
+
+		institution, err := repo.GetInstitution(ctx, "CONF")
+		require.NoError(t, err)
+		require.NotNil(t, institution)
+		assert.Equal(t, 3, institution.Headquarters[0].BranchCount)
+
+		institution, err = repo.GetInstitution(ctx, "OTHR")
+		require.NoError(t, err)
+		assert.Nil(t, institution)
+
+		deleted, err := repo.DeleteSwiftCode(ctx, "CONFUS33XXX")
+		require.NoError(t, err)
+		require.True(t, deleted)
+		require.NoError(t, repo.RefreshInstitutions(ctx, []string{"CONF", "OTHR"}))
+		institution, err = repo.GetInstitution(ctx, "CONF")
+		require.NoError(t, err)
+		assert.Len(t, institution.Headquarters, 1)
+		institution, err = repo.GetInstitution(ctx, "OTHR")
+		require.NoError(t, err)
+		assert.NotNil(t, institution)
+	})
+
+	t.Run("Institution search", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+		var codes []models.SwiftCode
+		for i, name := range []string{"ALPHA BANK", "BETA 100% BANK", "GAMMA CREDIT", "DELTA BANK"} {
+			c := hq(fmt.Sprintf("SRC%dDEFFXXX", i), "DE")
+			c.BankName = name
+			codes = append(codes, c)
+		}
+		require.NoError(t, repo.InsertSwiftCodes(ctx, codes))
+		require.NoError(t, repo.RefreshInstitutions(ctx, nil))
+
+		search := func(q repository.InstitutionQuery) ([]string, int) {
+			t.Helper()
+			institutions, total, err := repo.SearchInstitutions(ctx, q)
+			require.NoError(t, err)
+			out := []string{}
+			for _, i := range institutions {
+				out = append(out, i.Code)
+			}
+			return out, total
+		}
+
+		got, total := search(repository.InstitutionQuery{})
+		assert.Equal(t, []string{"CONF", "SRC0", "SRC1", "SRC2", "SRC3"}, got)
+		assert.Equal(t, 5, total)
+
+		got, total = search(repository.InstitutionQuery{Text: "bank", Limit: 2, Offset: 1})
+		assert.Equal(t, []string{"SRC0", "SRC1"}, got)
+		assert.Equal(t, 4, total, "CONF, SRC0, SRC1 and SRC3 have BANK in their name")
+
+		got, _ = search(repository.InstitutionQuery{Text: "src"})
+		assert.Equal(t, []string{"SRC0", "SRC1", "SRC2", "SRC3"}, got, "code prefix")
+
+		got, _ = search(repository.InstitutionQuery{Text: "0%"})
+		assert.Equal(t, []string{"SRC1"}, got, "wildcards are literal")
+
+		got, _ = search(repository.InstitutionQuery{Text: "bank", CountryISO2: "us"})
+		assert.Equal(t, []string{"CONF"}, got)
+
+		institutions, _, err := repo.SearchInstitutions(ctx, repository.InstitutionQuery{CountryISO2: "US"})
+		require.NoError(t, err)
+		require.Len(t, institutions, 1)
+		assert.Len(t, institutions[0].Headquarters, 2, "every headquarter of a matching institution")
+
+		got, total = search(repository.InstitutionQuery{Offset: 10})
+		assert.Empty(t, got)
+		assert.Equal(t, 5, total)
+	})
+
+	t.Run("Batch lookups by code and by headquarter", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		codes, err := repo.GetSwiftCodesByCodes(ctx, []string{"CONFUS33XXX", "MISSINGGXXX", "CONFPLPW001", "CONFUS33XXX"})
+		require.NoError(t, err)
+		require.Len(t, codes, 2)
+		assert.Equal(t, "CONFPLPW001", codes[0].SwiftCode)
+		assert.Equal(t, "CONFUS33XXX", codes[1].SwiftCode)
+		assert.Equal(t, "America/New_York", codes[1].Timezone)
+
+		codes, err = repo.GetSwiftCodesByCodes(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, codes)
+
+		branches, err := repo.GetBranchesByHeadquarters(ctx, []string{"CONFPLPWXXX", "CONFUS33XXX", "MISSINGGXXX"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CONFPLPW001", "CONFPLPW002"}, codesOf(branches))
+		assert.Equal(t, "CONFPLPW001", branches[0].SwiftCode, "ordered by code")
+	})
+
+	t.Run("Headquarters with nested branches", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		code, err := repo.GetSwiftCodeWithBranches(ctx, "CONFPLPWXXX")
+		require.NoError(t, err)
+		require.NotNil(t, code)
+		assert.Equal(t, "HQ St 1", *code.Address)
+		assert.Equal(t, []string{"CONFPLPW001", "CONFPLPW002"}, codesOf(code.Branches))
+		assert.Equal(t, "CONFPLPW001", code.Branches[0].SwiftCode, "ordered by code")
+		assert.Equal(t, "CONFPLPWXXX", *code.Branches[0].HeadquarterSWIFTCode)
+		assert.Nil(t, code.Branches[0].Address)
+
+		for _, swiftCode := range []string{"CONFUS33XXX", "CONFPLPW001"} {
+			code, err = repo.GetSwiftCodeWithBranches(ctx, swiftCode)
+			require.NoError(t, err)
+			require.NotNil(t, code)
+			assert.Empty(t, code.Branches, swiftCode)
+		}
+
+		code, err = repo.GetSwiftCodeWithBranches(ctx, "MISSINGGXXX")
+		require.NoError(t, err)
+		assert.Nil(t, code)
+
+		headquarters, name, err := repo.GetHeadquartersByCountry(ctx, "pl")
+		require.NoError(t, err)
+		assert.Equal(t, "POLAND", name)
+		require.Len(t, headquarters, 1)
+		assert.Equal(t, "CONFPLPWXXX", headquarters[0].SwiftCode)
+		assert.Equal(t, []string{"CONFPLPW001", "CONFPLPW002"}, codesOf(headquarters[0].Branches))
+
+		headquarters, name, err = repo.GetHeadquartersByCountry(ctx, "XX")
+		require.NoError(t, err)
+		assert.Empty(t, headquarters)
+		assert.Equal(t, "", name)
+	})
+
+	t.Run("Country lookup is case-insensitive", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		codes, name, err := repo.GetSwiftCodesByCountry(ctx, "pl")
+		require.NoError(t, err)
+		assert.Equal(t, "POLAND", name)
+		assert.Equal(t, []string{"CONFPLPW001", "CONFPLPW002", "CONFPLPWXXX"}, codesOf(codes))
+
+		codes, name, err = repo.GetSwiftCodesByCountry(ctx, "XX")
+		require.NoError(t, err)
+		assert.Empty(t, codes)
+		assert.Equal(t, "", name)
+	})
+
+	t.Run("All codes", func(t *testing.T) {
+		repo := newRepo(t)
+		codes, err := repo.GetAllSwiftCodes(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, codes)
+
+		seed(t, repo)
+		codes, err = repo.GetAllSwiftCodes(ctx)
+		require.NoError(t, err)
+		assert.Len(t, codes, 4)
+	})
+
+	t.Run("Existence checks", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		for _, tc := range []struct {
+			code         string
+			exists, isHQ bool
+		}{
+			{"CONFPLPWXXX", true, true},
+			{"CONFPLPW001", true, false},
+			{"MISSINGGXXX", false, false},
+		} {
+			exists, err := repo.SwiftCodeExists(ctx, tc.code)
+			require.NoError(t, err)
+			assert.Equal(t, tc.exists, exists, tc.code)
+			isHQ, err := repo.HeadquarterExists(ctx, tc.code)
+			require.NoError(t, err)
+			assert.Equal(t, tc.isHQ, isHQ, tc.code)
+		}
+	})
+
+	t.Run("Placeholders", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+		placeholder := models.SwiftCode{
+			SwiftCode: "PLACEPLPXXX", BankName: "UNKNOWN", TownName: "UNKNOWN",
+			CountryISO2: "PL", CountryName: "POLAND", Timezone: "Etc/UTC", IsHeadquarter: true,
+		}
+		require.NoError(t, repo.InsertSwiftCodes(ctx, []models.SwiftCode{placeholder}))
+
+		is, err := repo.IsPlaceholder(ctx, "PLACEPLPXXX")
+		require.NoError(t, err)
+		assert.True(t, is)
+		is, err = repo.IsPlaceholder(ctx, "CONFPLPWXXX")
+		require.NoError(t, err)
+		assert.False(t, is)
+		is, err = repo.IsPlaceholder(ctx, "MISSINGGXXX")
+		require.NoError(t, err)
+		assert.False(t, is)
+
+		promoted := hq("PLACEPLPXXX", "PL")
+		promoted.BankName = "REAL BANK"
+		promoted.Address = strPtr("Real St 2")
+		require.NoError(t, repo.UpdatePlaceholderSwiftCode(ctx, promoted))
+
+		code, err := repo.GetSwiftCodeDetails(ctx, "PLACEPLPXXX")
+		require.NoError(t, err)
+		assert.Equal(t, "REAL BANK", code.BankName)
+		assert.Equal(t, "Europe/Warsaw", code.Timezone)
+		require.NotNil(t, code.Address)
+		assert.Equal(t, "Real St 2", *code.Address)
+
+		again := promoted
+		again.BankName = "OVERWRITTEN"
+		require.NoError(t, repo.UpdatePlaceholderSwiftCode(ctx, again))
+		code, err = repo.GetSwiftCodeDetails(ctx, "PLACEPLPXXX")
+		require.NoError(t, err)
+		assert.Equal(t, "REAL BANK", code.BankName, "only placeholders are updated")
+
+		require.NoError(t, repo.UpdatePlaceholderSwiftCode(ctx, hq("NOTTHEREXXX", "PL")))
+		exists, err := repo.SwiftCodeExists(ctx, "NOTTHEREXXX")
+		require.NoError(t, err)
+		assert.False(t, exists, "updating a missing code does not insert it")
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		changed := hq("CONFPLPWXXX", "PL")
+		changed.BankName = "RENAMED BANK"
+		changed.Address = strPtr("New St 3")
+		updated, err := repo.UpdateSwiftCode(ctx, changed)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		code, err := repo.GetSwiftCodeDetails(ctx, "CONFPLPWXXX")
+		require.NoError(t, err)
+		assert.Equal(t, "RENAMED BANK", code.BankName)
+		require.NotNil(t, code.Address)
+		assert.Equal(t, "New St 3", *code.Address)
+
+		updated, err = repo.UpdateSwiftCode(ctx, hq("NOTTHEREXXX", "PL"))
+		require.NoError(t, err)
+		assert.False(t, updated)
+		exists, err := repo.SwiftCodeExists(ctx, "NOTTHEREXXX")
+		require.NoError(t, err)
+		assert.False(t, exists, "updating a missing code does not insert it")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		_, err := repo.DeleteSwiftCode(ctx, "CONFPLPWXXX")
+		assert.Error(t, err, "headquarter with branches")
+
+		for _, code := range []string{"CONFPLPW001", "CONFPLPW002", "CONFPLPWXXX"} {
+			deleted, err := repo.DeleteSwiftCode(ctx, code)
+			require.NoError(t, err)
+			assert.True(t, deleted, code)
+		}
+
+		deleted, err := repo.DeleteSwiftCode(ctx, "CONFPLPWXXX")
+		require.NoError(t, err)
+		assert.False(t, deleted)
+
+		codes, err := repo.GetAllSwiftCodes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CONFUS33XXX"}, codesOf(codes))
+	})
+
+	t.Run("Returned values are copies", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo)
+
+		code, err := repo.GetSwiftCodeDetails(ctx, "CONFPLPWXXX")
+		require.NoError(t, err)
+		*code.Address = "changed"
+		code.BankName = "changed"
+
+		code, err = repo.GetSwiftCodeDetails(ctx, "CONFPLPWXXX")
+		require.NoError(t, err)
+		assert.Equal(t, "HQ St 1", *code.Address)
+		assert.Equal(t, "BANK", code.BankName)
+	})
+}
+
+func TestMemoryRepository(t *testing.T) {
+	testConformance(t, func(*testing.T) repository.Repository {
+		return repository.NewMemoryRepository()
+	})
+}
+
+func TestPostgresRepository(t *testing.T) {
+	if os.Getenv("DB_URL") == "" {
+		t.Skip("DB_URL is not set")
+	}
+	db, err := sql.Open("postgres", os.Getenv("DB_URL"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	testConformance(t, func(t *testing.T) repository.Repository {
+		_, err := db.Exec("DELETE FROM banks")
+		require.NoError(t, err)
+		_, err = db.Exec("DELETE FROM swift_codes")
+		require.NoError(t, err)
+		return repository.NewRepository(db)
+	})
+}
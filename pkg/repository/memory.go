@@ -0,0 +1,503 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"swift-api/pkg/models"
+	"sync"
+)
+
+var (
+	errMissingHeadquarter = errors.New("headquarter SWIFT code does not exist")
+	errHasBranches        = errors.New("SWIFT code is still referenced by branches")
+)
+
+// MemoryRepo keeps SWIFT codes in a map. It mirrors the constraints of the
+// swift_codes table (column lengths, the headquarter foreign key, conflicts
+// ignored on insert) so it can stand in for PostgreSQL in tests and in
+// deployments without a database. Results are ordered by SWIFT code.
+//
+// Institutions are a snapshot taken by RefreshInstitutions, like the banks
+// table.
+type MemoryRepo struct {
+	mu    sync.RWMutex
+	codes map[string]models.SwiftCode
+	banks map[string]models.Institution
+}
+
+func NewMemoryRepository() Repository {
+	return &MemoryRepo{codes: make(map[string]models.SwiftCode), banks: make(map[string]models.Institution)}
+}
+
+// isPlaceholder matches the headquarter rows inserted for branches whose
+// headquarter is not known yet.
+func isPlaceholder(code *models.SwiftCode) bool {
+	return code != nil && code.BankName == "UNKNOWN" && code.Timezone == "Etc/UTC"
+}
+
+// checkColumns enforces the column types of swift_codes.
+func checkColumns(code models.SwiftCode) error {
+	if len(code.SwiftCode) > 11 {
+		return fmt.Errorf("swift_code %q: value too long for type character varying(11)", code.SwiftCode)
+	}
+	if code.HeadquarterSWIFTCode != nil && len(*code.HeadquarterSWIFTCode) > 11 {
+		return fmt.Errorf("headquarter_swift_code %q: value too long for type character varying(11)", *code.HeadquarterSWIFTCode)
+	}
+	if len(code.CountryISO2) > 2 {
+		return fmt.Errorf("country_iso2 %q: value too long for type character(2)", code.CountryISO2)
+	}
+	return nil
+}
+
+// checkHeadquarter enforces the headquarter foreign key. A row may refer to
+// itself.
+func checkHeadquarter(code models.SwiftCode, exists func(swiftCode string) bool) error {
+	hq := code.HeadquarterSWIFTCode
+	if hq == nil || *hq == code.SwiftCode || exists(*hq) {
+		return nil
+	}
+	return fmt.Errorf("%s: %w: %s", code.SwiftCode, errMissingHeadquarter, *hq)
+}
+
+// filterBranches applies q to branches already ordered by SWIFT code. The
+// result shares no memory with branches.
+func filterBranches(branches []models.SwiftCode, q BranchQuery, paginate bool) []models.SwiftCode {
+	var out []models.SwiftCode
+	for _, b := range branches {
+		if q.Town == "" || strings.EqualFold(b.TownName, q.Town) {
+			out = append(out, b)
+		}
+	}
+	if !paginate {
+		return out
+	}
+	if q.Offset >= len(out) {
+		return nil
+	}
+	out = out[q.Offset:]
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+func cloneCode(code models.SwiftCode) models.SwiftCode {
+	if code.Address != nil {
+		address := *code.Address
+		code.Address = &address
+	}
+	if code.HeadquarterSWIFTCode != nil {
+		hq := *code.HeadquarterSWIFTCode
+		code.HeadquarterSWIFTCode = &hq
+	}
+	code.Branches = nil
+	return code
+}
+
+// collect must be called with mu held.
+func (m *MemoryRepo) collect(match func(models.SwiftCode) bool) []models.SwiftCode {
+	var codes []models.SwiftCode
+	for _, code := range m.codes {
+		if match(code) {
+			codes = append(codes, cloneCode(code))
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].SwiftCode < codes[j].SwiftCode })
+	return codes
+}
+
+// InsertSwiftCodes is all-or-nothing, like the transaction used by Repo: the
+// batch is staged and only applied when every row passes the constraints.
+func (m *MemoryRepo) InsertSwiftCodes(ctx context.Context, swiftCodes []models.SwiftCode) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	markWrite(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	staged := make(map[string]models.SwiftCode, len(swiftCodes))
+	exists := func(swiftCode string) bool {
+		_, inBatch := staged[swiftCode]
+		_, stored := m.codes[swiftCode]
+		return inBatch || stored
+	}
+
+	for _, code := range swiftCodes {
+		if err := checkColumns(code); err != nil {
+			return err
+		}
+		if exists(code.SwiftCode) {
+			continue
+		}
+		if err := checkHeadquarter(code, exists); err != nil {
+			return err
+		}
+		staged[code.SwiftCode] = cloneCode(code)
+	}
+
+	for key, code := range staged {
+		m.codes[key] = code
+	}
+	return nil
+}
+
+func (m *MemoryRepo) GetSwiftCodeDetails(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	code, ok := m.codes[swiftCode]
+	if !ok {
+		return nil, nil
+	}
+	code = cloneCode(code)
+	return &code, nil
+}
+
+func (m *MemoryRepo) GetSwiftCodesByCodes(ctx context.Context, swiftCodes []string) ([]models.SwiftCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := toSet(swiftCodes)
+	return m.collect(func(code models.SwiftCode) bool { return wanted[code.SwiftCode] }), nil
+}
+
+func (m *MemoryRepo) GetBranchesByHeadquarters(ctx context.Context, headquarterSWIFTCodes []string) ([]models.SwiftCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := toSet(headquarterSWIFTCodes)
+	return m.collect(func(code models.SwiftCode) bool {
+		return code.HeadquarterSWIFTCode != nil && wanted[*code.HeadquarterSWIFTCode]
+	}), nil
+}
+
+func (m *MemoryRepo) GetSwiftCodeWithBranches(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	code, err := m.GetSwiftCodeDetails(ctx, swiftCode)
+	if err != nil || code == nil || !code.IsHeadquarter {
+		return code, err
+	}
+	code.Branches, err = m.GetBranchesByHeadquarter(ctx, swiftCode)
+	return code, err
+}
+
+func (m *MemoryRepo) GetBranchesByHeadquarter(ctx context.Context, headquarterSWIFTCode string) ([]models.SwiftCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.collect(func(code models.SwiftCode) bool {
+		return code.HeadquarterSWIFTCode != nil && *code.HeadquarterSWIFTCode == headquarterSWIFTCode
+	}), nil
+}
+
+func (m *MemoryRepo) GetBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) ([]models.SwiftCode, error) {
+	branches, err := m.GetBranchesByHeadquarter(ctx, headquarterSWIFTCode)
+	return filterBranches(branches, q, true), err
+}
+
+func (m *MemoryRepo) CountBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) (int, error) {
+	branches, err := m.GetBranchesByHeadquarter(ctx, headquarterSWIFTCode)
+	return len(filterBranches(branches, q, false)), err
+}
+
+func (m *MemoryRepo) GetSwiftCodesByInstitution(ctx context.Context, institutionCode string) ([]models.SwiftCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prefix := strings.ToUpper(institutionCode)
+	return m.collect(func(code models.SwiftCode) bool {
+		return len(code.SwiftCode) >= 4 && code.SwiftCode[:4] == prefix
+	}), nil
+}
+
+func (m *MemoryRepo) RefreshInstitutions(ctx context.Context, institutionCodes []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	markWrite(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	refresh := func(institutionCode string) bool { return true }
+	if institutionCodes != nil {
+		wanted := make(map[string]bool, len(institutionCodes))
+		for _, code := range institutionCodes {
+			wanted[strings.ToUpper(code)] = true
+		}
+		refresh = func(institutionCode string) bool { return wanted[institutionCode] }
+	}
+
+	for code := range m.banks {
+		if refresh(code) {
+			delete(m.banks, code)
+		}
+	}
+	branches := make(map[string]int)
+	for _, code := range m.codes {
+		if hq := code.HeadquarterSWIFTCode; hq != nil && *hq != code.SwiftCode {
+			branches[*hq]++
+		}
+	}
+	for _, hq := range m.collect(func(code models.SwiftCode) bool {
+		return code.IsHeadquarter && !isPlaceholder(&code) && len(code.SwiftCode) >= 4 && refresh(code.SwiftCode[:4])
+	}) {
+		institution := m.banks[hq.SwiftCode[:4]]
+		institution.Code = hq.SwiftCode[:4]
+		institution.Headquarters = append(institution.Headquarters, models.InstitutionHeadquarter{
+			SwiftCode:   hq.SwiftCode,
+			BankName:    hq.BankName,
+			TownName:    hq.TownName,
+			CountryISO2: hq.CountryISO2,
+			CountryName: hq.CountryName,
+			BranchCount: branches[hq.SwiftCode],
+		})
+		m.banks[institution.Code] = institution
+	}
+	for code, institution := range m.banks {
+		sort.Slice(institution.Headquarters, func(i, j int) bool {
+			a, b := institution.Headquarters[i], institution.Headquarters[j]
+			if a.CountryISO2 != b.CountryISO2 {
+				return a.CountryISO2 < b.CountryISO2
+			}
+			return a.SwiftCode < b.SwiftCode
+		})
+		m.banks[code] = institution
+	}
+	return nil
+}
+
+func cloneInstitution(institution models.Institution) models.Institution {
+	institution.Headquarters = append([]models.InstitutionHeadquarter(nil), institution.Headquarters...)
+	return institution
+}
+
+func (m *MemoryRepo) GetInstitution(ctx context.Context, institutionCode string) (*models.Institution, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	institution, ok := m.banks[strings.ToUpper(institutionCode)]
+	if !ok {
+		return nil, nil
+	}
+	institution = cloneInstitution(institution)
+	return &institution, nil
+}
+
+func (m *MemoryRepo) SearchInstitutions(ctx context.Context, q InstitutionQuery) ([]models.Institution, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []models.Institution
+	for code, institution := range m.banks {
+		if slices.ContainsFunc(institution.Headquarters, func(hq models.InstitutionHeadquarter) bool { return q.matches(code, hq) }) {
+			matched = append(matched, cloneInstitution(institution))
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Code < matched[j].Code })
+
+	total := len(matched)
+	if q.Offset >= total {
+		return nil, total, nil
+	}
+	matched = matched[q.Offset:]
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched, total, nil
+}
+
+func (m *MemoryRepo) GetSwiftCodesByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	iso2 = strings.ToUpper(iso2)
+	codes := m.collect(func(code models.SwiftCode) bool { return code.CountryISO2 == iso2 })
+
+	var countryName string
+	if len(codes) > 0 {
+		countryName = codes[len(codes)-1].CountryName
+	}
+	return codes, countryName, nil
+}
+
+func (m *MemoryRepo) GetHeadquartersByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	iso2 = strings.ToUpper(iso2)
+	headquarters := m.collect(func(code models.SwiftCode) bool { return code.CountryISO2 == iso2 && code.IsHeadquarter })
+	for i := range headquarters {
+		hq := headquarters[i].SwiftCode
+		headquarters[i].Branches = m.collect(func(code models.SwiftCode) bool {
+			return code.HeadquarterSWIFTCode != nil && *code.HeadquarterSWIFTCode == hq
+		})
+	}
+
+	var countryName string
+	if len(headquarters) > 0 {
+		countryName = headquarters[len(headquarters)-1].CountryName
+	}
+	return headquarters, countryName, nil
+}
+
+func (m *MemoryRepo) GetAllSwiftCodes(ctx context.Context) ([]models.SwiftCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.collect(func(models.SwiftCode) bool { return true }), nil
+}
+
+func (m *MemoryRepo) HeadquarterExists(ctx context.Context, swiftCode string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	code, ok := m.codes[swiftCode]
+	return ok && code.IsHeadquarter, nil
+}
+
+func (m *MemoryRepo) SwiftCodeExists(ctx context.Context, swiftCode string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.codes[swiftCode]
+	return ok, nil
+}
+
+func (m *MemoryRepo) IsPlaceholder(ctx context.Context, swiftCode string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	code, ok := m.codes[swiftCode]
+	return ok && isPlaceholder(&code), nil
+}
+
+// UpdatePlaceholderSwiftCode replaces the row only while it is still a
+// placeholder; anything else is left untouched without an error.
+func (m *MemoryRepo) UpdatePlaceholderSwiftCode(ctx context.Context, code models.SwiftCode) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	markWrite(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.codes[code.SwiftCode]
+	if !ok || !isPlaceholder(&current) {
+		return nil
+	}
+	if err := checkColumns(code); err != nil {
+		return err
+	}
+	if err := checkHeadquarter(code, func(swiftCode string) bool {
+		_, ok := m.codes[swiftCode]
+		return ok
+	}); err != nil {
+		return err
+	}
+	m.codes[code.SwiftCode] = cloneCode(code)
+	return nil
+}
+
+// UpdateSwiftCode replaces the row of code.SwiftCode, placeholder or not, and
+// reports false when there is none.
+func (m *MemoryRepo) UpdateSwiftCode(ctx context.Context, code models.SwiftCode) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	markWrite(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.codes[code.SwiftCode]; !ok {
+		return false, nil
+	}
+	if err := checkColumns(code); err != nil {
+		return false, err
+	}
+	if err := checkHeadquarter(code, func(swiftCode string) bool {
+		_, ok := m.codes[swiftCode]
+		return ok
+	}); err != nil {
+		return false, err
+	}
+	m.codes[code.SwiftCode] = cloneCode(code)
+	return true, nil
+}
+
+// DeleteSwiftCode fails while branches still point at swiftCode, as the
+// foreign key does in PostgreSQL.
+func (m *MemoryRepo) DeleteSwiftCode(ctx context.Context, swiftCode string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	markWrite(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.codes[swiftCode]; !ok {
+		return false, nil
+	}
+	for _, code := range m.codes {
+		if code.HeadquarterSWIFTCode != nil && *code.HeadquarterSWIFTCode == swiftCode && code.SwiftCode != swiftCode {
+			return false, fmt.Errorf("%s: %w", swiftCode, errHasBranches)
+		}
+	}
+	delete(m.codes, swiftCode)
+	return true, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// ReplicaSet hands out read replicas round-robin, skipping those whose last
+// health check or query failed. Replicas start healthy; Run keeps the state
+// current.
+type ReplicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+	logger   *slog.Logger
+}
+
+func NewReplicaSet(logger *slog.Logger, dbs ...*sql.DB) *ReplicaSet {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	rs := &ReplicaSet{logger: logger}
+	for i, db := range dbs {
+		r := &replica{name: "replica-" + strconv.Itoa(i+1), db: db}
+		r.healthy.Store(true)
+		rs.replicas = append(rs.replicas, r)
+	}
+	return rs
+}
+
+// pick returns the next healthy replica, or nil when there is none.
+func (rs *ReplicaSet) pick() *replica {
+	if rs == nil || len(rs.replicas) == 0 {
+		return nil
+	}
+	n := uint64(len(rs.replicas))
+	start := rs.next.Add(1) - 1
+	for i := uint64(0); i < n; i++ {
+		r := rs.replicas[(start+i)%n]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+func (rs *ReplicaSet) setHealthy(r *replica, healthy bool, err error) {
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		rs.logger.Info("Read replica is healthy again", "replica", r.name)
+	} else {
+		rs.logger.Warn("Read replica marked unhealthy, reads fall back", "replica", r.name, "error", err)
+	}
+}
+
+// Check pings every replica once and records the result.
+func (rs *ReplicaSet) Check(ctx context.Context, timeout time.Duration) {
+	for _, r := range rs.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		rs.setHealthy(r, err == nil, err)
+	}
+}
+
+// Run checks the replicas every interval until ctx is cancelled.
+func (rs *ReplicaSet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		rs.Check(ctx, interval)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Healthy returns the number of replicas currently serving reads.
+func (rs *ReplicaSet) Healthy() int {
+	n := 0
+	for _, r := range rs.replicas {
+		if r.healthy.Load() {
+			n++
+		}
+	}
+	return n
+}
+
+type pinKey struct{}
+
+type pin struct {
+	primary atomic.Bool
+}
+
+// WithReadYourWrites prepares ctx so that once the repository performs a
+// mutation under it, every later read under it goes to the primary.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(pinKey{}).(*pin); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, pinKey{}, &pin{})
+}
+
+// PinPrimary sends every read under the returned context to the primary.
+func PinPrimary(ctx context.Context) context.Context {
+	p := &pin{}
+	p.primary.Store(true)
+	return context.WithValue(ctx, pinKey{}, p)
+}
+
+func PinnedToPrimary(ctx context.Context) bool {
+	p, ok := ctx.Value(pinKey{}).(*pin)
+	return ok && p.primary.Load()
+}
+
+func markWrite(ctx context.Context) {
+	if p, ok := ctx.Value(pinKey{}).(*pin); ok {
+		p.primary.Store(true)
+	}
+}
@@ -0,0 +1,199 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"swift-api/pkg/repository"
+)
+
+// countingDriver answers every query with a single true value and counts
+// queries per DSN, so tests can see where the repository sent them.
+type countingDriver struct {
+	mu      sync.Mutex
+	queries map[string]int
+	down    map[string]bool
+}
+
+var counting = &countingDriver{queries: map[string]int{}, down: map[string]bool{}}
+
+func init() {
+	sql.Register("counting", counting)
+}
+
+func (d *countingDriver) Open(name string) (driver.Conn, error) {
+	if d.isDown(name) {
+		return nil, errors.New("connection refused")
+	}
+	return &countingConn{d: d, name: name}, nil
+}
+
+func (d *countingDriver) isDown(name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.down[name]
+}
+
+func (d *countingDriver) setDown(name string, down bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.down[name] = down
+}
+
+func (d *countingDriver) count(name string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.queries[name]
+}
+
+func (d *countingDriver) record(name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.down[name] {
+		return errors.New("connection reset by peer")
+	}
+	d.queries[name]++
+	return nil
+}
+
+type countingConn struct {
+	d    *countingDriver
+	name string
+}
+
+func (c *countingConn) Prepare(string) (driver.Stmt, error) { return &countingStmt{c}, nil }
+func (c *countingConn) Close() error                        { return nil }
+func (c *countingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *countingConn) Ping(context.Context) error {
+	if c.d.isDown(c.name) {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+type countingStmt struct {
+	c *countingConn
+}
+
+func (s *countingStmt) Close() error  { return nil }
+func (s *countingStmt) NumInput() int { return -1 }
+
+func (s *countingStmt) Exec([]driver.Value) (driver.Result, error) {
+	if err := s.c.d.record(s.c.name); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *countingStmt) Query([]driver.Value) (driver.Rows, error) {
+	if err := s.c.d.record(s.c.name); err != nil {
+		return nil, err
+	}
+	return &singleRow{}, nil
+}
+
+type singleRow struct {
+	done bool
+}
+
+func (r *singleRow) Columns() []string { return []string{"exists"} }
+func (r *singleRow) Close() error      { return nil }
+
+func (r *singleRow) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = true
+	return nil
+}
+
+func openCounting(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("counting", name)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestReplicaRouting(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	primary := t.Name() + "/primary"
+	replica1 := t.Name() + "/replica-1"
+	replica2 := t.Name() + "/replica-2"
+
+	replicas := repository.NewReplicaSet(logger, openCounting(t, replica1), openCounting(t, replica2))
+	repo := repository.NewRepository(openCounting(t, primary),
+		repository.WithLogger(logger), repository.WithReplicas(replicas))
+	ctx := context.Background()
+
+	read := func(ctx context.Context, n int) {
+		for i := 0; i < n; i++ {
+			exists, err := repo.SwiftCodeExists(ctx, "AAAAPLPWXXX")
+			require.NoError(t, err)
+			require.True(t, exists)
+		}
+	}
+
+	t.Run("Reads are spread round-robin", func(t *testing.T) {
+		read(ctx, 4)
+		assert.Equal(t, 2, counting.count(replica1))
+		assert.Equal(t, 2, counting.count(replica2))
+		assert.Equal(t, 0, counting.count(primary))
+	})
+
+	t.Run("Failed replica falls back to primary", func(t *testing.T) {
+		counting.setDown(replica2, true)
+		read(ctx, 4)
+
+		assert.Equal(t, 1, replicas.Healthy())
+		assert.Equal(t, 1, counting.count(primary), "only the failed read is retried on primary")
+		assert.Equal(t, 5, counting.count(replica1))
+	})
+
+	t.Run("Health check restores replica", func(t *testing.T) {
+		counting.setDown(replica2, false)
+		replicas.Check(ctx, time.Second)
+		assert.Equal(t, 2, replicas.Healthy())
+	})
+
+	t.Run("Pinned context reads from primary", func(t *testing.T) {
+		before := counting.count(primary)
+		read(repository.PinPrimary(ctx), 3)
+		assert.Equal(t, before+3, counting.count(primary))
+	})
+
+	t.Run("Reads after a write go to primary", func(t *testing.T) {
+		rywCtx := repository.WithReadYourWrites(ctx)
+		assert.False(t, repository.PinnedToPrimary(rywCtx))
+
+		_, err := repo.DeleteSwiftCode(rywCtx, "AAAAPLPWXXX")
+		require.NoError(t, err)
+		assert.True(t, repository.PinnedToPrimary(rywCtx))
+
+		before := counting.count(primary)
+		read(rywCtx, 2)
+		assert.Equal(t, before+2, counting.count(primary))
+	})
+
+	t.Run("No healthy replica", func(t *testing.T) {
+		counting.setDown(replica1, true)
+		counting.setDown(replica2, true)
+		replicas.Check(ctx, time.Second)
+		assert.Equal(t, 0, replicas.Healthy())
+
+		before := counting.count(primary)
+		read(ctx, 2)
+		assert.Equal(t, before+2, counting.count(primary))
+	})
+}
@@ -1,36 +1,223 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
 	"strings"
 	"swift-api/pkg/models"
+
+	"github.com/lib/pq"
 )
 
 type Repository interface {
-	InsertSwiftCodes(swiftCodes []models.SwiftCode) error
-	GetSwiftCodeDetails(swiftCode string) (*models.SwiftCode, error)
-	GetBranchesByHeadquarter(headquarterSWIFTCode string) ([]models.SwiftCode, error)
-	GetSwiftCodesByCountry(iso2 string) ([]models.SwiftCode, string, error)
-	HeadquarterExists(swiftCode string) (bool, error)
-	SwiftCodeExists(swiftCode string) (bool, error)
-	IsPlaceholder(swiftCode string) (bool, error)
-	UpdatePlaceholderSwiftCode(code models.SwiftCode) error
-	DeleteSwiftCode(swiftCode string) (bool, error)
+	InsertSwiftCodes(ctx context.Context, swiftCodes []models.SwiftCode) error
+	GetSwiftCodeDetails(ctx context.Context, swiftCode string) (*models.SwiftCode, error)
+	GetSwiftCodesByCodes(ctx context.Context, swiftCodes []string) ([]models.SwiftCode, error)
+	GetSwiftCodeWithBranches(ctx context.Context, swiftCode string) (*models.SwiftCode, error)
+	GetBranchesByHeadquarter(ctx context.Context, headquarterSWIFTCode string) ([]models.SwiftCode, error)
+	GetBranchesByHeadquarters(ctx context.Context, headquarterSWIFTCodes []string) ([]models.SwiftCode, error)
+	GetBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) ([]models.SwiftCode, error)
+	CountBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) (int, error)
+	GetSwiftCodesByInstitution(ctx context.Context, institutionCode string) ([]models.SwiftCode, error)
+	RefreshInstitutions(ctx context.Context, institutionCodes []string) error
+	GetInstitution(ctx context.Context, institutionCode string) (*models.Institution, error)
+	SearchInstitutions(ctx context.Context, q InstitutionQuery) ([]models.Institution, int, error)
+	GetSwiftCodesByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error)
+	GetHeadquartersByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error)
+	GetAllSwiftCodes(ctx context.Context) ([]models.SwiftCode, error)
+	HeadquarterExists(ctx context.Context, swiftCode string) (bool, error)
+	SwiftCodeExists(ctx context.Context, swiftCode string) (bool, error)
+	IsPlaceholder(ctx context.Context, swiftCode string) (bool, error)
+	UpdatePlaceholderSwiftCode(ctx context.Context, code models.SwiftCode) error
+	UpdateSwiftCode(ctx context.Context, code models.SwiftCode) (bool, error)
+	DeleteSwiftCode(ctx context.Context, swiftCode string) (bool, error)
+}
+
+// BranchQuery narrows GetBranches, which returns branches ordered by SWIFT
+// code. Town matches case-insensitively; a zero Limit returns every branch
+// after Offset. CountBranches only applies Town.
+type BranchQuery struct {
+	Town   string
+	Limit  int
+	Offset int
+}
+
+// where renders the conditions of q, numbering placeholders after args.
+func (q BranchQuery) where(headquarterSWIFTCode string) (string, []any) {
+	where := `headquarter_swift_code = $1`
+	args := []any{headquarterSWIFTCode}
+	if q.Town != "" {
+		args = append(args, q.Town)
+		where += fmt.Sprintf(` AND UPPER(town_name) = UPPER($%d)`, len(args))
+	}
+	return where, args
+}
+
+// InstitutionQuery narrows SearchInstitutions, which returns institutions
+// ordered by code along with how many match in total. Text matches the start
+// of an institution code or part of a bank name, ignoring case; CountryISO2
+// keeps institutions with a headquarter in that country. A zero Limit
+// returns every institution after Offset.
+type InstitutionQuery struct {
+	Text        string
+	CountryISO2 string
+	Limit       int
+	Offset      int
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (q InstitutionQuery) where() (string, []any) {
+	where := `TRUE`
+	var args []any
+	if q.Text != "" {
+		text := likeEscaper.Replace(strings.ToUpper(q.Text))
+		args = append(args, text+"%", "%"+text+"%")
+		where += fmt.Sprintf(` AND (institution_code LIKE $%d ESCAPE '\' OR UPPER(bank_name) LIKE $%d ESCAPE '\')`, len(args)-1, len(args))
+	}
+	if q.CountryISO2 != "" {
+		args = append(args, strings.ToUpper(q.CountryISO2))
+		where += fmt.Sprintf(` AND country_iso2 = $%d`, len(args))
+	}
+	return where, args
+}
+
+// matches reports whether hq satisfies the filters of q, for repositories
+// that search in memory.
+func (q InstitutionQuery) matches(institutionCode string, hq models.InstitutionHeadquarter) bool {
+	text := strings.ToUpper(q.Text)
+	if text != "" && !strings.HasPrefix(institutionCode, text) && !strings.Contains(strings.ToUpper(hq.BankName), text) {
+		return false
+	}
+	return q.CountryISO2 == "" || strings.EqualFold(hq.CountryISO2, q.CountryISO2)
+}
+
+// pageLimit renders a LIMIT and OFFSET pair. SQLite only accepts OFFSET after
+// a LIMIT, and the two databases spell "no limit" differently, so an
+// unlimited page is bounded by the largest BIGINT instead.
+func pageLimit(limit, offset int, args []any) (string, []any) {
+	bound := int64(math.MaxInt64)
+	if limit > 0 {
+		bound = int64(limit)
+	}
+	args = append(args, bound, offset)
+	return fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)-1, len(args)), args
 }
 
 type Repo struct {
-	db *sql.DB
+	db       *sql.DB
+	replicas *ReplicaSet
+	logger   *slog.Logger
+}
+
+type Option func(*Repo)
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Repo) {
+		r.logger = logger
+	}
 }
 
-func NewRepository(db *sql.DB) Repository {
-	return &Repo{db: db}
+// WithReplicas routes reads to rs. Writes, and reads under a context pinned
+// to the primary, keep using the primary.
+func WithReplicas(rs *ReplicaSet) Option {
+	return func(r *Repo) {
+		r.replicas = rs
+	}
 }
 
-func (r *Repo) InsertSwiftCodes(swiftCodes []models.SwiftCode) error {
-	tx, err := r.db.Begin()
+func NewRepository(db *sql.DB, opts ...Option) Repository {
+	return newRepo(db, opts)
+}
+
+func newRepo(db *sql.DB, opts []Option) *Repo {
+	r := &Repo{db: db, logger: slog.Default()}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// read runs fn on a healthy replica unless ctx is pinned to the primary. When
+// the replica fails with anything but sql.ErrNoRows it is marked unhealthy
+// and fn runs again on the primary.
+func (r *Repo) read(ctx context.Context, fn func(db *sql.DB) error) error {
+	rep := r.replicas.pick()
+	if rep == nil || PinnedToPrimary(ctx) {
+		return fn(r.db)
+	}
+
+	err := fn(rep.db)
+	if err == nil || errors.Is(err, sql.ErrNoRows) || ctx.Err() != nil {
+		return err
+	}
+	r.replicas.setHealthy(rep, false, err)
+	return fn(r.db)
+}
+
+func scanSwiftCodes(rows *sql.Rows) ([]models.SwiftCode, error) {
+	defer rows.Close()
+
+	var codes []models.SwiftCode
+	for rows.Next() {
+		var c models.SwiftCode
+		err := rows.Scan(&c.SwiftCode, &c.BankName, &c.Address, &c.TownName, &c.CountryISO2, &c.CountryName, &c.Timezone, &c.IsHeadquarter, &c.HeadquarterSWIFTCode)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, c)
+	}
+	return codes, rows.Err()
+}
+
+// scanWithBranches reads the rows of a LEFT JOIN from codes to their
+// branches, ordered by code and then branch, into codes with nested Branches.
+func scanWithBranches(rows *sql.Rows) ([]models.SwiftCode, error) {
+	defer rows.Close()
+
+	var codes []models.SwiftCode
+	for rows.Next() {
+		var c models.SwiftCode
+		var b struct {
+			swiftCode, bankName, address, townName, countryISO2, countryName, timezone, hq *string
+			isHeadquarter                                                                  *bool
+		}
+		err := rows.Scan(&c.SwiftCode, &c.BankName, &c.Address, &c.TownName, &c.CountryISO2, &c.CountryName, &c.Timezone, &c.IsHeadquarter, &c.HeadquarterSWIFTCode,
+			&b.swiftCode, &b.bankName, &b.address, &b.townName, &b.countryISO2, &b.countryName, &b.timezone, &b.isHeadquarter, &b.hq)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(codes); n == 0 || codes[n-1].SwiftCode != c.SwiftCode {
+			codes = append(codes, c)
+		}
+		if b.swiftCode == nil {
+			continue
+		}
+		parent := &codes[len(codes)-1]
+		parent.Branches = append(parent.Branches, models.SwiftCode{
+			SwiftCode:            *b.swiftCode,
+			BankName:             *b.bankName,
+			Address:              b.address,
+			TownName:             *b.townName,
+			CountryISO2:          *b.countryISO2,
+			CountryName:          *b.countryName,
+			Timezone:             *b.timezone,
+			IsHeadquarter:        *b.isHeadquarter,
+			HeadquarterSWIFTCode: b.hq,
+		})
+	}
+	return codes, rows.Err()
+}
+
+func (r *Repo) InsertSwiftCodes(ctx context.Context, swiftCodes []models.SwiftCode) error {
+	markWrite(ctx)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Println("Error starting transaction:", err)
+		r.logger.ErrorContext(ctx, "Error starting transaction", "error", err)
 		return err
 	}
 	defer tx.Rollback()
@@ -51,148 +238,447 @@ func (r *Repo) InsertSwiftCodes(swiftCodes []models.SwiftCode) error {
 			hqCode = *code.HeadquarterSWIFTCode
 		}
 
-		_, err := tx.Exec(`
+		_, err := tx.ExecContext(ctx, `
 			INSERT INTO swift_codes (swift_code, bank_name, address, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code)
 			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 			ON CONFLICT (swift_code) DO NOTHING`,
 			code.SwiftCode, code.BankName, address, code.TownName, code.CountryISO2, code.CountryName, code.Timezone, code.IsHeadquarter, hqCode)
 
 		if err != nil {
-			log.Println("Error inserting data:", err)
+			r.logger.ErrorContext(ctx, "Error inserting data", "swift_code", code.SwiftCode, "error", err)
 			return err
 		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		log.Println("Error committing transaction:", err)
+		r.logger.ErrorContext(ctx, "Error committing transaction", "error", err)
 		return err
 	}
 
-	log.Println("Swift codes inserted successfully")
+	r.logger.DebugContext(ctx, "Swift codes inserted successfully", "count", len(swiftCodes))
 	return nil
 }
 
-func (r *Repo) GetSwiftCodeDetails(swiftCode string) (*models.SwiftCode, error) {
-	row := r.db.QueryRow(`
-		SELECT swift_code, bank_name, address, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code
-		FROM swift_codes WHERE swift_code = $1`, swiftCode)
-
+func (r *Repo) GetSwiftCodeDetails(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
 	var code models.SwiftCode
-	err := row.Scan(&code.SwiftCode, &code.BankName, &code.Address, &code.TownName, &code.CountryISO2, &code.CountryName, &code.Timezone, &code.IsHeadquarter, &code.HeadquarterSWIFTCode)
+	err := r.read(ctx, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, `
+			SELECT swift_code, bank_name, address, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code
+			FROM swift_codes WHERE swift_code = $1`, swiftCode).
+			Scan(&code.SwiftCode, &code.BankName, &code.Address, &code.TownName, &code.CountryISO2, &code.CountryName, &code.Timezone, &code.IsHeadquarter, &code.HeadquarterSWIFTCode)
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		log.Println("Error fetching SWIFT code details:", err)
+		r.logger.ErrorContext(ctx, "Error fetching SWIFT code details", "swift_code", swiftCode, "error", err)
 		return nil, err
 	}
 	return &code, nil
 }
 
-func (r *Repo) GetBranchesByHeadquarter(headquarterSWIFTCode string) ([]models.SwiftCode, error) {
-	rows, err := r.db.Query(`
+// selectCodes returns the rows matching where, a condition on a single
+// parameter, ordered by SWIFT code.
+func (r *Repo) selectCodes(ctx context.Context, where string, arg any) ([]models.SwiftCode, error) {
+	var codes []models.SwiftCode
+	err := r.read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT swift_code, bank_name, address, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code
+			FROM swift_codes WHERE `+where+`
+			ORDER BY swift_code`, arg)
+		if err != nil {
+			return err
+		}
+		codes, err = scanSwiftCodes(rows)
+		return err
+	})
+	return codes, err
+}
+
+// GetSwiftCodesByCodes returns the codes among swiftCodes that exist, ordered
+// by SWIFT code, in a single query.
+func (r *Repo) GetSwiftCodesByCodes(ctx context.Context, swiftCodes []string) ([]models.SwiftCode, error) {
+	codes, err := r.selectCodes(ctx, `swift_code = ANY($1)`, pq.Array(swiftCodes))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error fetching SWIFT codes", "count", len(swiftCodes), "error", err)
+		return nil, err
+	}
+	return codes, nil
+}
+
+// GetBranchesByHeadquarters returns the branches of every headquarter in
+// headquarterSWIFTCodes, ordered by SWIFT code, in a single query.
+func (r *Repo) GetBranchesByHeadquarters(ctx context.Context, headquarterSWIFTCodes []string) ([]models.SwiftCode, error) {
+	branches, err := r.selectCodes(ctx, `headquarter_swift_code = ANY($1)`, pq.Array(headquarterSWIFTCodes))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error fetching branches", "headquarters", len(headquarterSWIFTCodes), "error", err)
+		return nil, err
+	}
+	return branches, nil
+}
+
+// GetSwiftCodeWithBranches returns a code and, for a headquarter, its
+// branches ordered by SWIFT code, in one round trip.
+func (r *Repo) GetSwiftCodeWithBranches(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	var codes []models.SwiftCode
+	err := r.read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT c.swift_code, c.bank_name, c.address, c.town_name, c.country_iso2, c.country_name, c.timezone, c.is_headquarter, c.headquarter_swift_code,
+			       b.swift_code, b.bank_name, b.address, b.town_name, b.country_iso2, b.country_name, b.timezone, b.is_headquarter, b.headquarter_swift_code
+			FROM swift_codes c
+			LEFT JOIN swift_codes b ON b.headquarter_swift_code = c.swift_code AND c.is_headquarter = TRUE
+			WHERE c.swift_code = $1
+			ORDER BY b.swift_code`, swiftCode)
+		if err != nil {
+			return err
+		}
+		codes, err = scanWithBranches(rows)
+		return err
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error fetching SWIFT code with branches", "swift_code", swiftCode, "error", err)
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	return &codes[0], nil
+}
+
+func (r *Repo) GetBranchesByHeadquarter(ctx context.Context, headquarterSWIFTCode string) ([]models.SwiftCode, error) {
+	return r.GetBranches(ctx, headquarterSWIFTCode, BranchQuery{})
+}
+
+func (r *Repo) GetBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) ([]models.SwiftCode, error) {
+	where, args := q.where(headquarterSWIFTCode)
+	query := `
 		SELECT swift_code, bank_name, address, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code
-		FROM swift_codes WHERE headquarter_swift_code = $1`, headquarterSWIFTCode)
+		FROM swift_codes WHERE ` + where + `
+		ORDER BY swift_code`
+	if q.Limit > 0 || q.Offset > 0 {
+		var page string
+		page, args = pageLimit(q.Limit, q.Offset, args)
+		query += page
+	}
+
+	var branches []models.SwiftCode
+	err := r.read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		branches, err = scanSwiftCodes(rows)
+		return err
+	})
 	if err != nil {
-		log.Println("Error fetching branches:", err)
+		r.logger.ErrorContext(ctx, "Error fetching branches", "headquarter", headquarterSWIFTCode, "error", err)
 		return nil, err
 	}
+	return branches, nil
+}
+
+func (r *Repo) CountBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) (int, error) {
+	where, args := q.where(headquarterSWIFTCode)
+	var count int
+	err := r.read(ctx, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, `SELECT COUNT(*) FROM swift_codes WHERE `+where, args...).Scan(&count)
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error counting branches", "headquarter", headquarterSWIFTCode, "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetSwiftCodesByInstitution returns every code whose first four characters
+// are institutionCode, across all countries, ordered by SWIFT code.
+func (r *Repo) GetSwiftCodesByInstitution(ctx context.Context, institutionCode string) ([]models.SwiftCode, error) {
+	var codes []models.SwiftCode
+	err := r.read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT swift_code, bank_name, address, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code
+			FROM swift_codes WHERE SUBSTR(swift_code, 1, 4) = $1
+			ORDER BY swift_code`, strings.ToUpper(institutionCode))
+		if err != nil {
+			return err
+		}
+		codes, err = scanSwiftCodes(rows)
+		return err
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error fetching SWIFT codes by institution", "institution", institutionCode, "error", err)
+		return nil, err
+	}
+	return codes, nil
+}
+
+// inList renders "column IN ($n, ...)" for values, numbering placeholders
+// after args.
+func inList(column string, values []string, args []any) (string, []any) {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		args = append(args, v)
+		placeholders[i] = fmt.Sprintf("$%d", len(args))
+	}
+	return column + ` IN (` + strings.Join(placeholders, ", ") + `)`, args
+}
+
+// RefreshInstitutions rebuilds the banks table from swift_codes for the given
+// institution codes, or for every institution when institutionCodes is nil.
+// Placeholder headquarters are left out.
+func (r *Repo) RefreshInstitutions(ctx context.Context, institutionCodes []string) error {
+	if institutionCodes != nil && len(institutionCodes) == 0 {
+		return nil
+	}
+	markWrite(ctx)
+
+	deleteWhere, insertWhere := `TRUE`, `TRUE`
+	var args []any
+	if institutionCodes != nil {
+		codes := make([]string, len(institutionCodes))
+		for i, code := range institutionCodes {
+			codes[i] = strings.ToUpper(code)
+		}
+		deleteWhere, args = inList(`institution_code`, codes, nil)
+		insertWhere, _ = inList(`SUBSTR(hq.swift_code, 1, 4)`, codes, nil)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error starting transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM banks WHERE `+deleteWhere, args...); err != nil {
+		r.logger.ErrorContext(ctx, "Error clearing institutions", "error", err)
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO banks (headquarter_swift_code, institution_code, bank_name, town_name, country_iso2, country_name, branch_count)
+		SELECT hq.swift_code, SUBSTR(hq.swift_code, 1, 4), hq.bank_name, hq.town_name, hq.country_iso2, hq.country_name,
+		       (SELECT COUNT(*) FROM swift_codes b WHERE b.headquarter_swift_code = hq.swift_code AND b.swift_code <> hq.swift_code)
+		FROM swift_codes hq
+		WHERE hq.is_headquarter = TRUE AND NOT (hq.bank_name = 'UNKNOWN' AND hq.timezone = 'Etc/UTC')
+		AND `+insertWhere, args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error populating institutions", "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "Error committing transaction", "error", err)
+		return err
+	}
+	return nil
+}
+
+// scanInstitutions groups rows ordered by institution code.
+func scanInstitutions(rows *sql.Rows) ([]models.Institution, error) {
 	defer rows.Close()
 
-	var branches []models.SwiftCode
+	var institutions []models.Institution
 	for rows.Next() {
-		var branch models.SwiftCode
-		err := rows.Scan(&branch.SwiftCode, &branch.BankName, &branch.Address, &branch.TownName, &branch.CountryISO2, &branch.CountryName, &branch.Timezone, &branch.IsHeadquarter, &branch.HeadquarterSWIFTCode)
+		var code string
+		var hq models.InstitutionHeadquarter
+		err := rows.Scan(&code, &hq.SwiftCode, &hq.BankName, &hq.TownName, &hq.CountryISO2, &hq.CountryName, &hq.BranchCount)
 		if err != nil {
-			log.Println("Error scanning branch:", err)
 			return nil, err
 		}
-		branches = append(branches, branch)
+		if n := len(institutions); n == 0 || institutions[n-1].Code != code {
+			institutions = append(institutions, models.Institution{Code: code})
+		}
+		last := &institutions[len(institutions)-1]
+		last.Headquarters = append(last.Headquarters, hq)
 	}
+	return institutions, rows.Err()
+}
 
-	if err := rows.Err(); err != nil {
-		log.Println("Error with rows:", err)
+func (r *Repo) GetInstitution(ctx context.Context, institutionCode string) (*models.Institution, error) {
+	var institutions []models.Institution
+	err := r.read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT institution_code, headquarter_swift_code, bank_name, town_name, country_iso2, country_name, branch_count
+			FROM banks WHERE institution_code = $1
+			ORDER BY country_iso2, headquarter_swift_code`, strings.ToUpper(institutionCode))
+		if err != nil {
+			return err
+		}
+		institutions, err = scanInstitutions(rows)
+		return err
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error fetching institution", "institution", institutionCode, "error", err)
 		return nil, err
 	}
+	if len(institutions) == 0 {
+		return nil, nil
+	}
+	return &institutions[0], nil
+}
 
-	return branches, nil
+func (r *Repo) SearchInstitutions(ctx context.Context, q InstitutionQuery) ([]models.Institution, int, error) {
+	where, args := q.where()
+	page, pageArgs := pageLimit(q.Limit, q.Offset, append([]any(nil), args...))
+
+	var institutions []models.Institution
+	var total int
+	err := r.read(ctx, func(db *sql.DB) error {
+		err := db.QueryRowContext(ctx, `SELECT COUNT(DISTINCT institution_code) FROM banks WHERE `+where, args...).Scan(&total)
+		if err != nil {
+			return err
+		}
+		rows, err := db.QueryContext(ctx, `
+			SELECT institution_code, headquarter_swift_code, bank_name, town_name, country_iso2, country_name, branch_count
+			FROM banks WHERE institution_code IN (
+				SELECT DISTINCT institution_code FROM banks WHERE `+where+`
+				ORDER BY institution_code`+page+`
+			)
+			ORDER BY institution_code, country_iso2, headquarter_swift_code`, pageArgs...)
+		if err != nil {
+			return err
+		}
+		institutions, err = scanInstitutions(rows)
+		return err
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error searching institutions", "error", err)
+		return nil, 0, err
+	}
+	return institutions, total, nil
 }
 
-func (r *Repo) GetSwiftCodesByCountry(iso2 string) ([]models.SwiftCode, string, error) {
-	rows, err := r.db.Query(`
-		SELECT swift_code, bank_name, address, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code
-		FROM swift_codes WHERE country_iso2 = $1`, strings.ToUpper(iso2))
+func (r *Repo) GetSwiftCodesByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	var codes []models.SwiftCode
+	err := r.read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT swift_code, bank_name, address, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code
+			FROM swift_codes WHERE country_iso2 = $1`, strings.ToUpper(iso2))
+		if err != nil {
+			return err
+		}
+		codes, err = scanSwiftCodes(rows)
+		return err
+	})
 	if err != nil {
+		r.logger.ErrorContext(ctx, "Error fetching SWIFT codes by country", "country", iso2, "error", err)
 		return nil, "", err
 	}
-	defer rows.Close()
 
-	var codes []models.SwiftCode
 	var countryName string
+	if len(codes) > 0 {
+		countryName = codes[len(codes)-1].CountryName
+	}
+	return codes, countryName, nil
+}
 
-	for rows.Next() {
-		var c models.SwiftCode
-		err = rows.Scan(&c.SwiftCode, &c.BankName, &c.Address, &c.TownName, &c.CountryISO2, &c.CountryName, &c.Timezone, &c.IsHeadquarter, &c.HeadquarterSWIFTCode)
+// GetHeadquartersByCountry returns the headquarters of a country ordered by
+// SWIFT code, each with its branches nested, in one round trip.
+func (r *Repo) GetHeadquartersByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	var headquarters []models.SwiftCode
+	err := r.read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT h.swift_code, h.bank_name, h.address, h.town_name, h.country_iso2, h.country_name, h.timezone, h.is_headquarter, h.headquarter_swift_code,
+			       b.swift_code, b.bank_name, b.address, b.town_name, b.country_iso2, b.country_name, b.timezone, b.is_headquarter, b.headquarter_swift_code
+			FROM swift_codes h
+			LEFT JOIN swift_codes b ON b.headquarter_swift_code = h.swift_code
+			WHERE h.country_iso2 = $1 AND h.is_headquarter = TRUE
+			ORDER BY h.swift_code, b.swift_code`, strings.ToUpper(iso2))
 		if err != nil {
-			return nil, "", err
+			return err
 		}
-		codes = append(codes, c)
-		countryName = c.CountryName
+		headquarters, err = scanWithBranches(rows)
+		return err
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error fetching headquarters by country", "country", iso2, "error", err)
+		return nil, "", err
 	}
 
-	return codes, countryName, nil
+	var countryName string
+	if len(headquarters) > 0 {
+		countryName = headquarters[len(headquarters)-1].CountryName
+	}
+	return headquarters, countryName, nil
+}
+
+func (r *Repo) GetAllSwiftCodes(ctx context.Context) ([]models.SwiftCode, error) {
+	var codes []models.SwiftCode
+	err := r.read(ctx, func(db *sql.DB) error {
+		rows, err := db.QueryContext(ctx, `
+			SELECT swift_code, bank_name, address, town_name, country_iso2, country_name, timezone, is_headquarter, headquarter_swift_code
+			FROM swift_codes`)
+		if err != nil {
+			return err
+		}
+		codes, err = scanSwiftCodes(rows)
+		return err
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error fetching all SWIFT codes", "error", err)
+		return nil, err
+	}
+	return codes, nil
 }
 
-func (r *Repo) HeadquarterExists(swiftCode string) (bool, error) {
+func (r *Repo) HeadquarterExists(ctx context.Context, swiftCode string) (bool, error) {
 	var exists bool
-	err := r.db.QueryRow(`
-		SELECT EXISTS(
-			SELECT 1 FROM swift_codes 
-			WHERE swift_code = $1 AND is_headquarter = TRUE
-		)
-	`, swiftCode).Scan(&exists)
+	err := r.read(ctx, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM swift_codes 
+				WHERE swift_code = $1 AND is_headquarter = TRUE
+			)
+		`, swiftCode).Scan(&exists)
+	})
 
 	if err != nil {
-		log.Println("Error checking headquarter existence:", err)
+		r.logger.ErrorContext(ctx, "Error checking headquarter existence", "swift_code", swiftCode, "error", err)
 		return false, err
 	}
 
 	return exists, nil
 }
 
-func (r *Repo) SwiftCodeExists(swiftCode string) (bool, error) {
+func (r *Repo) SwiftCodeExists(ctx context.Context, swiftCode string) (bool, error) {
 	var exists bool
-	err := r.db.QueryRow(`
-		SELECT EXISTS(SELECT 1 FROM swift_codes WHERE swift_code = $1)
-	`, swiftCode).Scan(&exists)
+	err := r.read(ctx, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, `
+			SELECT EXISTS(SELECT 1 FROM swift_codes WHERE swift_code = $1)
+		`, swiftCode).Scan(&exists)
+	})
 
 	if err != nil {
-		log.Println("Error checking SWIFT code existence:", err)
+		r.logger.ErrorContext(ctx, "Error checking SWIFT code existence", "swift_code", swiftCode, "error", err)
 		return false, err
 	}
 
 	return exists, nil
 }
 
-func (r *Repo) IsPlaceholder(swiftCode string) (bool, error) {
+func (r *Repo) IsPlaceholder(ctx context.Context, swiftCode string) (bool, error) {
 	var bankName, timezone string
-	err := r.db.QueryRow(`
-		SELECT bank_name, timezone FROM swift_codes WHERE swift_code = $1
-	`, swiftCode).Scan(&bankName, &timezone)
+	err := r.read(ctx, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, `
+			SELECT bank_name, timezone FROM swift_codes WHERE swift_code = $1
+		`, swiftCode).Scan(&bankName, &timezone)
+	})
 
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
 	if err != nil {
+		r.logger.ErrorContext(ctx, "Error checking placeholder", "swift_code", swiftCode, "error", err)
 		return false, err
 	}
 
 	return bankName == "UNKNOWN" && timezone == "Etc/UTC", nil
 }
 
-func (r *Repo) UpdatePlaceholderSwiftCode(code models.SwiftCode) error {
-	_, err := r.db.Exec(`
+func (r *Repo) UpdatePlaceholderSwiftCode(ctx context.Context, code models.SwiftCode) error {
+	markWrite(ctx)
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE swift_codes SET
 			bank_name = $1,
 			address = $2,
@@ -207,12 +693,45 @@ func (r *Repo) UpdatePlaceholderSwiftCode(code models.SwiftCode) error {
 	`, code.BankName, code.Address, code.TownName, code.CountryISO2,
 		code.CountryName, code.Timezone, code.IsHeadquarter, code.HeadquarterSWIFTCode, code.SwiftCode)
 
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error updating placeholder", "swift_code", code.SwiftCode, "error", err)
+	}
 	return err
 }
 
-func (r *Repo) DeleteSwiftCode(swiftCode string) (bool, error) {
-	result, err := r.db.Exec(`DELETE FROM swift_codes WHERE swift_code = $1`, swiftCode)
+// UpdateSwiftCode replaces the row of code.SwiftCode, placeholder or not. It
+// reports false when there is no such row.
+func (r *Repo) UpdateSwiftCode(ctx context.Context, code models.SwiftCode) (bool, error) {
+	markWrite(ctx)
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE swift_codes SET
+			bank_name = $1,
+			address = $2,
+			town_name = $3,
+			country_iso2 = $4,
+			country_name = $5,
+			timezone = $6,
+			is_headquarter = $7,
+			headquarter_swift_code = $8
+		WHERE swift_code = $9
+	`, code.BankName, code.Address, code.TownName, code.CountryISO2,
+		code.CountryName, code.Timezone, code.IsHeadquarter, code.HeadquarterSWIFTCode, code.SwiftCode)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Error updating SWIFT code", "swift_code", code.SwiftCode, "error", err)
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *Repo) DeleteSwiftCode(ctx context.Context, swiftCode string) (bool, error) {
+	markWrite(ctx)
+	result, err := r.db.ExecContext(ctx, `DELETE FROM swift_codes WHERE swift_code = $1`, swiftCode)
 	if err != nil {
+		r.logger.ErrorContext(ctx, "Error deleting SWIFT code", "swift_code", swiftCode, "error", err)
 		return false, err
 	}
 	rowsAffected, err := result.RowsAffected()
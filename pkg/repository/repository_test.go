@@ -1,6 +1,7 @@
 package repository_test
 
 import (
+	"context"
 	"database/sql"
 	"os"
 	"testing"
@@ -11,7 +12,12 @@ import (
 	"swift-api/pkg/repository"
 )
 
+// setupTestDB is for tests that inspect the table directly; behaviour shared
+// with the other backends is covered by testConformance.
 func setupTestDB(t *testing.T) *sql.DB {
+	if os.Getenv("DB_URL") == "" {
+		t.Skip("DB_URL is not set")
+	}
 	db, err := sql.Open("postgres", os.Getenv("DB_URL"))
 	if err != nil {
 		t.Fatalf("Failed to connect to test DB: %v", err)
@@ -25,6 +31,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 func TestInsertSwiftCodes(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
 	swiftCode := models.SwiftCode{
 		SwiftCode:     "TESTINSERT1",
@@ -37,7 +44,7 @@ func TestInsertSwiftCodes(t *testing.T) {
 		Address:       nil,
 	}
 
-	err := repo.InsertSwiftCodes([]models.SwiftCode{swiftCode})
+	err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{swiftCode})
 	assert.NoError(t, err)
 
 	var count int
@@ -49,9 +56,10 @@ func TestInsertSwiftCodes(t *testing.T) {
 func TestInsertSwiftCodes_AdvancedCases(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
 	t.Run("Insert new HQ", func(t *testing.T) {
-		err := repo.InsertSwiftCodes([]models.SwiftCode{
+		err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 			{
 				SwiftCode:     "NEWPLLHQXXX",
 				BankName:      "New HQ Bank",
@@ -67,7 +75,7 @@ func TestInsertSwiftCodes_AdvancedCases(t *testing.T) {
 	})
 
 	t.Run("Insert branch with existing HQ", func(t *testing.T) {
-		err := repo.InsertSwiftCodes([]models.SwiftCode{
+		err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 			{
 				SwiftCode:            "NEWPLLHQ001",
 				BankName:             "Branch Bank",
@@ -84,7 +92,7 @@ func TestInsertSwiftCodes_AdvancedCases(t *testing.T) {
 	})
 
 	t.Run("Duplicate HQ", func(t *testing.T) {
-		err := repo.InsertSwiftCodes([]models.SwiftCode{
+		err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 			{
 				SwiftCode:     "NEWPLLHQXXX",
 				BankName:      "Duplicate HQ",
@@ -100,7 +108,7 @@ func TestInsertSwiftCodes_AdvancedCases(t *testing.T) {
 	})
 
 	t.Run("Insert branch with missing HQ (should fail on FK)", func(t *testing.T) {
-		err := repo.InsertSwiftCodes([]models.SwiftCode{
+		err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 			{
 				SwiftCode:            "MISSINGHQ001",
 				BankName:             "Orphan Branch",
@@ -117,7 +125,7 @@ func TestInsertSwiftCodes_AdvancedCases(t *testing.T) {
 	})
 
 	t.Run("Insert HQ with nil address", func(t *testing.T) {
-		err := repo.InsertSwiftCodes([]models.SwiftCode{
+		err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 			{
 				SwiftCode:     "NULLADDRXXX",
 				BankName:      "No Address HQ",
@@ -140,6 +148,7 @@ func strPtr(s string) *string {
 func TestGetSwiftCodeDetails(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
 	hq := models.SwiftCode{
 		SwiftCode:     "DETATESTXXX",
@@ -151,11 +160,11 @@ func TestGetSwiftCodeDetails(t *testing.T) {
 		Address:       strPtr("Detail St 1"),
 		Timezone:      "Europe/Warsaw",
 	}
-	err := repo.InsertSwiftCodes([]models.SwiftCode{hq})
+	err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{hq})
 	assert.NoError(t, err)
 
 	t.Run("Get existing SWIFT code", func(t *testing.T) {
-		result, err := repo.GetSwiftCodeDetails("DETATESTXXX")
+		result, err := repo.GetSwiftCodeDetails(ctx, "DETATESTXXX")
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Equal(t, "DETATESTXXX", result.SwiftCode)
@@ -166,7 +175,7 @@ func TestGetSwiftCodeDetails(t *testing.T) {
 	})
 
 	t.Run("Get non-existent SWIFT code", func(t *testing.T) {
-		result, err := repo.GetSwiftCodeDetails("DOESNOTEXIS")
+		result, err := repo.GetSwiftCodeDetails(ctx, "DOESNOTEXIS")
 		assert.NoError(t, err)
 		assert.Nil(t, result)
 	})
@@ -175,10 +184,11 @@ func TestGetSwiftCodeDetails(t *testing.T) {
 func TestGetBranchesByHeadquarter(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
 	hqCode := "BRANCHTEXXX"
 
-	err := repo.InsertSwiftCodes([]models.SwiftCode{
+	err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 		{
 			SwiftCode:     hqCode,
 			BankName:      "Test HQ",
@@ -221,7 +231,7 @@ func TestGetBranchesByHeadquarter(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Run("Get branches for existing HQ", func(t *testing.T) {
-		branches, err := repo.GetBranchesByHeadquarter(hqCode)
+		branches, err := repo.GetBranchesByHeadquarter(ctx, hqCode)
 		assert.NoError(t, err)
 		assert.Len(t, branches, 2)
 
@@ -234,13 +244,13 @@ func TestGetBranchesByHeadquarter(t *testing.T) {
 	})
 
 	t.Run("Get branches for HQ with no branches", func(t *testing.T) {
-		branches, err := repo.GetBranchesByHeadquarter("NOCHILDSXXX")
+		branches, err := repo.GetBranchesByHeadquarter(ctx, "NOCHILDSXXX")
 		assert.NoError(t, err)
 		assert.Len(t, branches, 0)
 	})
 
 	t.Run("Get branches for non-existent HQ", func(t *testing.T) {
-		branches, err := repo.GetBranchesByHeadquarter("UNKNOWNNXXX")
+		branches, err := repo.GetBranchesByHeadquarter(ctx, "UNKNOWNNXXX")
 		assert.NoError(t, err)
 		assert.Len(t, branches, 0)
 	})
@@ -249,8 +259,9 @@ func TestGetBranchesByHeadquarter(t *testing.T) {
 func TestGetSwiftCodesByCountry(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
-	err := repo.InsertSwiftCodes([]models.SwiftCode{
+	err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 		{
 			SwiftCode:     "PLCOUNTRXXX",
 			BankName:      "Polish HQ",
@@ -283,7 +294,7 @@ func TestGetSwiftCodesByCountry(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Run("Get codes for PL", func(t *testing.T) {
-		codes, countryName, err := repo.GetSwiftCodesByCountry("pl")
+		codes, countryName, err := repo.GetSwiftCodesByCountry(ctx, "pl")
 		assert.NoError(t, err)
 		assert.Len(t, codes, 2)
 		assert.Equal(t, "POLAND", countryName)
@@ -297,7 +308,7 @@ func TestGetSwiftCodesByCountry(t *testing.T) {
 	})
 
 	t.Run("Get codes for US", func(t *testing.T) {
-		codes, countryName, err := repo.GetSwiftCodesByCountry("us")
+		codes, countryName, err := repo.GetSwiftCodesByCountry(ctx, "us")
 		assert.NoError(t, err)
 		assert.Len(t, codes, 1)
 		assert.Equal(t, "UNITED STATES", countryName)
@@ -305,22 +316,71 @@ func TestGetSwiftCodesByCountry(t *testing.T) {
 	})
 
 	t.Run("No results for XX", func(t *testing.T) {
-		codes, countryName, err := repo.GetSwiftCodesByCountry("xx")
+		codes, countryName, err := repo.GetSwiftCodesByCountry(ctx, "xx")
 		assert.NoError(t, err)
 		assert.Empty(t, codes)
 		assert.Equal(t, "", countryName)
 	})
 }
 
+func TestGetAllSwiftCodes(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewRepository(db)
+	ctx := context.Background()
+
+	t.Run("Empty table", func(t *testing.T) {
+		codes, err := repo.GetAllSwiftCodes(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, codes)
+	})
+
+	err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
+		{
+			SwiftCode:     "ALLCODESXXX",
+			BankName:      "All HQ",
+			CountryISO2:   "PL",
+			CountryName:   "POLAND",
+			TownName:      "WARSAW",
+			IsHeadquarter: true,
+			Timezone:      "Europe/Warsaw",
+		},
+		{
+			SwiftCode:            "ALLCODES001",
+			BankName:             "All Branch",
+			CountryISO2:          "PL",
+			CountryName:          "POLAND",
+			TownName:             "KRAKOW",
+			IsHeadquarter:        false,
+			Timezone:             "Europe/Warsaw",
+			HeadquarterSWIFTCode: strPtr("ALLCODESXXX"),
+		},
+	})
+	assert.NoError(t, err)
+
+	t.Run("Returns every code", func(t *testing.T) {
+		codes, err := repo.GetAllSwiftCodes(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, codes, 2)
+
+		var swiftCodes []string
+		for _, c := range codes {
+			swiftCodes = append(swiftCodes, c.SwiftCode)
+		}
+		assert.Contains(t, swiftCodes, "ALLCODESXXX")
+		assert.Contains(t, swiftCodes, "ALLCODES001")
+	})
+}
+
 func TestHeadquarterExists(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
 	hqCode := "EXISTHQQXXX"
 	nonHqCode := "NOTAHQQQ001"
 	missingCode := "DOESNOTEXIS"
 
-	err := repo.InsertSwiftCodes([]models.SwiftCode{
+	err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 		{
 			SwiftCode:     hqCode,
 			BankName:      "Test HQ",
@@ -343,19 +403,19 @@ func TestHeadquarterExists(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Run("Existing HQ returns true", func(t *testing.T) {
-		exists, err := repo.HeadquarterExists(hqCode)
+		exists, err := repo.HeadquarterExists(ctx, hqCode)
 		assert.NoError(t, err)
 		assert.True(t, exists)
 	})
 
 	t.Run("Existing non-HQ returns false", func(t *testing.T) {
-		exists, err := repo.HeadquarterExists(nonHqCode)
+		exists, err := repo.HeadquarterExists(ctx, nonHqCode)
 		assert.NoError(t, err)
 		assert.False(t, exists)
 	})
 
 	t.Run("Non-existent code returns false", func(t *testing.T) {
-		exists, err := repo.HeadquarterExists(missingCode)
+		exists, err := repo.HeadquarterExists(ctx, missingCode)
 		assert.NoError(t, err)
 		assert.False(t, exists)
 	})
@@ -364,11 +424,12 @@ func TestHeadquarterExists(t *testing.T) {
 func TestSwiftCodeExists(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
 	existingCode := "EXISTSCC001"
 	nonExistingCode := "NOSUCHCODEE"
 
-	err := repo.InsertSwiftCodes([]models.SwiftCode{
+	err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 		{
 			SwiftCode:     existingCode,
 			BankName:      "Some Bank",
@@ -382,13 +443,13 @@ func TestSwiftCodeExists(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Run("Existing SWIFT code returns true", func(t *testing.T) {
-		exists, err := repo.SwiftCodeExists(existingCode)
+		exists, err := repo.SwiftCodeExists(ctx, existingCode)
 		assert.NoError(t, err)
 		assert.True(t, exists)
 	})
 
 	t.Run("Non-existing SWIFT code returns false", func(t *testing.T) {
-		exists, err := repo.SwiftCodeExists(nonExistingCode)
+		exists, err := repo.SwiftCodeExists(ctx, nonExistingCode)
 		assert.NoError(t, err)
 		assert.False(t, exists)
 	})
@@ -397,11 +458,12 @@ func TestSwiftCodeExists(t *testing.T) {
 func TestIsPlaceholder(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
 	placeholderCode := "PLACEHOLXXX"
 	normalCode := "NORMALHQXXX"
 
-	err := repo.InsertSwiftCodes([]models.SwiftCode{
+	err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 		{
 			SwiftCode:     placeholderCode,
 			BankName:      "UNKNOWN",
@@ -424,19 +486,19 @@ func TestIsPlaceholder(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Run("Recognize placeholder HQ", func(t *testing.T) {
-		isPlaceholder, err := repo.IsPlaceholder(placeholderCode)
+		isPlaceholder, err := repo.IsPlaceholder(ctx, placeholderCode)
 		assert.NoError(t, err)
 		assert.True(t, isPlaceholder)
 	})
 
 	t.Run("Recognize non-placeholder HQ", func(t *testing.T) {
-		isPlaceholder, err := repo.IsPlaceholder(normalCode)
+		isPlaceholder, err := repo.IsPlaceholder(ctx, normalCode)
 		assert.NoError(t, err)
 		assert.False(t, isPlaceholder)
 	})
 
 	t.Run("Non-existent code returns false", func(t *testing.T) {
-		isPlaceholder, err := repo.IsPlaceholder("NOPEEEEEXXX")
+		isPlaceholder, err := repo.IsPlaceholder(ctx, "NOPEEEEEXXX")
 		assert.NoError(t, err)
 		assert.False(t, isPlaceholder)
 	})
@@ -445,11 +507,12 @@ func TestIsPlaceholder(t *testing.T) {
 func TestUpdatePlaceholderSwiftCode(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
 	t.Run("Update existing placeholder with real data", func(t *testing.T) {
 		placeholderCode := "PLACEHOLXXX"
 
-		err := repo.InsertSwiftCodes([]models.SwiftCode{
+		err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{
 			{
 				SwiftCode:     placeholderCode,
 				BankName:      "UNKNOWN",
@@ -474,10 +537,10 @@ func TestUpdatePlaceholderSwiftCode(t *testing.T) {
 			Address:       strPtr("HQ Updated Address"),
 		}
 
-		err = repo.UpdatePlaceholderSwiftCode(fullCode)
+		err = repo.UpdatePlaceholderSwiftCode(ctx, fullCode)
 		assert.NoError(t, err)
 
-		updated, err := repo.GetSwiftCodeDetails(placeholderCode)
+		updated, err := repo.GetSwiftCodeDetails(ctx, placeholderCode)
 		assert.NoError(t, err)
 		assert.NotNil(t, updated)
 		assert.Equal(t, "Updated Bank", updated.BankName)
@@ -500,7 +563,7 @@ func TestUpdatePlaceholderSwiftCode(t *testing.T) {
 			Timezone:      "Europe/Warsaw",
 		}
 
-		err := repo.UpdatePlaceholderSwiftCode(code)
+		err := repo.UpdatePlaceholderSwiftCode(ctx, code)
 		assert.NoError(t, err)
 
 		var count int
@@ -513,6 +576,7 @@ func TestUpdatePlaceholderSwiftCode(t *testing.T) {
 func TestDeleteSwiftCode(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewRepository(db)
+	ctx := context.Background()
 
 	t.Run("Delete existing SWIFT code", func(t *testing.T) {
 		code := models.SwiftCode{
@@ -525,20 +589,20 @@ func TestDeleteSwiftCode(t *testing.T) {
 			Timezone:      "Europe/Warsaw",
 		}
 
-		err := repo.InsertSwiftCodes([]models.SwiftCode{code})
+		err := repo.InsertSwiftCodes(ctx, []models.SwiftCode{code})
 		assert.NoError(t, err)
 
-		deleted, err := repo.DeleteSwiftCode("DELTESTTXXX")
+		deleted, err := repo.DeleteSwiftCode(ctx, "DELTESTTXXX")
 		assert.NoError(t, err)
 		assert.True(t, deleted, "expected to successfully delete the SWIFT code")
 
-		exists, err := repo.SwiftCodeExists("DELTESTTXXX")
+		exists, err := repo.SwiftCodeExists(ctx, "DELTESTTXXX")
 		assert.NoError(t, err)
 		assert.False(t, exists, "expected the SWIFT code to no longer exist")
 	})
 
 	t.Run("Delete non-existent SWIFT code", func(t *testing.T) {
-		deleted, err := repo.DeleteSwiftCode("DOESNOTEXIS")
+		deleted, err := repo.DeleteSwiftCode(ctx, "DOESNOTEXIS")
 		assert.NoError(t, err)
 		assert.False(t, deleted, "expected deletion to return false for non-existent SWIFT code")
 	})
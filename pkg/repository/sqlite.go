@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"swift-api/pkg/models"
+)
+
+// SQLiteRepo stores SWIFT codes in SQLite, using the schema from the sqlite
+// migrations. Repo's statements are portable between the two databases, so
+// SQLiteRepo only overrides methods whose SQL has to differ.
+type SQLiteRepo struct {
+	*Repo
+}
+
+// NewSQLiteRepository expects db to enforce foreign keys, as pools opened by
+// database.ConnectDB do. WithReplicas is not supported.
+func NewSQLiteRepository(db *sql.DB, opts ...Option) Repository {
+	return &SQLiteRepo{Repo: newRepo(db, opts)}
+}
+
+// jsonArray binds values as a single JSON parameter, which json_each
+// expands, since SQLite has no array type for = ANY($1).
+func jsonArray(values []string) (string, error) {
+	if values == nil {
+		values = []string{}
+	}
+	b, err := json.Marshal(values)
+	return string(b), err
+}
+
+func (s *SQLiteRepo) GetSwiftCodesByCodes(ctx context.Context, swiftCodes []string) ([]models.SwiftCode, error) {
+	arg, err := jsonArray(swiftCodes)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.selectCodes(ctx, `swift_code IN (SELECT value FROM json_each($1))`, arg)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error fetching SWIFT codes", "count", len(swiftCodes), "error", err)
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *SQLiteRepo) GetBranchesByHeadquarters(ctx context.Context, headquarterSWIFTCodes []string) ([]models.SwiftCode, error) {
+	arg, err := jsonArray(headquarterSWIFTCodes)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := s.selectCodes(ctx, `headquarter_swift_code IN (SELECT value FROM json_each($1))`, arg)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error fetching branches", "headquarters", len(headquarterSWIFTCodes), "error", err)
+		return nil, err
+	}
+	return branches, nil
+}
@@ -0,0 +1,27 @@
+package repository_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"swift-api/internal/database"
+	"swift-api/pkg/repository"
+)
+
+func TestSQLiteRepository(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	testConformance(t, func(t *testing.T) repository.Repository {
+		cfg := database.DefaultConfig()
+		cfg.URL = "sqlite:" + filepath.Join(t.TempDir(), "swift.db")
+		db, err := database.ConnectDB(context.Background(), cfg, logger)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		require.NoError(t, database.Migrate(context.Background(), db, database.SQLite, logger))
+		return repository.NewSQLiteRepository(db, repository.WithLogger(logger))
+	})
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"swift-api/pkg/models"
+	"swift-api/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracedRepository struct {
+	inner    Repository
+	dbSystem string
+}
+
+// NewTracedRepository wraps inner so that every call produces a client span
+// named after the method, carrying the SQL statement name and row count.
+func NewTracedRepository(inner Repository, dbSystem string) Repository {
+	return &tracedRepository{inner: inner, dbSystem: dbSystem}
+}
+
+func (t *tracedRepository) start(ctx context.Context, method, statement string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "repository."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", t.dbSystem),
+			attribute.String("db.statement.name", statement),
+		),
+	)
+}
+
+// finish ends span, recording rows unless it is negative (unknown).
+func finish(span trace.Span, rows int, err error) {
+	if rows >= 0 {
+		span.SetAttributes(tracing.RowsAttribute(rows))
+	}
+	tracing.RecordError(span, err)
+	span.End()
+}
+
+func boolRows(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (t *tracedRepository) InsertSwiftCodes(ctx context.Context, swiftCodes []models.SwiftCode) error {
+	ctx, span := t.start(ctx, "InsertSwiftCodes", "insert_swift_codes")
+	err := t.inner.InsertSwiftCodes(ctx, swiftCodes)
+	finish(span, len(swiftCodes), err)
+	return err
+}
+
+func (t *tracedRepository) GetSwiftCodeDetails(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	ctx, span := t.start(ctx, "GetSwiftCodeDetails", "select_swift_code")
+	code, err := t.inner.GetSwiftCodeDetails(ctx, swiftCode)
+	finish(span, boolRows(code != nil), err)
+	return code, err
+}
+
+func (t *tracedRepository) GetSwiftCodesByCodes(ctx context.Context, swiftCodes []string) ([]models.SwiftCode, error) {
+	ctx, span := t.start(ctx, "GetSwiftCodesByCodes", "select_swift_codes_by_codes")
+	codes, err := t.inner.GetSwiftCodesByCodes(ctx, swiftCodes)
+	finish(span, len(codes), err)
+	return codes, err
+}
+
+func (t *tracedRepository) GetBranchesByHeadquarters(ctx context.Context, headquarterSWIFTCodes []string) ([]models.SwiftCode, error) {
+	ctx, span := t.start(ctx, "GetBranchesByHeadquarters", "select_branches_by_headquarters")
+	branches, err := t.inner.GetBranchesByHeadquarters(ctx, headquarterSWIFTCodes)
+	finish(span, len(branches), err)
+	return branches, err
+}
+
+// withBranchesRows counts the codes and their nested branches.
+func withBranchesRows(codes []models.SwiftCode) int {
+	rows := len(codes)
+	for _, c := range codes {
+		rows += len(c.Branches)
+	}
+	return rows
+}
+
+func (t *tracedRepository) GetSwiftCodeWithBranches(ctx context.Context, swiftCode string) (*models.SwiftCode, error) {
+	ctx, span := t.start(ctx, "GetSwiftCodeWithBranches", "select_swift_code_with_branches")
+	code, err := t.inner.GetSwiftCodeWithBranches(ctx, swiftCode)
+	rows := 0
+	if code != nil {
+		rows = withBranchesRows([]models.SwiftCode{*code})
+	}
+	finish(span, rows, err)
+	return code, err
+}
+
+func (t *tracedRepository) GetBranchesByHeadquarter(ctx context.Context, headquarterSWIFTCode string) ([]models.SwiftCode, error) {
+	ctx, span := t.start(ctx, "GetBranchesByHeadquarter", "select_branches_by_headquarter")
+	branches, err := t.inner.GetBranchesByHeadquarter(ctx, headquarterSWIFTCode)
+	finish(span, len(branches), err)
+	return branches, err
+}
+
+func (t *tracedRepository) GetBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) ([]models.SwiftCode, error) {
+	ctx, span := t.start(ctx, "GetBranches", "select_branches_page")
+	branches, err := t.inner.GetBranches(ctx, headquarterSWIFTCode, q)
+	finish(span, len(branches), err)
+	return branches, err
+}
+
+func (t *tracedRepository) CountBranches(ctx context.Context, headquarterSWIFTCode string, q BranchQuery) (int, error) {
+	ctx, span := t.start(ctx, "CountBranches", "count_branches")
+	count, err := t.inner.CountBranches(ctx, headquarterSWIFTCode, q)
+	finish(span, 1, err)
+	return count, err
+}
+
+func (t *tracedRepository) GetSwiftCodesByInstitution(ctx context.Context, institutionCode string) ([]models.SwiftCode, error) {
+	ctx, span := t.start(ctx, "GetSwiftCodesByInstitution", "select_swift_codes_by_institution")
+	codes, err := t.inner.GetSwiftCodesByInstitution(ctx, institutionCode)
+	finish(span, len(codes), err)
+	return codes, err
+}
+
+func (t *tracedRepository) RefreshInstitutions(ctx context.Context, institutionCodes []string) error {
+	ctx, span := t.start(ctx, "RefreshInstitutions", "refresh_banks")
+	err := t.inner.RefreshInstitutions(ctx, institutionCodes)
+	finish(span, -1, err)
+	return err
+}
+
+func (t *tracedRepository) GetInstitution(ctx context.Context, institutionCode string) (*models.Institution, error) {
+	ctx, span := t.start(ctx, "GetInstitution", "select_bank")
+	institution, err := t.inner.GetInstitution(ctx, institutionCode)
+	rows := 0
+	if institution != nil {
+		rows = len(institution.Headquarters)
+	}
+	finish(span, rows, err)
+	return institution, err
+}
+
+func (t *tracedRepository) SearchInstitutions(ctx context.Context, q InstitutionQuery) ([]models.Institution, int, error) {
+	ctx, span := t.start(ctx, "SearchInstitutions", "search_banks")
+	institutions, total, err := t.inner.SearchInstitutions(ctx, q)
+	finish(span, len(institutions), err)
+	return institutions, total, err
+}
+
+func (t *tracedRepository) GetSwiftCodesByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	ctx, span := t.start(ctx, "GetSwiftCodesByCountry", "select_swift_codes_by_country")
+	codes, countryName, err := t.inner.GetSwiftCodesByCountry(ctx, iso2)
+	finish(span, len(codes), err)
+	return codes, countryName, err
+}
+
+func (t *tracedRepository) GetHeadquartersByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	ctx, span := t.start(ctx, "GetHeadquartersByCountry", "select_headquarters_by_country")
+	headquarters, countryName, err := t.inner.GetHeadquartersByCountry(ctx, iso2)
+	finish(span, withBranchesRows(headquarters), err)
+	return headquarters, countryName, err
+}
+
+func (t *tracedRepository) GetAllSwiftCodes(ctx context.Context) ([]models.SwiftCode, error) {
+	ctx, span := t.start(ctx, "GetAllSwiftCodes", "select_all_swift_codes")
+	codes, err := t.inner.GetAllSwiftCodes(ctx)
+	finish(span, len(codes), err)
+	return codes, err
+}
+
+func (t *tracedRepository) HeadquarterExists(ctx context.Context, swiftCode string) (bool, error) {
+	ctx, span := t.start(ctx, "HeadquarterExists", "exists_headquarter")
+	exists, err := t.inner.HeadquarterExists(ctx, swiftCode)
+	finish(span, boolRows(exists), err)
+	return exists, err
+}
+
+func (t *tracedRepository) SwiftCodeExists(ctx context.Context, swiftCode string) (bool, error) {
+	ctx, span := t.start(ctx, "SwiftCodeExists", "exists_swift_code")
+	exists, err := t.inner.SwiftCodeExists(ctx, swiftCode)
+	finish(span, boolRows(exists), err)
+	return exists, err
+}
+
+func (t *tracedRepository) IsPlaceholder(ctx context.Context, swiftCode string) (bool, error) {
+	ctx, span := t.start(ctx, "IsPlaceholder", "select_placeholder")
+	placeholder, err := t.inner.IsPlaceholder(ctx, swiftCode)
+	finish(span, boolRows(placeholder), err)
+	return placeholder, err
+}
+
+func (t *tracedRepository) UpdatePlaceholderSwiftCode(ctx context.Context, code models.SwiftCode) error {
+	ctx, span := t.start(ctx, "UpdatePlaceholderSwiftCode", "update_placeholder")
+	err := t.inner.UpdatePlaceholderSwiftCode(ctx, code)
+	finish(span, -1, err)
+	return err
+}
+
+func (t *tracedRepository) UpdateSwiftCode(ctx context.Context, code models.SwiftCode) (bool, error) {
+	ctx, span := t.start(ctx, "UpdateSwiftCode", "update_swift_code")
+	updated, err := t.inner.UpdateSwiftCode(ctx, code)
+	finish(span, boolRows(updated), err)
+	return updated, err
+}
+
+func (t *tracedRepository) DeleteSwiftCode(ctx context.Context, swiftCode string) (bool, error) {
+	ctx, span := t.start(ctx, "DeleteSwiftCode", "delete_swift_code")
+	deleted, err := t.inner.DeleteSwiftCode(ctx, swiftCode)
+	finish(span, boolRows(deleted), err)
+	return deleted, err
+}
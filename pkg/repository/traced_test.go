@@ -0,0 +1,52 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+)
+
+type branchesRepo struct {
+	repository.Repository
+	err error
+}
+
+func (b branchesRepo) GetBranchesByHeadquarter(context.Context, string) ([]models.SwiftCode, error) {
+	return []models.SwiftCode{{SwiftCode: "AAAAPLPW001"}, {SwiftCode: "AAAAPLPW002"}}, b.err
+}
+
+func TestTracedRepository(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	t.Run("Span carries statement and rows", func(t *testing.T) {
+		repo := repository.NewTracedRepository(branchesRepo{}, "postgresql")
+		_, err := repo.GetBranchesByHeadquarter(context.Background(), "AAAAPLPWXXX")
+		assert.NoError(t, err)
+
+		spans := recorder.Ended()
+		span := spans[len(spans)-1]
+		assert.Equal(t, "repository.GetBranchesByHeadquarter", span.Name())
+		assert.Contains(t, span.Attributes(), attribute.String("db.statement.name", "select_branches_by_headquarter"))
+		assert.Contains(t, span.Attributes(), attribute.String("db.system", "postgresql"))
+		assert.Contains(t, span.Attributes(), attribute.Int("db.response.returned_rows", 2))
+	})
+
+	t.Run("Errors mark the span", func(t *testing.T) {
+		repo := repository.NewTracedRepository(branchesRepo{err: errors.New("boom")}, "postgresql")
+		_, err := repo.GetBranchesByHeadquarter(context.Background(), "AAAAPLPWXXX")
+		assert.Error(t, err)
+
+		spans := recorder.Ended()
+		assert.Equal(t, codes.Error, spans[len(spans)-1].Status().Code)
+	})
+}
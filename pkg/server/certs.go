@@ -0,0 +1,110 @@
+package server
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader serves a certificate loaded from disk and reloads it when the
+// certificate or key file changes, so rotated certificates are picked up
+// without a restart. Files are checked at most once per CheckInterval.
+type CertReloader struct {
+	CheckInterval time.Duration
+
+	certFile string
+	keyFile  string
+	logger   *slog.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func NewCertReloader(certFile, keyFile string, logger *slog.Logger) (*CertReloader, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	c := &CertReloader{
+		CheckInterval: 10 * time.Second,
+		certFile:      certFile,
+		keyFile:       keyFile,
+		logger:        logger,
+	}
+
+	modTime, err := c.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.load(modTime); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.maybeReload()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// maybeReload keeps serving the current certificate when the new files
+// cannot be read or parsed, e.g. while they are only half written.
+func (c *CertReloader) maybeReload() {
+	now := time.Now()
+
+	c.mu.Lock()
+	if now.Sub(c.checked) < c.CheckInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.checked = now
+	current := c.modTime
+	c.mu.Unlock()
+
+	modTime, err := c.latestModTime()
+	if err != nil {
+		c.logger.Warn("Could not stat TLS certificate", "error", err)
+		return
+	}
+	if !modTime.After(current) {
+		return
+	}
+	if err := c.load(modTime); err != nil {
+		c.logger.Warn("Could not reload TLS certificate, keeping the current one", "error", err)
+		return
+	}
+	c.logger.Info("Reloaded TLS certificate", "cert_file", c.certFile)
+}
+
+func (c *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = &cert
+	c.modTime = modTime
+	return nil
+}
+
+func (c *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
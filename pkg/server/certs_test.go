@@ -0,0 +1,88 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCert(t *testing.T, dir string, serial int64, modTime time.Time) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	return certFile, keyFile
+}
+
+func servedSerial(t *testing.T, c *CertReloader) int64 {
+	t.Helper()
+	cert, err := c.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.SerialNumber.Int64()
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	certFile, keyFile := writeCert(t, dir, 1, base)
+
+	c, err := NewCertReloader(certFile, keyFile, quietLogger())
+	require.NoError(t, err)
+	c.CheckInterval = 0
+	assert.Equal(t, int64(1), servedSerial(t, c))
+
+	t.Run("Reloads changed files", func(t *testing.T) {
+		writeCert(t, dir, 2, base.Add(time.Minute))
+		assert.Equal(t, int64(2), servedSerial(t, c))
+	})
+
+	t.Run("Keeps current certificate when new files are invalid", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+		modTime := base.Add(2 * time.Minute)
+		require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+		assert.Equal(t, int64(2), servedSerial(t, c))
+	})
+
+	t.Run("Respects check interval", func(t *testing.T) {
+		c.CheckInterval = time.Hour
+		c.checked = time.Now()
+		writeCert(t, dir, 3, base.Add(3*time.Minute))
+		assert.Equal(t, int64(2), servedSerial(t, c))
+	})
+
+	t.Run("Missing files", func(t *testing.T) {
+		_, err := NewCertReloader(filepath.Join(dir, "missing.crt"), keyFile, quietLogger())
+		assert.Error(t, err)
+	})
+}
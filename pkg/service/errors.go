@@ -0,0 +1,44 @@
+package service
+
+import "errors"
+
+// Kinds of failure a client can act on. Every API maps them to its own
+// status codes; anything else is an internal error.
+var (
+	ErrInvalid  = errors.New("invalid argument")
+	ErrNotFound = errors.New("not found")
+	ErrExists   = errors.New("already exists")
+	ErrConflict = errors.New("conflict with the current state")
+)
+
+// Error carries a message that is safe to return to clients. Kind is one of
+// the errors above, or nil for an internal failure whose cause is Err.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	var errs []error
+	for _, err := range []error{e.Kind, e.Err} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func invalid(message string) error  { return &Error{Kind: ErrInvalid, Message: message} }
+func notFound(message string) error { return &Error{Kind: ErrNotFound, Message: message} }
+
+func internal(message string, err error) error {
+	return &Error{Message: message, Err: err}
+}
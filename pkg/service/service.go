@@ -0,0 +1,547 @@
+// Package service holds the SWIFT code operations the REST, gRPC and GraphQL
+// APIs share, so all of them validate the same way and fail with the same
+// errors.
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"swift-api/pkg/events"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+)
+
+const (
+	MaxBatchLookup          = 1000
+	DefaultInstitutionLimit = 20
+	MaxInstitutionLimit     = 100
+	DefaultBranchLimit      = 50
+	MaxBranchLimit          = 500
+)
+
+// Events, when set, is told of every code created, updated, promoted or
+// deleted.
+type Service struct {
+	Repo   repository.Repository
+	Logger *slog.Logger
+	Events events.Publisher
+}
+
+func New(repo repository.Repository, logger *slog.Logger) *Service {
+	return &Service{Repo: repo, Logger: logger}
+}
+
+func (s *Service) logger() *slog.Logger {
+	if s.Logger == nil {
+		return slog.Default()
+	}
+	return s.Logger
+}
+
+func (s *Service) publish(ctx context.Context, t events.Type, code *models.SwiftCode, swiftCode string) {
+	if s.Events == nil {
+		return
+	}
+	s.Events.Publish(ctx, events.Event{Type: t, Source: events.SourceAPI, SwiftCode: swiftCode, Data: code})
+}
+
+// LookupOptions say which branches a headquarter lookup embeds. BranchLimit
+// caps them at the first N by code; 0 embeds every branch.
+type LookupOptions struct {
+	Branches    bool
+	BranchLimit int
+}
+
+func validSwiftCode(swiftCode string) error {
+	if swiftCode == "" {
+		return invalid("SWIFT code is required")
+	}
+	if len(swiftCode) != 11 {
+		return invalid("SWIFT code must be exactly 11 characters")
+	}
+	return nil
+}
+
+// GetSwiftCode returns a code with the branches opts ask for. Embedding
+// every branch takes one joined query instead of two.
+func (s *Service) GetSwiftCode(ctx context.Context, swiftCode string, opts LookupOptions) (*models.SwiftCode, error) {
+	if err := validSwiftCode(swiftCode); err != nil {
+		return nil, err
+	}
+
+	var code *models.SwiftCode
+	var err error
+	if opts.Branches && opts.BranchLimit == 0 {
+		code, err = s.Repo.GetSwiftCodeWithBranches(ctx, swiftCode)
+	} else {
+		code, err = s.Repo.GetSwiftCodeDetails(ctx, swiftCode)
+		if err == nil && code != nil && code.IsHeadquarter && opts.Branches {
+			code.Branches, err = s.Repo.GetBranches(ctx, swiftCode, repository.BranchQuery{Limit: opts.BranchLimit})
+		}
+	}
+	if err != nil {
+		return nil, internal("Error retrieving SWIFT code", err)
+	}
+	if code == nil {
+		return nil, notFound("SWIFT code not found")
+	}
+	return code, nil
+}
+
+// ListBranches pages through the branches of a headquarter and counts every
+// branch matching q.Town. A zero limit means DefaultBranchLimit.
+func (s *Service) ListBranches(ctx context.Context, swiftCode string, q repository.BranchQuery) ([]models.SwiftCode, int, error) {
+	if err := validSwiftCode(swiftCode); err != nil {
+		return nil, 0, err
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultBranchLimit
+	}
+	if q.Limit < 1 || q.Limit > MaxBranchLimit {
+		return nil, 0, invalid(fmt.Sprintf("limit must be an integer between 1 and %d", MaxBranchLimit))
+	}
+	if q.Offset < 0 {
+		return nil, 0, invalid("offset must not be negative")
+	}
+	q.Town = strings.TrimSpace(q.Town)
+
+	code, err := s.Repo.GetSwiftCodeDetails(ctx, swiftCode)
+	if err != nil {
+		return nil, 0, internal("Error retrieving SWIFT code", err)
+	}
+	if code == nil {
+		return nil, 0, notFound("SWIFT code not found")
+	}
+	if !code.IsHeadquarter {
+		return nil, 0, invalid("SWIFT code is not a headquarter")
+	}
+
+	total, err := s.Repo.CountBranches(ctx, swiftCode, q)
+	if err != nil {
+		return nil, 0, internal("Error counting branches", err)
+	}
+	if q.Offset >= total {
+		return nil, total, nil
+	}
+	branches, err := s.Repo.GetBranches(ctx, swiftCode, q)
+	if err != nil {
+		return nil, 0, internal("Error retrieving branches", err)
+	}
+	return branches, total, nil
+}
+
+// ListByCountry returns every code of a country and the country's name.
+func (s *Service) ListByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	if iso2 == "" {
+		return nil, "", invalid("Country ISO2 code is required")
+	}
+	if len(iso2) != 2 {
+		return nil, "", invalid("Country ISO2 code must be exactly 2 characters")
+	}
+
+	codes, countryName, err := s.Repo.GetSwiftCodesByCountry(ctx, iso2)
+	if err != nil {
+		return nil, "", internal("Error retrieving SWIFT codes", err)
+	}
+	if len(codes) == 0 {
+		return nil, "", notFound("No SWIFT codes found for this country")
+	}
+	return codes, countryName, nil
+}
+
+// ListHeadquartersByCountry returns the headquarters of a country, each with
+// its branches, and the country's name.
+func (s *Service) ListHeadquartersByCountry(ctx context.Context, iso2 string) ([]models.SwiftCode, string, error) {
+	iso2 = strings.ToUpper(iso2)
+	if len(iso2) != 2 {
+		return nil, "", invalid("Country ISO2 code must be exactly 2 characters")
+	}
+
+	headquarters, countryName, err := s.Repo.GetHeadquartersByCountry(ctx, iso2)
+	if err != nil {
+		return nil, "", internal("Error retrieving headquarters", err)
+	}
+	if len(headquarters) == 0 {
+		return nil, "", notFound("No headquarters found for this country")
+	}
+	return headquarters, countryName, nil
+}
+
+// NewSwiftCode is a code to create. Country fields are upper-cased before
+// validation.
+type NewSwiftCode struct {
+	Address       string
+	BankName      string
+	CountryISO2   string
+	CountryName   string
+	IsHeadquarter bool
+	SwiftCode     string
+}
+
+func (n NewSwiftCode) Validate() error {
+	if n.BankName == "" {
+		return invalid("bankName is required")
+	}
+	if n.CountryISO2 == "" || len(n.CountryISO2) != 2 {
+		return invalid("countryISO2 must be 2-letter code")
+	}
+	if n.CountryName == "" {
+		return invalid("countryName is required")
+	}
+	if n.Address == "" {
+		return invalid("address is required")
+	}
+	if n.SwiftCode == "" {
+		return invalid("swiftCode is required")
+	}
+	if len(n.SwiftCode) != 11 {
+		return invalid("swiftCode must be exactly 11 characters")
+	}
+	if n.IsHeadquarter && !strings.HasSuffix(n.SwiftCode, "XXX") {
+		return invalid("headquarter swiftCode must end with 'XXX'")
+	}
+	if !n.IsHeadquarter && strings.HasSuffix(n.SwiftCode, "XXX") {
+		return invalid("branch swiftCode cannot end with 'XXX'")
+	}
+	return nil
+}
+
+// CreateSwiftCode adds a code. A branch whose headquarter is unknown gets a
+// placeholder headquarter, and creating a placeholder's code replaces it.
+func (s *Service) CreateSwiftCode(ctx context.Context, n NewSwiftCode) error {
+	n.CountryISO2 = strings.ToUpper(n.CountryISO2)
+	n.CountryName = strings.ToUpper(n.CountryName)
+	if err := n.Validate(); err != nil {
+		return err
+	}
+
+	newCode := models.SwiftCode{
+		Address:       &n.Address,
+		BankName:      n.BankName,
+		CountryISO2:   n.CountryISO2,
+		CountryName:   n.CountryName,
+		IsHeadquarter: n.IsHeadquarter,
+		SwiftCode:     n.SwiftCode,
+	}
+
+	if !n.IsHeadquarter {
+		hqCode := n.SwiftCode[:8] + "XXX"
+		exists, err := s.Repo.HeadquarterExists(ctx, hqCode)
+		if err != nil {
+			return internal("DB error", err)
+		}
+		if !exists {
+			placeholder := models.SwiftCode{
+				SwiftCode:     hqCode,
+				BankName:      "UNKNOWN",
+				TownName:      "UNKNOWN",
+				CountryISO2:   n.CountryISO2,
+				CountryName:   n.CountryName,
+				Timezone:      "Etc/UTC",
+				IsHeadquarter: true,
+			}
+			if err := s.Repo.InsertSwiftCodes(ctx, []models.SwiftCode{placeholder}); err != nil {
+				return internal("Failed to insert placeholder HQ", err)
+			}
+			s.logger().InfoContext(ctx, "Inserted placeholder headquarter", "swift_code", hqCode)
+			s.publish(ctx, events.SwiftCodeCreated, &placeholder, hqCode)
+		}
+		newCode.HeadquarterSWIFTCode = &hqCode
+	}
+
+	exists, err := s.Repo.SwiftCodeExists(ctx, newCode.SwiftCode)
+	if err != nil {
+		return internal("DB error", err)
+	}
+
+	if exists {
+		isPlaceholder, err := s.Repo.IsPlaceholder(ctx, newCode.SwiftCode)
+		if err != nil {
+			return internal("DB error", err)
+		}
+		if !isPlaceholder {
+			return &Error{Kind: ErrExists, Message: "SWIFT code already exists"}
+		}
+		if err := s.Repo.UpdatePlaceholderSwiftCode(ctx, newCode); err != nil {
+			return internal("Failed to update placeholder SWIFT code", err)
+		}
+		s.logger().InfoContext(ctx, "Replaced placeholder with real data", "swift_code", newCode.SwiftCode)
+		s.publish(ctx, events.SwiftCodePlaceholderPromoted, &newCode, newCode.SwiftCode)
+	} else {
+		if err := s.Repo.InsertSwiftCodes(ctx, []models.SwiftCode{newCode}); err != nil {
+			return internal("Failed to insert SWIFT code", err)
+		}
+		s.publish(ctx, events.SwiftCodeCreated, &newCode, newCode.SwiftCode)
+	}
+
+	s.refreshInstitution(ctx, newCode.SwiftCode)
+	return nil
+}
+
+// UpdateSwiftCode replaces the bank name, address and country of the code
+// swiftCode, keeping its town and timezone. n.SwiftCode may be left empty;
+// otherwise it must be swiftCode. Updating a placeholder promotes it, and an
+// update that changes nothing is not announced.
+func (s *Service) UpdateSwiftCode(ctx context.Context, swiftCode string, n NewSwiftCode) error {
+	if err := validSwiftCode(swiftCode); err != nil {
+		return err
+	}
+	if n.SwiftCode == "" {
+		n.SwiftCode = swiftCode
+	}
+	if n.SwiftCode != swiftCode {
+		return invalid("swiftCode must match the SWIFT code being updated")
+	}
+	n.CountryISO2 = strings.ToUpper(n.CountryISO2)
+	n.CountryName = strings.ToUpper(n.CountryName)
+	if err := n.Validate(); err != nil {
+		return err
+	}
+
+	current, err := s.Repo.GetSwiftCodeDetails(ctx, swiftCode)
+	if err != nil {
+		return internal("Error retrieving SWIFT code", err)
+	}
+	if current == nil {
+		return notFound("SWIFT code not found")
+	}
+	isPlaceholder, err := s.Repo.IsPlaceholder(ctx, swiftCode)
+	if err != nil {
+		return internal("DB error", err)
+	}
+
+	code := *current
+	code.Branches = nil
+	code.BankName = n.BankName
+	code.Address = &n.Address
+	code.CountryISO2 = n.CountryISO2
+	code.CountryName = n.CountryName
+
+	if isPlaceholder {
+		if err := s.Repo.UpdatePlaceholderSwiftCode(ctx, code); err != nil {
+			return internal("Failed to update placeholder SWIFT code", err)
+		}
+		s.logger().InfoContext(ctx, "Replaced placeholder with real data", "swift_code", swiftCode)
+		s.publish(ctx, events.SwiftCodePlaceholderPromoted, &code, swiftCode)
+	} else {
+		if code.BankName == current.BankName && current.Address != nil && *current.Address == n.Address &&
+			code.CountryISO2 == current.CountryISO2 && code.CountryName == current.CountryName {
+			return nil
+		}
+		updated, err := s.Repo.UpdateSwiftCode(ctx, code)
+		if err != nil {
+			return internal("Failed to update SWIFT code", err)
+		}
+		if !updated {
+			return notFound("SWIFT code not found")
+		}
+		s.publish(ctx, events.SwiftCodeUpdated, &code, swiftCode)
+	}
+
+	s.refreshInstitution(ctx, swiftCode)
+	return nil
+}
+
+// DeleteSwiftCode removes a branch, or a headquarter without branches.
+func (s *Service) DeleteSwiftCode(ctx context.Context, swiftCode string) error {
+	if err := validSwiftCode(swiftCode); err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(swiftCode, "XXX") {
+		branches, err := s.Repo.GetBranchesByHeadquarter(ctx, swiftCode)
+		if err != nil {
+			return internal("DB error", err)
+		}
+		if len(branches) > 0 {
+			return &Error{Kind: ErrConflict, Message: "Cannot delete headquarter with existing branches"}
+		}
+	}
+
+	deleted, err := s.Repo.DeleteSwiftCode(ctx, swiftCode)
+	if err != nil {
+		return internal("Error deleting SWIFT code", err)
+	}
+	if !deleted {
+		return notFound("SWIFT code not found")
+	}
+	s.publish(ctx, events.SwiftCodeDeleted, nil, swiftCode)
+
+	s.refreshInstitution(ctx, swiftCode)
+	return nil
+}
+
+// refreshInstitution rebuilds the institution view after a write to
+// swiftCode. The write has already succeeded, so a failure is only logged.
+func (s *Service) refreshInstitution(ctx context.Context, swiftCode string) {
+	if err := s.Repo.RefreshInstitutions(ctx, []string{swiftCode[:4]}); err != nil {
+		s.logger().WarnContext(ctx, "Error refreshing institution", "swift_code", swiftCode, "error", err)
+	}
+}
+
+// NormalizeBIC upper-cases a BIC8 or BIC11 and expands a BIC8 to its
+// headquarter's BIC11.
+func NormalizeBIC(query string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(query))
+	if len(code) != 8 && len(code) != 11 {
+		return "", fmt.Errorf("SWIFT code must be 8 or 11 characters")
+	}
+	for _, c := range code {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return "", fmt.Errorf("SWIFT code must only contain letters and digits")
+		}
+	}
+	if len(code) == 8 {
+		code += "XXX"
+	}
+	return code, nil
+}
+
+// LookupResult answers one query of a batch lookup. SwiftCode is the
+// normalized query, empty when Error says why the query is invalid. Code,
+// with its branches embedded, is nil when nothing was found.
+type LookupResult struct {
+	Query     string
+	SwiftCode string
+	Code      *models.SwiftCode
+	Error     string
+}
+
+// LookupSwiftCodes resolves many codes with one query for the codes and one
+// for the branches of the headquarters among them. Results follow queries.
+func (s *Service) LookupSwiftCodes(ctx context.Context, queries []string) ([]LookupResult, error) {
+	if len(queries) == 0 {
+		return nil, invalid("swiftCodes must not be empty")
+	}
+	if len(queries) > MaxBatchLookup {
+		return nil, invalid(fmt.Sprintf("swiftCodes must not have more than %d entries", MaxBatchLookup))
+	}
+
+	results := make([]LookupResult, len(queries))
+	var wanted []string
+	for i, query := range queries {
+		results[i].Query = query
+		code, err := NormalizeBIC(query)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].SwiftCode = code
+		wanted = append(wanted, code)
+	}
+
+	var codes []models.SwiftCode
+	if len(wanted) > 0 {
+		var err error
+		codes, err = s.Repo.GetSwiftCodesByCodes(ctx, wanted)
+		if err != nil {
+			return nil, internal("Error retrieving SWIFT codes", err)
+		}
+	}
+
+	found := make(map[string]models.SwiftCode, len(codes))
+	var headquarters []string
+	for _, code := range codes {
+		found[code.SwiftCode] = code
+		if code.IsHeadquarter {
+			headquarters = append(headquarters, code.SwiftCode)
+		}
+	}
+
+	branches := make(map[string][]models.SwiftCode)
+	if len(headquarters) > 0 {
+		all, err := s.Repo.GetBranchesByHeadquarters(ctx, headquarters)
+		if err != nil {
+			return nil, internal("Error retrieving branches", err)
+		}
+		for _, b := range all {
+			branches[*b.HeadquarterSWIFTCode] = append(branches[*b.HeadquarterSWIFTCode], b)
+		}
+	}
+
+	for i := range results {
+		code, ok := found[results[i].SwiftCode]
+		if !ok {
+			continue
+		}
+		code.Branches = branches[code.SwiftCode]
+		results[i].Code = &code
+	}
+	return results, nil
+}
+
+// SearchInstitutions pages through the institutions matching q. A zero
+// limit means DefaultInstitutionLimit.
+func (s *Service) SearchInstitutions(ctx context.Context, q repository.InstitutionQuery) ([]models.Institution, int, error) {
+	if q.Limit == 0 {
+		q.Limit = DefaultInstitutionLimit
+	}
+	if q.Limit < 1 || q.Limit > MaxInstitutionLimit {
+		return nil, 0, invalid(fmt.Sprintf("limit must be an integer between 1 and %d", MaxInstitutionLimit))
+	}
+	if q.Offset < 0 {
+		return nil, 0, invalid("offset must not be negative")
+	}
+	if q.CountryISO2 != "" && len(q.CountryISO2) != 2 {
+		return nil, 0, invalid("Country ISO2 code must be exactly 2 characters")
+	}
+	q.Text = strings.TrimSpace(q.Text)
+
+	institutions, total, err := s.Repo.SearchInstitutions(ctx, q)
+	if err != nil {
+		return nil, 0, internal("Error searching institutions", err)
+	}
+	return institutions, total, nil
+}
+
+// ValidInstitutionCode reports whether code, upper-cased, can be the first
+// four characters of a SWIFT code.
+func ValidInstitutionCode(code string) bool {
+	if len(code) != 4 {
+		return false
+	}
+	for _, c := range code {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// GetInstitution returns the headquarters of an institution, matching code
+// regardless of case.
+func (s *Service) GetInstitution(ctx context.Context, code string) (*models.Institution, error) {
+	code = strings.ToUpper(code)
+	if !ValidInstitutionCode(code) {
+		return nil, invalid("Institution code must be exactly 4 letters or digits")
+	}
+
+	institution, err := s.Repo.GetInstitution(ctx, code)
+	if err != nil {
+		return nil, internal("Error retrieving institution", err)
+	}
+	if institution == nil {
+		return nil, notFound("Institution not found")
+	}
+	return institution, nil
+}
+
+// ListByInstitution returns every code of an institution, matching code
+// regardless of case.
+func (s *Service) ListByInstitution(ctx context.Context, code string) ([]models.SwiftCode, error) {
+	code = strings.ToUpper(code)
+	if !ValidInstitutionCode(code) {
+		return nil, invalid("Institution code must be exactly 4 letters or digits")
+	}
+
+	codes, err := s.Repo.GetSwiftCodesByInstitution(ctx, code)
+	if err != nil {
+		return nil, internal("Error retrieving SWIFT codes", err)
+	}
+	if len(codes) == 0 {
+		return nil, notFound("No SWIFT codes found for this institution")
+	}
+	return codes, nil
+}
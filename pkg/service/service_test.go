@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"swift-api/pkg/events"
+	"swift-api/pkg/models"
+	"swift-api/pkg/repository"
+	"swift-api/pkg/service"
+)
+
+// failingRepo fails every lookup with err.
+type failingRepo struct {
+	repository.Repository
+	err error
+}
+
+func (r failingRepo) GetSwiftCodeWithBranches(context.Context, string) (*models.SwiftCode, error) {
+	return nil, r.err
+}
+
+func newService(t *testing.T) *service.Service {
+	hqCode := "SERVPLPWXXX"
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.InsertSwiftCodes(context.Background(), []models.SwiftCode{
+		{SwiftCode: hqCode, BankName: "SERVICE BANK", TownName: "WARSZAWA", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true},
+		{SwiftCode: "SERVPLPW001", BankName: "SERVICE BANK", TownName: "KRAKOW", CountryISO2: "PL", CountryName: "POLAND", HeadquarterSWIFTCode: &hqCode},
+		{SwiftCode: "SERVPLPW002", BankName: "SERVICE BANK", TownName: "GDANSK", CountryISO2: "PL", CountryName: "POLAND", HeadquarterSWIFTCode: &hqCode},
+	}))
+	return service.New(repo, nil)
+}
+
+func TestErrorKinds(t *testing.T) {
+	ctx := context.Background()
+	s := newService(t)
+	newCode := service.NewSwiftCode{SwiftCode: "SERVPLPW003", BankName: "B", Address: "A", CountryISO2: "pl", CountryName: "Poland"}
+
+	for _, tc := range []struct {
+		name    string
+		err     error
+		kind    error
+		message string
+	}{
+		{"Short code", s.DeleteSwiftCode(ctx, "SHORT"), service.ErrInvalid, "SWIFT code must be exactly 11 characters"},
+		{"Invalid new code", s.CreateSwiftCode(ctx, service.NewSwiftCode{}), service.ErrInvalid, "bankName is required"},
+		{"Unknown code", s.DeleteSwiftCode(ctx, "MISSINGGXXX"), service.ErrNotFound, "SWIFT code not found"},
+		{"Headquarter with branches", s.DeleteSwiftCode(ctx, "SERVPLPWXXX"), service.ErrConflict, "Cannot delete headquarter with existing branches"},
+		{"Unknown code to update", s.UpdateSwiftCode(ctx, "MISSINGGXXX", service.NewSwiftCode{BankName: "B", Address: "A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}), service.ErrNotFound, "SWIFT code not found"},
+		{"Update of another code", s.UpdateSwiftCode(ctx, "SERVPLPW001", service.NewSwiftCode{SwiftCode: "SERVPLPW002"}), service.ErrInvalid, "swiftCode must match the SWIFT code being updated"},
+		{"Duplicate code", s.CreateSwiftCode(ctx, service.NewSwiftCode{SwiftCode: "SERVPLPW001", BankName: "B", Address: "A", CountryISO2: "PL", CountryName: "POLAND"}), service.ErrExists, "SWIFT code already exists"},
+		{"Batch without codes", func() error { _, err := s.LookupSwiftCodes(ctx, nil); return err }(), service.ErrInvalid, "swiftCodes must not be empty"},
+		{"Branches of a branch", func() error {
+			_, _, err := s.ListBranches(ctx, "SERVPLPW001", repository.BranchQuery{})
+			return err
+		}(), service.ErrInvalid, "SWIFT code is not a headquarter"},
+		{"Branch page past the limit", func() error {
+			_, _, err := s.ListBranches(ctx, "SERVPLPWXXX", repository.BranchQuery{Limit: 501})
+			return err
+		}(), service.ErrInvalid, "limit must be an integer between 1 and 500"},
+		{"Unknown institution", func() error { _, err := s.ListByInstitution(ctx, "miss"); return err }(), service.ErrNotFound, "No SWIFT codes found for this institution"},
+		{"Country without headquarters", func() error {
+			_, _, err := s.ListHeadquartersByCountry(ctx, "de")
+			return err
+		}(), service.ErrNotFound, "No headquarters found for this country"},
+		{"Search past the limit", func() error {
+			_, _, err := s.SearchInstitutions(ctx, repository.InstitutionQuery{Limit: 101})
+			return err
+		}(), service.ErrInvalid, "limit must be an integer between 1 and 100"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Error(t, tc.err)
+			assert.ErrorIs(t, tc.err, tc.kind)
+			var e *service.Error
+			require.ErrorAs(t, tc.err, &e)
+			assert.Equal(t, tc.message, e.Message)
+		})
+	}
+
+	require.NoError(t, s.CreateSwiftCode(ctx, newCode))
+	code, err := s.GetSwiftCode(ctx, "SERVPLPW003", service.LookupOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "PL", code.CountryISO2, "country fields are upper-cased")
+	require.NoError(t, s.DeleteSwiftCode(ctx, "SERVPLPW003"))
+}
+
+func TestInternalErrorsKeepTheirCause(t *testing.T) {
+	s := service.New(failingRepo{err: context.DeadlineExceeded}, nil)
+	_, err := s.GetSwiftCode(context.Background(), "SERVPLPWXXX", service.LookupOptions{Branches: true})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	for _, kind := range []error{service.ErrInvalid, service.ErrNotFound, service.ErrExists, service.ErrConflict} {
+		assert.False(t, errors.Is(err, kind))
+	}
+	var e *service.Error
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, "Error retrieving SWIFT code", e.Message)
+}
+
+func TestLookupOptions(t *testing.T) {
+	ctx := context.Background()
+	s := newService(t)
+
+	for _, tc := range []struct {
+		opts     service.LookupOptions
+		branches int
+	}{
+		{service.LookupOptions{}, 0},
+		{service.LookupOptions{Branches: true}, 2},
+		{service.LookupOptions{Branches: true, BranchLimit: 1}, 1},
+	} {
+		code, err := s.GetSwiftCode(ctx, "SERVPLPWXXX", tc.opts)
+		require.NoError(t, err)
+		assert.Len(t, code.Branches, tc.branches, "%+v", tc.opts)
+	}
+}
+
+func TestListBranches(t *testing.T) {
+	ctx := context.Background()
+	s := newService(t)
+
+	branches, total, err := s.ListBranches(ctx, "SERVPLPWXXX", repository.BranchQuery{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, branches, 1)
+	assert.Equal(t, "SERVPLPW002", branches[0].SwiftCode)
+
+	branches, total, err = s.ListBranches(ctx, "SERVPLPWXXX", repository.BranchQuery{Town: " krakow ", Offset: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Empty(t, branches, "a page past the end skips the query")
+}
+
+func TestLookupSwiftCodes(t *testing.T) {
+	results, err := newService(t).LookupSwiftCodes(context.Background(), []string{"servplpw", "BAD", "MISSINGGXXX"})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "SERVPLPWXXX", results[0].SwiftCode)
+	require.NotNil(t, results[0].Code)
+	assert.Len(t, results[0].Code.Branches, 2)
+	assert.Contains(t, results[1].Error, "8 or 11 characters")
+	assert.Nil(t, results[1].Code)
+	assert.Equal(t, "MISSINGGXXX", results[2].SwiftCode)
+	assert.Nil(t, results[2].Code)
+}
+
+// recorder collects published events.
+type recorder struct{ events []events.Event }
+
+func (r *recorder) Publish(_ context.Context, evs ...events.Event) {
+	r.events = append(r.events, evs...)
+}
+
+func TestEvents(t *testing.T) {
+	ctx := context.Background()
+	s := newService(t)
+	rec := &recorder{}
+	s.Events = rec
+
+	branch := service.NewSwiftCode{SwiftCode: "EVNTPLPW001", BankName: "EVENT BANK", Address: "A", CountryISO2: "PL", CountryName: "POLAND"}
+	require.NoError(t, s.CreateSwiftCode(ctx, branch))
+	hq := service.NewSwiftCode{SwiftCode: "EVNTPLPWXXX", BankName: "EVENT BANK", Address: "A", CountryISO2: "PL", CountryName: "POLAND", IsHeadquarter: true}
+	require.NoError(t, s.CreateSwiftCode(ctx, hq))
+	branch.BankName = "RENAMED BANK"
+	require.NoError(t, s.UpdateSwiftCode(ctx, "EVNTPLPW001", branch))
+	require.NoError(t, s.UpdateSwiftCode(ctx, "EVNTPLPW001", branch), "nothing changed")
+	require.NoError(t, s.DeleteSwiftCode(ctx, "EVNTPLPW001"))
+	require.Error(t, s.DeleteSwiftCode(ctx, "EVNTPLPW001"))
+
+	var got []string
+	for _, e := range rec.events {
+		assert.Equal(t, events.SourceAPI, e.Source)
+		got = append(got, string(e.Type)+" "+e.SwiftCode)
+	}
+	assert.Equal(t, []string{
+		"swift_code.created EVNTPLPWXXX",
+		"swift_code.created EVNTPLPW001",
+		"swift_code.placeholder_promoted EVNTPLPWXXX",
+		"swift_code.updated EVNTPLPW001",
+		"swift_code.deleted EVNTPLPW001",
+	}, got)
+	assert.Equal(t, "UNKNOWN", rec.events[0].Data.BankName)
+	assert.Equal(t, "EVENT BANK", rec.events[2].Data.BankName)
+	assert.Equal(t, "RENAMED BANK", rec.events[3].Data.BankName)
+	assert.Nil(t, rec.events[4].Data)
+}